@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -8,41 +9,70 @@ import (
 	"travel-backend/internal/adapters/api"
 	"travel-backend/internal/adapters/api/handlers"
 	"travel-backend/internal/adapters/db/dynamodb"
+	"travel-backend/internal/adapters/db/memory"
+	"travel-backend/internal/core/domain/models"
 	"travel-backend/internal/core/services"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/jwt"
 
+	awsdynamodb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/gorilla/mux"
 )
 
+// repositories are the storage of one tenant
+type repositories struct {
+	hotel       db.HotelRepository
+	roomType    db.RoomTypeRepository
+	reservation db.HotelReservationRepository
+	inventory   db.RoomInventoryRepository
+	flight      db.FlightRepository
+	booking     db.BookingRepository
+	seat        db.SeatRepository
+	passenger   db.PassengerRepository
+	meal        db.MealRepository
+	fare        db.FareRepository
+	ratePlan    db.RatePlanRepository
+	idempotency db.IdempotencyRepository
+}
+
 func main() {
 	customConfig.LoadConfig()
 
 	log.Println("Accessing loaded configuration:")
 
-	dbClient := dynamodb.NewDynamoDBClient()
-
-	// Initialize repositories
-	hotelRepo := dynamodb.NewHotelRepo(dbClient)
-	flightRepo := dynamodb.NewFlightRepo(dbClient)
-	bookingRepo := dynamodb.NewBookingRepo(dbClient)
-
-	// Initialize services
-	hotelService := services.NewHotelService(hotelRepo, bookingRepo)
-	flightService := services.NewFlightService(flightRepo, bookingRepo)
-	bookingService := services.NewBookingService(bookingRepo)
+	// Initialize repositories. Every tenant gets its own, so that nothing one
+	// tenant stores can be read through another's.
+	var openRepositories func(tenantID string) *repositories
 
-	// Initialize API Handlers
-	hotelHandler := handlers.NewHotelHandler(hotelService)
-	flightHandler := handlers.NewFlightHandler(flightService)
-	bookingHandler := handlers.NewBookingHandler(bookingService)
+	switch driver := customConfig.AppConfig.Database.Driver; driver {
+	case "memory":
+		log.Println("Using in-memory repositories")
+		openRepositories = func(string) *repositories {
+			store := memory.NewStore()
+			store.StartSweeper(context.Background(), customConfig.AppConfig.Seats.HoldSweepInterval)
+			return newMemoryRepositories(store)
+		}
+	case "dynamodb":
+		dbClient := dynamodb.NewDynamoDBClient()
+		if dbClient == nil {
+			log.Fatal("Could not connect to DynamoDB")
+		}
+		openRepositories = func(tenantID string) *repositories {
+			return newDynamoDBRepositories(dynamodb.ForTenant(dbClient, tenantID))
+		}
+	default:
+		log.Fatalf("Unknown database driver %q", driver)
+	}
 
-	// Set up routes
-	router := mux.NewRouter()
-	api.SetupRoutes(router, hotelHandler, flightHandler, bookingHandler)
+	verifier, err := api.NewTokenVerifier()
+	if err != nil {
+		log.Fatalf("Error configuring authentication: %v", err)
+	}
 
 	// Start the server
 	server := &http.Server{
 		Addr:    ":3000",
-		Handler: router,
+		Handler: newRouter(verifier, openRepositories),
 	}
 
 	fmt.Println("Server started on http://localhost:3000")
@@ -51,3 +81,87 @@ func main() {
 	}
 
 }
+
+// newMemoryRepositories returns the in-memory repositories of one tenant
+func newMemoryRepositories(store *memory.Store) *repositories {
+	return &repositories{
+		hotel:       memory.NewHotelRepo(store),
+		roomType:    memory.NewRoomTypeRepo(store),
+		reservation: memory.NewHotelReservationRepo(store),
+		inventory:   memory.NewRoomInventoryRepo(store),
+		flight:      memory.NewFlightRepo(store),
+		booking:     memory.NewBookingRepo(store),
+		seat:        memory.NewSeatRepo(store),
+		passenger:   memory.NewPassengerRepo(store),
+		meal:        memory.NewMealRepo(store),
+		fare:        memory.NewFareRepo(store),
+		ratePlan:    memory.NewRatePlanRepo(store),
+		idempotency: memory.NewIdempotencyRepo(store),
+	}
+}
+
+// newDynamoDBRepositories returns the DynamoDB repositories of one tenant,
+// given a client returned by dynamodb.ForTenant
+func newDynamoDBRepositories(client *awsdynamodb.Client) *repositories {
+	return &repositories{
+		hotel:       dynamodb.NewHotelRepo(client),
+		roomType:    dynamodb.NewRoomTypeRepo(client),
+		reservation: dynamodb.NewHotelReservationRepo(client),
+		inventory:   dynamodb.NewRoomInventoryRepo(client),
+		flight:      dynamodb.NewFlightRepo(client),
+		booking:     dynamodb.NewBookingRepo(client),
+		seat:        dynamodb.NewSeatRepo(client),
+		passenger:   dynamodb.NewPassengerRepo(client),
+		meal:        dynamodb.NewMealRepo(client),
+		fare:        dynamodb.NewFareRepo(client),
+		ratePlan:    dynamodb.NewRatePlanRepo(client),
+		idempotency: dynamodb.NewIdempotencyRepo(client),
+	}
+}
+
+// newRouter sets up the routes of every configured tenant. Requests are
+// authenticated before their tenant is known, then served by that tenant's
+// own router over the repositories openRepositories returns for it.
+func newRouter(verifier *jwt.Verifier, openRepositories func(tenantID string) *repositories) *mux.Router {
+	tenants := api.NewTenantRouter(customConfig.AppConfig.Tenants.List, customConfig.AppConfig.Tenants.Default, func(tenant *models.Tenant) http.Handler {
+		return newTenantRouter(tenant, openRepositories(tenant.ID))
+	})
+	router := mux.NewRouter()
+	router.Use(api.RequestID)
+	router.Use(api.Authenticate(verifier))
+	router.PathPrefix("/").Handler(tenants)
+	return router
+}
+
+// newTenantRouter wires the services and handlers of one tenant onto repos
+func newTenantRouter(tenant *models.Tenant, repos *repositories) *mux.Router {
+	// Initialize services
+	hotelService := services.NewHotelService(repos.hotel, repos.roomType, repos.reservation, repos.inventory, repos.ratePlan, tenant)
+	flightService := services.NewFlightService(repos.flight, repos.booking, repos.seat)
+	bookingService := services.NewBookingService(repos.booking, repos.passenger, repos.flight, repos.fare, repos.seat, repos.hotel, repos.roomType, repos.ratePlan, repos.reservation, repos.inventory, tenant)
+	seatService := services.NewSeatService(repos.seat, repos.booking, repos.passenger, customConfig.AppConfig.Seats.HoldDuration)
+	passengerService := services.NewPassengerService(repos.passenger, repos.booking, repos.seat, repos.meal)
+	mealService := services.NewMealService(repos.meal)
+	itineraryService := services.NewItineraryService(repos.flight, customConfig.AppConfig.Connections)
+	fareService := services.NewFareService(repos.fare, repos.flight, tenant)
+	reservationService := services.NewHotelReservationService(repos.reservation, repos.hotel, repos.roomType, repos.inventory, repos.booking, customConfig.AppConfig.Hotels.FlexibleCheckinDays)
+	ratePlanService := services.NewRatePlanService(repos.ratePlan, repos.hotel, repos.roomType, tenant)
+
+	// Initialize API Handlers
+	hotelHandler := handlers.NewHotelHandler(hotelService)
+	flightHandler := handlers.NewFlightHandler(flightService)
+	bookingHandler := handlers.NewBookingHandler(bookingService)
+	seatHandler := handlers.NewSeatHandler(seatService)
+	passengerHandler := handlers.NewPassengerHandler(passengerService)
+	mealHandler := handlers.NewMealHandler(mealService)
+	itineraryHandler := handlers.NewItineraryHandler(itineraryService)
+	fareHandler := handlers.NewFareHandler(fareService)
+	reservationHandler := handlers.NewHotelReservationHandler(reservationService)
+	ratePlanHandler := handlers.NewRatePlanHandler(ratePlanService)
+	tenantHandler := handlers.NewTenantHandler(tenant)
+
+	router := mux.NewRouter()
+	router.Use(api.Idempotency(repos.idempotency, customConfig.AppConfig.Idempotency.KeyTTL, customConfig.AppConfig.Idempotency.LockTimeout))
+	api.SetupRoutes(router, hotelHandler, flightHandler, bookingHandler, seatHandler, passengerHandler, mealHandler, itineraryHandler, fareHandler, reservationHandler, ratePlanHandler, tenantHandler)
+	return router
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+	"travel-backend/customConfig"
+	"travel-backend/internal/adapters/api"
+	"travel-backend/internal/adapters/db/dynamodb"
+	"travel-backend/internal/adapters/db/dynamodb/testutil"
+	"travel-backend/internal/adapters/db/memory"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/jwt"
+)
+
+const testSecret = "test-secret"
+
+// testTables lists the primary key of every table, as created in DynamoDB
+var testTables = []struct{ name, partitionKey, sortKey string }{
+	{"Bookings", "bookingID", ""},
+	{"BookingLocators", "locator", ""},
+	{"Fares", "fareID", ""},
+	{"Flights", "flightID", ""},
+	{"FlightInventory", "flightID", ""},
+	{"Hotels", "hotelID", ""},
+	{"HotelReservations", "reservationID", ""},
+	{"IdempotencyKeys", "idempotencyKey", ""},
+	{"Meals", "mealID", ""},
+	{"Passengers", "passengerID", ""},
+	{"PassengerMeals", "passengerID", ""},
+	{"RatePlans", "ratePlanID", ""},
+	{"RoomRates", "rateKey", "date"},
+	{"RoomInventory", "inventoryKey", "date"},
+	{"RoomTypes", "roomTypeID", ""},
+	{"Seats", "seatID", ""},
+	{"SeatHolds", "seatID", ""},
+}
+
+// useTestConfig configures two tenants, acme and globex, and HS256 tokens
+func useTestConfig(t *testing.T) {
+	t.Helper()
+	previous := customConfig.AppConfig
+	t.Cleanup(func() { customConfig.AppConfig = previous })
+
+	config := &customConfig.Config{}
+	config.Auth.Issuer = "travel"
+	config.Auth.Audience = "api"
+	config.Auth.HS256Secret = testSecret
+	config.Seats.HoldDuration = 15 * time.Minute
+	config.Idempotency.KeyTTL = time.Hour
+	config.Idempotency.LockTimeout = time.Minute
+	config.Hotels.FlexibleCheckinDays = 3
+	config.AWS.DynamoDB.TablePrefix = "test_"
+	config.Tenants.Default = "acme"
+	config.Tenants.List = []models.Tenant{
+		{ID: "acme", BrandName: "Acme Travel", APIKeys: []string{"acme-key"}},
+		{ID: "globex", BrandName: "Globex Trips", APIKeys: []string{"globex-key"}},
+	}
+	customConfig.AppConfig = config
+}
+
+func testToken(t *testing.T, userID, role, tenant string) string {
+	t.Helper()
+	token, err := jwt.Sign(&jwt.Claims{
+		Issuer:    "travel",
+		Subject:   userID,
+		Audience:  jwt.Audience{"api"},
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Roles:     []string{role},
+		Tenant:    tenant,
+	}, jwt.HS256, "", []byte(testSecret))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return token
+}
+
+// call sends a request to the router and decodes its JSON response into out,
+// unless out is nil, returning the status code
+func call(t *testing.T, router http.Handler, method, path string, headers map[string]string, body, out interface{}) int {
+	t.Helper()
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("encoding %s %s: %v", method, path, err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if out != nil && rec.Code < 300 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("decoding %s %s: %v: %s", method, path, err, rec.Body)
+		}
+	}
+	return rec.Code
+}
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// testFlight is flight f1 of airline, departing in a month
+func testFlight(airline string) map[string]interface{} {
+	departure := time.Now().UTC().Add(30 * 24 * time.Hour).Truncate(time.Hour)
+	return map[string]interface{}{
+		"flightID": "f1", "airline": airline, "origin": "DEL", "destination": "BOM",
+		"departureTime": departure, "arrivalTime": departure.Add(2 * time.Hour), "aircraftType": "A320",
+	}
+}
+
+// bookFlight has ops put flight f1 and its fare y1 on sale and customer book
+// a seat on it
+func bookFlight(t *testing.T, router http.Handler, ops, customer map[string]string) models.Booking {
+	t.Helper()
+	if status := call(t, router, http.MethodPost, "/flights/", ops, testFlight("AI"), nil); status != http.StatusCreated {
+		t.Fatalf("creating flight: status %d", status)
+	}
+	fare := map[string]interface{}{
+		"fareID": "y1", "cabin": "economy", "bookingCode": "y", "currency": "inr",
+		"prices": map[string]interface{}{"ADT": map[string]interface{}{"baseFare": 500000}},
+	}
+	if status := call(t, router, http.MethodPost, "/flights/f1/fares", ops, fare, nil); status != http.StatusCreated {
+		t.Fatalf("creating fare: status %d", status)
+	}
+
+	var booking models.Booking
+	order := map[string]interface{}{"segments": []interface{}{map[string]interface{}{
+		"type": "FLIGHT",
+		"flight": map[string]interface{}{
+			"flightID": "f1",
+			"quote":    map[string]interface{}{"fareID": "y1", "passengers": map[string]int{"ADT": 1}},
+		},
+	}}}
+	if status := call(t, router, http.MethodPost, "/bookings/", customer, order, &booking); status != http.StatusCreated {
+		t.Fatalf("creating booking: status %d", status)
+	}
+	return booking
+}
+
+// newMemoryRouter serves the test tenants from memory
+func newMemoryRouter(t *testing.T) http.Handler {
+	t.Helper()
+	useTestConfig(t)
+	verifier, err := api.NewTokenVerifier()
+	if err != nil {
+		t.Fatalf("NewTokenVerifier() error = %v", err)
+	}
+	return newRouter(verifier, func(string) *repositories {
+		return newMemoryRepositories(memory.NewStore())
+	})
+}
+
+func TestTenantIsolation(t *testing.T) {
+	drivers := []struct {
+		name             string
+		openRepositories func(t *testing.T) func(tenantID string) *repositories
+	}{
+		{name: "memory", openRepositories: func(t *testing.T) func(string) *repositories {
+			return func(string) *repositories {
+				return newMemoryRepositories(memory.NewStore())
+			}
+		}},
+		{name: "dynamodb", openRepositories: func(t *testing.T) func(string) *repositories {
+			server := testutil.NewServer()
+			t.Cleanup(server.Close)
+			for _, table := range testTables {
+				server.CreateTable("test_"+table.name, table.partitionKey, table.sortKey)
+			}
+			client := server.Client()
+			return func(tenantID string) *repositories {
+				return newDynamoDBRepositories(dynamodb.ForTenant(client, tenantID))
+			}
+		}},
+	}
+
+	for _, driver := range drivers {
+		t.Run(driver.name, func(t *testing.T) {
+			useTestConfig(t)
+			verifier, err := api.NewTokenVerifier()
+			if err != nil {
+				t.Fatalf("NewTokenVerifier() error = %v", err)
+			}
+			router := newRouter(verifier, driver.openRepositories(t))
+
+			// Tenant A, acme, sells a flight that one of its customers books
+			opsA := bearer(testToken(t, "ops", "ops-admin", "acme"))
+			customerA := bearer(testToken(t, "u1", "customer", "acme"))
+			booking := bookFlight(t, router, opsA, customerA)
+			passenger := map[string]interface{}{"name": "Asha Rao", "age": 30, "gender": "F", "passportNumber": "K1234567"}
+			if status := call(t, router, http.MethodPost, "/bookings/"+booking.BookingID+"/passengers", customerA, passenger, nil); status != http.StatusCreated {
+				t.Fatalf("adding passenger: status %d", status)
+			}
+
+			bookingPath := "/bookings/" + booking.BookingID
+			locatorPath := "/bookings/locator/" + url.PathEscape(booking.Locator) + "?lastName=Rao"
+
+			// Tenant A sees its own booking
+			if status := call(t, router, http.MethodGet, bookingPath, customerA, nil, nil); status != http.StatusOK {
+				t.Fatalf("acme reading its booking: status %d, want 200", status)
+			}
+			var page models.BookingPage
+			if status := call(t, router, http.MethodGet, "/bookings/", customerA, nil, &page); status != http.StatusOK || len(page.Items) != 1 {
+				t.Fatalf("acme listing its bookings: status %d with %d bookings, want 200 with 1", status, len(page.Items))
+			}
+			if status := call(t, router, http.MethodGet, locatorPath, map[string]string{"X-API-Key": "acme-key"}, nil, nil); status != http.StatusOK {
+				t.Fatalf("acme looking up its locator: status %d, want 200", status)
+			}
+
+			// Tenant B, globex, sees nothing of it: neither a user with the
+			// same ID nor staff, who may read any booking of their own tenant
+			callers := map[string]map[string]string{
+				"customer with the same user ID": bearer(testToken(t, "u1", "customer", "globex")),
+				"agent":                          bearer(testToken(t, "agent", "agent", "globex")),
+				"ops admin":                      bearer(testToken(t, "ops", "ops-admin", "globex")),
+			}
+			for name, headers := range callers {
+				if status := call(t, router, http.MethodGet, bookingPath, headers, nil, nil); status != http.StatusNotFound {
+					t.Errorf("globex %s reading acme's booking: status %d, want 404", name, status)
+				}
+				var page models.BookingPage
+				if status := call(t, router, http.MethodGet, "/bookings/", headers, nil, &page); status != http.StatusOK || len(page.Items) != 0 {
+					t.Errorf("globex %s listing bookings: status %d with %d bookings, want 200 with none", name, status, len(page.Items))
+				}
+				if status := call(t, router, http.MethodGet, "/bookings/"+booking.BookingID+"/passengers", headers, nil, nil); status != http.StatusNotFound {
+					t.Errorf("globex %s listing acme's passengers: status %d, want 404", name, status)
+				}
+			}
+			if status := call(t, router, http.MethodGet, locatorPath, map[string]string{"X-API-Key": "globex-key"}, nil, nil); status != http.StatusNotFound {
+				t.Errorf("globex looking up acme's locator: status %d, want 404", status)
+			}
+			// An unknown flight is answered with null rather than 404
+			var unknown *models.Flight
+			if status := call(t, router, http.MethodGet, "/flights/f1", bearer(testToken(t, "u1", "customer", "globex")), nil, &unknown); status != http.StatusOK || unknown != nil {
+				t.Errorf("globex reading acme's flight: status %d with %+v, want no flight", status, unknown)
+			}
+
+			// Tenant B writing under the same IDs leaves tenant A's data alone
+			opsB := bearer(testToken(t, "ops", "ops-admin", "globex"))
+			if status := call(t, router, http.MethodPost, "/flights/", opsB, testFlight("6E"), nil); status != http.StatusCreated {
+				t.Fatalf("globex creating flight f1: status %d, want 201", status)
+			}
+			var got models.Flight
+			if status := call(t, router, http.MethodGet, "/flights/f1", customerA, nil, &got); status != http.StatusOK || got.Airline != "AI" {
+				t.Errorf("acme reading its flight: status %d, airline %q, want 200 and AI", status, got.Airline)
+			}
+		})
+	}
+}
+
+func TestUpdateBookingContact(t *testing.T) {
+	router := newMemoryRouter(t)
+	customer := bearer(testToken(t, "u1", "customer", "acme"))
+	booking := bookFlight(t, router, bearer(testToken(t, "ops", "ops-admin", "acme")), customer)
+	path := "/bookings/" + booking.BookingID
+	withVersion := func(version int64, contentType string) map[string]string {
+		headers := map[string]string{"If-Match": fmt.Sprintf("%q", fmt.Sprint(version)), "Content-Type": contentType}
+		for name, value := range customer {
+			headers[name] = value
+		}
+		return headers
+	}
+
+	var patched models.Booking
+	patch := map[string]interface{}{"contact": map[string]string{"email": "asha@example.com"}}
+	if status := call(t, router, http.MethodPatch, path, withVersion(booking.Version, "application/merge-patch+json"), patch, &patched); status != http.StatusOK {
+		t.Fatalf("patching the contact: status %d, want 200", status)
+	}
+	if patched.Contact == nil || patched.Contact.Email != "asha@example.com" || patched.Version != booking.Version+1 {
+		t.Fatalf("patched booking contact %+v at version %d, want the email at version %d", patched.Contact, patched.Version, booking.Version+1)
+	}
+
+	// Everything else stays as stored
+	patch = map[string]interface{}{"segments": []interface{}{}}
+	if status := call(t, router, http.MethodPatch, path, withVersion(patched.Version, "application/merge-patch+json"), patch, nil); status != http.StatusBadRequest {
+		t.Errorf("patching the segments: status %d, want 400", status)
+	}
+	patch = map[string]interface{}{"contact": map[string]string{"phone": "98123"}}
+	if status := call(t, router, http.MethodPatch, path, withVersion(patched.Version, "application/merge-patch+json"), patch, nil); status != http.StatusBadRequest {
+		t.Errorf("patching in a bad phone number: status %d, want 400", status)
+	}
+
+	replacement := patched
+	replacement.Contact = &models.BookingContact{Phone: "+919812345678"}
+	replacement.BookingStatus = models.BookingCancelled
+	var updated models.Booking
+	if status := call(t, router, http.MethodPut, path, withVersion(patched.Version, "application/json"), replacement, &updated); status != http.StatusOK {
+		t.Fatalf("replacing the contact: status %d, want 200", status)
+	}
+	if updated.Contact == nil || *updated.Contact != (models.BookingContact{Phone: "+919812345678"}) {
+		t.Errorf("updated booking contact = %+v, want only the phone number", updated.Contact)
+	}
+	if updated.BookingStatus != booking.BookingStatus {
+		t.Errorf("updated booking status = %s, want %s as stored", updated.BookingStatus, booking.BookingStatus)
+	}
+}
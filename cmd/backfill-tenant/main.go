@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"travel-backend/customConfig"
+	"travel-backend/internal/adapters/db/dynamodb"
+)
+
+// backfill-tenant moves the items stored before tenants existed, whose keys
+// carry no tenant prefix and so are read by no tenant, to one tenant. Legacy
+// Hotels rows are left to migrate-hotels.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be backfilled without writing anything")
+	tenant := flag.String("tenant", "", "ID of the tenant to move the items to; the default tenant when empty")
+	flag.Parse()
+
+	customConfig.LoadConfig()
+	if *tenant == "" {
+		*tenant = customConfig.AppConfig.Tenants.Default
+	}
+	if *tenant == "" {
+		log.Fatal("No tenant given and no default tenant configured")
+	}
+
+	tenantIDs := make([]string, 0, len(customConfig.AppConfig.Tenants.List))
+	known := false
+	for _, t := range customConfig.AppConfig.Tenants.List {
+		tenantIDs = append(tenantIDs, t.ID)
+		known = known || t.ID == *tenant
+	}
+	if !known {
+		log.Fatalf("Tenant %q is not configured", *tenant)
+	}
+
+	dbClient := dynamodb.NewDynamoDBClient()
+	if dbClient == nil {
+		log.Fatal("Could not connect to DynamoDB")
+	}
+
+	result, err := dynamodb.BackfillTenant(dbClient, dynamodb.ForTenant(dbClient, *tenant), tenantIDs, *dryRun)
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	fmt.Printf("Scanned %d items: %d backfilled, %d skipped, %d failed\n",
+		result.Scanned, result.Copied, result.Skipped, result.Failed)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"travel-backend/customConfig"
+	"travel-backend/internal/adapters/db/dynamodb"
+)
+
+// migrate-hotels splits legacy Hotels rows, which mixed a property with a
+// single reservation, into Hotels, RoomTypes and HotelReservations items.
+// Legacy rows are read from the unprefixed Hotels table and the new items are
+// written for one tenant per run.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be migrated without writing anything")
+	tenant := flag.String("tenant", "", "ID of the tenant to migrate the rows to; the default tenant when empty")
+	flag.Parse()
+
+	customConfig.LoadConfig()
+	if *tenant == "" {
+		*tenant = customConfig.AppConfig.Tenants.Default
+	}
+	if *tenant == "" {
+		log.Fatal("No tenant given and no default tenant configured")
+	}
+
+	dbClient := dynamodb.NewDynamoDBClient()
+	if dbClient == nil {
+		log.Fatal("Could not connect to DynamoDB")
+	}
+
+	result, err := dynamodb.MigrateLegacyHotels(dbClient, dynamodb.ForTenant(dbClient, *tenant), *dryRun)
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+
+	fmt.Printf("Scanned %d rows: %d migrated, %d already migrated, %d failed\n",
+		result.Scanned, result.Migrated, result.Skipped, result.Failed)
+}
@@ -1,13 +1,50 @@
 package customConfig
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
+	"travel-backend/internal/core/domain/models"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	AWS struct {
+	Database struct {
+		Driver string
+	}
+	Seats struct {
+		HoldDuration      time.Duration
+		HoldSweepInterval time.Duration
+	}
+	Hotels struct {
+		FlexibleCheckinDays int
+	}
+	Idempotency struct {
+		KeyTTL      time.Duration
+		LockTimeout time.Duration
+	}
+	Auth struct {
+		Issuer              string
+		Audience            string
+		HS256Secret         string
+		RS256PublicKeyFile  string
+		RS256PrivateKeyFile string
+		JWKSURL             string
+		JWKSRefreshInterval time.Duration
+		ClockSkew           time.Duration
+	}
+	Tenants struct {
+		Default string
+		List    []models.Tenant
+	}
+	Connections models.ConnectionRules
+	AWS         struct {
 		Region          string
 		AccessKeyID     string
 		SecretAccessKey string
@@ -24,6 +61,19 @@ func LoadConfig() {
 	// Automatically read environment variables
 	viper.AutomaticEnv()
 
+	// Default to DynamoDB unless another driver is requested
+	viper.SetDefault("DB_DRIVER", "dynamodb")
+	viper.SetDefault("SEAT_HOLD_DURATION", "15m")
+	viper.SetDefault("SEAT_HOLD_SWEEP_INTERVAL", "1m")
+	viper.SetDefault("CONNECTION_MIN_TIME", "45m")
+	viper.SetDefault("CONNECTION_MAX_TIME", "6h")
+	viper.SetDefault("HOTEL_FLEXIBLE_CHECKIN_DAYS", 3)
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	viper.SetDefault("IDEMPOTENCY_LOCK_TIMEOUT", "1m")
+	viper.SetDefault("AUTH_JWKS_REFRESH_INTERVAL", "1h")
+	viper.SetDefault("AUTH_CLOCK_SKEW", "1m")
+	viper.SetDefault("DEFAULT_TENANT", "default")
+
 	// Read .env file if it exists
 	viper.SetConfigFile(".env")
 	err := viper.ReadInConfig()
@@ -37,10 +87,176 @@ func LoadConfig() {
 		log.Fatalf("Error unmarshalling config: %v", err)
 	}
 
+	// Select the persistence adapter ("dynamodb" or "memory")
+	AppConfig.Database.Driver = viper.GetString("DB_DRIVER")
+
+	// How long a seat stays held during checkout, and how often the in-memory
+	// adapter purges lapsed holds
+	AppConfig.Seats.HoldDuration = viper.GetDuration("SEAT_HOLD_DURATION")
+	AppConfig.Seats.HoldSweepInterval = viper.GetDuration("SEAT_HOLD_SWEEP_INTERVAL")
+
+	// How many days either side of a sold-out check-in to suggest to flexible guests
+	AppConfig.Hotels.FlexibleCheckinDays = viper.GetInt("HOTEL_FLEXIBLE_CHECKIN_DAYS")
+
+	// How long a response is remembered for replay against its Idempotency-Key
+	AppConfig.Idempotency.KeyTTL = viper.GetDuration("IDEMPOTENCY_KEY_TTL")
+	// How long a request holds its key before a retry may take the key over,
+	// in case the process running it died
+	AppConfig.Idempotency.LockTimeout = viper.GetDuration("IDEMPOTENCY_LOCK_TIMEOUT")
+
+	// Bearer tokens are verified with an HS256 secret, an RS256 public key
+	// and/or the keys published at a JWKS URL, and must name the issuer and
+	// audience when set. The private key is only read by cmd/mint-token.
+	AppConfig.Auth.Issuer = viper.GetString("AUTH_ISSUER")
+	AppConfig.Auth.Audience = viper.GetString("AUTH_AUDIENCE")
+	AppConfig.Auth.HS256Secret = viper.GetString("AUTH_HS256_SECRET")
+	AppConfig.Auth.RS256PublicKeyFile = viper.GetString("AUTH_RS256_PUBLIC_KEY_FILE")
+	AppConfig.Auth.RS256PrivateKeyFile = viper.GetString("AUTH_RS256_PRIVATE_KEY_FILE")
+	AppConfig.Auth.JWKSURL = viper.GetString("AUTH_JWKS_URL")
+	AppConfig.Auth.JWKSRefreshInterval = viper.GetDuration("AUTH_JWKS_REFRESH_INTERVAL")
+	AppConfig.Auth.ClockSkew = viper.GetDuration("AUTH_CLOCK_SKEW")
+
+	// The travel agencies served, read from the JSON file at TENANTS_FILE.
+	// Requests that name no tenant through their token or API key are served
+	// for DEFAULT_TENANT, or turned away when it is empty.
+	AppConfig.Tenants.Default = viper.GetString("DEFAULT_TENANT")
+	AppConfig.Tenants.List, err = loadTenants(viper.GetString("TENANTS_FILE"), AppConfig.Tenants.Default)
+	if err != nil {
+		log.Fatalf("Error loading TENANTS_FILE: %v", err)
+	}
+
+	// Layover bounds for connecting itineraries. CONNECTION_TIMES overrides them
+	// per airport, e.g. "LHR=90m/8h,DEL=1h/6h"
+	AppConfig.Connections.Default = models.ConnectionTime{
+		Min: viper.GetDuration("CONNECTION_MIN_TIME"),
+		Max: viper.GetDuration("CONNECTION_MAX_TIME"),
+	}
+	AppConfig.Connections.Airports, err = parseConnectionTimes(viper.GetString("CONNECTION_TIMES"))
+	if err != nil {
+		log.Fatalf("Error parsing CONNECTION_TIMES: %v", err)
+	}
+
 	// Set AWS Credentials from Environment Variables
 	AppConfig.AWS.Region = viper.GetString("AWS_REGION")
 	AppConfig.AWS.AccessKeyID = viper.GetString("AWS_ACCESS_KEY_ID")
 	AppConfig.AWS.SecretAccessKey = viper.GetString("AWS_SECRET_ACCESS_KEY")
 
+	// Prepended to every DynamoDB table name, so that several deployments
+	// can share an account
+	AppConfig.AWS.DynamoDB.TablePrefix = viper.GetString("DYNAMODB_TABLE_PREFIX")
+
 	log.Println("Configuration loaded successfully.")
 }
+
+// parseConnectionTimes parses a comma separated list of AIRPORT=MIN/MAX entries
+func parseConnectionTimes(value string) (map[string]models.ConnectionTime, error) {
+	airports := make(map[string]models.ConnectionTime)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		airport, bounds, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must look like AIRPORT=MIN/MAX", entry)
+		}
+		minText, maxText, ok := strings.Cut(bounds, "/")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must look like AIRPORT=MIN/MAX", entry)
+		}
+		minTime, err := time.ParseDuration(strings.TrimSpace(minText))
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", entry, err)
+		}
+		maxTime, err := time.ParseDuration(strings.TrimSpace(maxText))
+		if err != nil {
+			return nil, fmt.Errorf("entry %q: %w", entry, err)
+		}
+		if minTime < 0 || maxTime < minTime {
+			return nil, fmt.Errorf("entry %q: maximum must not be below minimum", entry)
+		}
+		airports[strings.ToUpper(strings.TrimSpace(airport))] = models.ConnectionTime{Min: minTime, Max: maxTime}
+	}
+	return airports, nil
+}
+
+// tenantEntry is a tenant as written in TENANTS_FILE
+type tenantEntry struct {
+	ID                string                   `json:"id"`
+	BrandName         string                   `json:"brandName"`
+	MarkupBasisPoints int64                    `json:"markupBasisPoints"`
+	AllowedSuppliers  models.SupplierAllowList `json:"allowedSuppliers"`
+	APIKeys           []string                 `json:"apiKeys"`
+}
+
+// loadTenants reads the JSON array of tenants at path. Without a file the
+// deployment serves the default tenant alone, with no markup and every
+// supplier. Tenant IDs must not contain '#', which separates them from the
+// keys of their items in DynamoDB.
+func loadTenants(path, defaultID string) ([]models.Tenant, error) {
+	if path == "" {
+		if defaultID == "" {
+			return nil, errors.New("either TENANTS_FILE or DEFAULT_TENANT must be set")
+		}
+		return []models.Tenant{{ID: defaultID, BrandName: defaultID}}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var entries []tenantEntry
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	tenants := make([]models.Tenant, 0, len(entries))
+	ids := make(map[string]bool)
+	apiKeys := make(map[string]bool)
+	for _, entry := range entries {
+		id := strings.TrimSpace(entry.ID)
+		if id == "" || strings.Contains(id, "#") {
+			return nil, fmt.Errorf("tenant ID %q must be non-empty and must not contain '#'", entry.ID)
+		}
+		if ids[id] {
+			return nil, fmt.Errorf("tenant %q is listed twice", id)
+		}
+		ids[id] = true
+		if entry.MarkupBasisPoints < 0 {
+			return nil, fmt.Errorf("tenant %q: markup cannot be negative", id)
+		}
+
+		tenant := models.Tenant{ID: id, BrandName: entry.BrandName, Markup: models.Markup(entry.MarkupBasisPoints)}
+		if tenant.BrandName == "" {
+			tenant.BrandName = id
+		}
+		for _, airline := range entry.AllowedSuppliers.Airlines {
+			airline = strings.ToUpper(strings.TrimSpace(airline))
+			if len(airline) != 2 {
+				return nil, fmt.Errorf("tenant %q: airline %q must be a two-character IATA designator", id, airline)
+			}
+			tenant.AllowedSuppliers.Airlines = append(tenant.AllowedSuppliers.Airlines, airline)
+		}
+		for _, chain := range entry.AllowedSuppliers.HotelChains {
+			chain = strings.ToUpper(strings.TrimSpace(chain))
+			if chain == "" {
+				return nil, fmt.Errorf("tenant %q: hotel chain codes must be non-empty", id)
+			}
+			tenant.AllowedSuppliers.HotelChains = append(tenant.AllowedSuppliers.HotelChains, chain)
+		}
+		for _, key := range entry.APIKeys {
+			if key == "" || apiKeys[key] {
+				return nil, fmt.Errorf("tenant %q: API keys must be non-empty and unique", id)
+			}
+			apiKeys[key] = true
+			tenant.APIKeys = append(tenant.APIKeys, key)
+		}
+		tenants = append(tenants, tenant)
+	}
+	if defaultID != "" && !ids[defaultID] {
+		return nil, fmt.Errorf("default tenant %q is not listed", defaultID)
+	}
+	return tenants, nil
+}
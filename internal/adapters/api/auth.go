@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"travel-backend/customConfig"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/jwt"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// codeInvalidToken is the error code of requests whose bearer token is rejected
+const codeInvalidToken = "invalid_token"
+
+// NewTokenVerifier builds the verifier of bearer tokens from the auth
+// configuration. At least one of the HS256 secret, the RS256 public key and
+// the JWKS URL must be set.
+func NewTokenVerifier() (*jwt.Verifier, error) {
+	config := customConfig.AppConfig.Auth
+
+	static := jwt.StaticKeys{Secret: []byte(config.HS256Secret)}
+	if config.RS256PublicKeyFile != "" {
+		pemBytes, err := os.ReadFile(config.RS256PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading RS256 public key: %w", err)
+		}
+		static.PublicKey, err = jwt.ParseRSAPublicKey(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing RS256 public key: %w", err)
+		}
+	}
+
+	var keys jwt.KeySources
+	if len(static.Secret) > 0 || static.PublicKey != nil {
+		keys = append(keys, static)
+	}
+	if config.JWKSURL != "" {
+		keys = append(keys, jwt.NewJWKS(config.JWKSURL, config.JWKSRefreshInterval))
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no token keys configured: set AUTH_HS256_SECRET, AUTH_RS256_PUBLIC_KEY_FILE or AUTH_JWKS_URL")
+	}
+	return jwt.NewVerifier(keys, config.Issuer, config.Audience, config.ClockSkew), nil
+}
+
+// Authenticate returns middleware that verifies the bearer token of every
+// request that sends an Authorization header and puts the caller into the
+// request context as a models.Principal. Requests without the header carry
+// on anonymously, to be turned away by the policies of routes that need a
+// caller; requests with a bad token get 401 Unauthorized.
+func Authenticate(verifier *jwt.Verifier) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, _ := strings.Cut(header, " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				rejectToken(w, "Authorization header must carry a bearer token")
+				return
+			}
+			claims, err := verifier.Verify(strings.TrimSpace(token))
+			if errors.Is(err, jwt.ErrInvalidToken) {
+				rejectToken(w, err.Error())
+				return
+			}
+			if err != nil {
+				utils.HandleError(w, models.Unavailable("token_keys_unavailable", err))
+				return
+			}
+
+			principal := &models.Principal{UserID: claims.Subject, Roles: principalRoles(claims.Roles), TenantID: claims.Tenant}
+			next.ServeHTTP(w, r.WithContext(models.ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// principalRoles keeps the known roles of a token. A token without any is
+// taken to belong to a customer.
+func principalRoles(claimed []string) []models.Role {
+	var roles []models.Role
+	for _, name := range claimed {
+		if role := models.Role(name); role.IsValid() {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		roles = []models.Role{models.RoleCustomer}
+	}
+	return roles
+}
+
+func rejectToken(w http.ResponseWriter, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	utils.RespondWithProblem(w, utils.NewProblem(http.StatusUnauthorized, codeInvalidToken, detail))
+}
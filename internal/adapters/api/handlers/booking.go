@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"travel-backend/internal/core/domain/models"
 	"travel-backend/internal/ports/api"
@@ -20,9 +19,23 @@ func NewBookingHandler(bookingService api.BookingService) *BookingHandler {
 	return &BookingHandler{BookingService: bookingService}
 }
 
-// GetBookings handles GET /bookings
+// GetBookings handles GET /bookings?userID=&status=&from=&to=&sort=&limit=&cursor=
+// where from and to bound the creation date
 func (h *BookingHandler) GetBookings(w http.ResponseWriter, r *http.Request) {
-	bookings, err := h.BookingService.GetAllBookings()
+	query := r.URL.Query()
+	page, err := parsePage(query)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	bookings, err := h.BookingService.ListBookings(r.Context(), models.BookingQuery{
+		UserID: query.Get("userID"),
+		Status: models.BookingStatus(query.Get("status")),
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+		Page:   page,
+	})
 	if err != nil {
 		utils.HandleError(w, err)
 		return
@@ -33,42 +46,62 @@ func (h *BookingHandler) GetBookings(w http.ResponseWriter, r *http.Request) {
 // GetBookingByID handles GET /bookings/{id}
 func (h *BookingHandler) GetBookingByID(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
-	booking, err := h.BookingService.GetBookingByID(id)
+	booking, err := h.BookingService.GetBookingByID(r.Context(), id)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	setETag(w, booking.Version)
+	utils.RespondWithJSON(w, http.StatusOK, booking)
+}
+
+// GetBookingByLocator handles GET /bookings/locator/{code}?lastName=
+func (h *BookingHandler) GetBookingByLocator(w http.ResponseWriter, r *http.Request) {
+	code := mux.Vars(r)["code"]
+	booking, err := h.BookingService.GetBookingByLocator(code, r.URL.Query().Get("lastName"))
 	if err != nil {
 		utils.HandleError(w, err)
 		return
 	}
+	setETag(w, booking.Version)
 	utils.RespondWithJSON(w, http.StatusOK, booking)
 }
 
 // CreateBooking handles POST /bookings
 func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	var booking models.Booking
-	if err := json.NewDecoder(r.Body).Decode(&booking); err != nil {
+	if err := utils.DecodeJSON(r.Body, &booking); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
-	err := h.BookingService.CreateBooking(&booking)
+	err := h.BookingService.CreateBooking(r.Context(), &booking)
 	if err != nil {
 		utils.HandleError(w, err)
 		return
 	}
+	setETag(w, booking.Version)
 	utils.RespondWithJSON(w, http.StatusCreated, booking)
 }
 
-// UpdateBooking handles PUT /bookings/{id}
+// UpdateBooking handles PUT /bookings/{id} with the ETag of the version being replaced in If-Match
 func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
 	var booking models.Booking
-	if err := json.NewDecoder(r.Body).Decode(&booking); err != nil {
+	if err := utils.DecodeJSON(r.Body, &booking); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
-	updatedBooking, err := h.BookingService.UpdateBooking(id, &booking)
+	booking.Version = version
+	updatedBooking, err := h.BookingService.UpdateBooking(r.Context(), id, &booking)
 	if err != nil {
-		utils.HandleError(w, err)
+		handleWriteError(w, err)
 		return
 	}
+	setETag(w, updatedBooking.Version)
 	utils.RespondWithJSON(w, http.StatusOK, updatedBooking)
 }
 
@@ -76,40 +109,55 @@ func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 func (h *BookingHandler) UpdateBookingStatus(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	var statusRequest struct {
-		Status string `json:"status"`
+		Status models.BookingStatus `json:"status"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&statusRequest); err != nil {
+	if err := utils.DecodeJSON(r.Body, &statusRequest); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
-	err := h.BookingService.UpdateBookingStatus(id, statusRequest.Status)
+	booking, err := h.BookingService.UpdateBookingStatus(r.Context(), id, statusRequest.Status)
 	if err != nil {
+		// A failed confirmation tells the client which segment could not be
+		// booked in the problem's details
 		utils.HandleError(w, err)
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, nil)
+	setETag(w, booking.Version)
+	utils.RespondWithJSON(w, http.StatusOK, booking)
 }
 
-// DeleteBooking handles DELETE /bookings/{id}
-func (h *BookingHandler) DeleteBooking(w http.ResponseWriter, r *http.Request) {
+// PatchBooking handles PATCH /bookings/{id} with a JSON merge patch (RFC 7396) and
+// the ETag of the version being patched in If-Match
+func (h *BookingHandler) PatchBooking(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
-	err := h.BookingService.DeleteBooking(id)
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+	patched, err := h.BookingService.PatchBooking(r.Context(), id, patch, version)
 	if err != nil {
-		utils.HandleError(w, err)
+		handleWriteError(w, err)
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+	setETag(w, patched.Version)
+	utils.RespondWithJSON(w, http.StatusOK, patched)
 }
 
-// GetBookingsByUserID handles GET /bookings/user/{userID}
-func (h *BookingHandler) GetBookingsByUserID(w http.ResponseWriter, r *http.Request) {
-	userID := mux.Vars(r)["userID"]
-	bookings, err := h.BookingService.GetBookingsByUserID(userID)
+// DeleteBooking handles DELETE /bookings/{id} with the ETag of the current version in If-Match
+func (h *BookingHandler) DeleteBooking(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	err := h.BookingService.DeleteBooking(r.Context(), id, version)
 	if err != nil {
-		utils.HandleError(w, err)
+		handleWriteError(w, err)
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, bookings)
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
 }
-
-
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
+)
+
+// setETag exposes an entity's version as its ETag, e.g. "3"
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// requireIfMatch reads the version a write expects from the If-Match header.
+// Writes without one are refused with 428 Precondition Required, since they
+// would silently overwrite changes made by others; ok is false once a
+// response has been written.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (version int64, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		utils.RespondWithProblem(w, utils.NewProblem(http.StatusPreconditionRequired, "if_match_required",
+			"If-Match header with the ETag of the current version is required"))
+		return 0, false
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		unquoted = tag
+	}
+	version, err = strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version < 0 {
+		utils.RespondWithProblem(w, utils.NewProblem(http.StatusBadRequest, "invalid_if_match",
+			"If-Match must hold a single ETag returned by this API"))
+		return 0, false
+	}
+	return version, true
+}
+
+// handleWriteError answers a failed conditional write, with 412 Precondition
+// Failed when the entity was changed since the client read it
+func handleWriteError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrVersionConflict) {
+		problem := utils.ProblemFor(err)
+		problem.Status = http.StatusPreconditionFailed
+		utils.RespondWithProblem(w, problem)
+		return
+	}
+	utils.HandleError(w, err)
+}
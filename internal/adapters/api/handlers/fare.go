@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// FareHandler handles fare and pricing API requests
+type FareHandler struct {
+	FareService api.FareService
+}
+
+// NewFareHandler creates a new instance of FareHandler
+func NewFareHandler(fareService api.FareService) *FareHandler {
+	return &FareHandler{FareService: fareService}
+}
+
+// GetFlightFares handles GET /flights/{id}/fares
+func (h *FareHandler) GetFlightFares(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	fares, err := h.FareService.GetFlightFares(id)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, fares)
+}
+
+// CreateFare handles POST /flights/{id}/fares
+func (h *FareHandler) CreateFare(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var fare models.Fare
+	if err := utils.DecodeJSON(r.Body, &fare); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	if err := h.FareService.CreateFare(id, &fare); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, fare)
+}
+
+// QuoteFare handles POST /flights/{id}/quote with a body of
+// {"fareID": "...", "passengers": {"ADT": 2, "CHD": 1}}
+func (h *FareHandler) QuoteFare(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var request models.FareQuote
+	if err := utils.DecodeJSON(r.Body, &request); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	quote, err := h.FareService.QuoteFare(id, request)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, quote)
+}
@@ -1,8 +1,8 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
+	"strconv"
 	"travel-backend/internal/core/domain/models"
 	"travel-backend/internal/ports/api"
 	"travel-backend/pkg/utils"
@@ -20,9 +20,50 @@ func NewFlightHandler(flightService api.FlightService) *FlightHandler {
 	return &FlightHandler{FlightService: flightService}
 }
 
-// GetFlights handles GET /flights
+// GetFlights handles GET /flights?airline=&origin=&destination=&from=&to=&sort=&limit=&cursor=
+// where from and to bound the departure date
 func (h *FlightHandler) GetFlights(w http.ResponseWriter, r *http.Request) {
-	flights, err := h.FlightService.GetAllFlights()
+	query := r.URL.Query()
+	page, err := parsePage(query)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	flights, err := h.FlightService.ListFlights(models.FlightQuery{
+		Airline:     query.Get("airline"),
+		Origin:      query.Get("origin"),
+		Destination: query.Get("destination"),
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+		Page:        page,
+	})
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, flights)
+}
+
+// SearchFlights handles GET /flights/search?origin=&destination=&date=&passengers=&sort=
+func (h *FlightHandler) SearchFlights(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := models.FlightSearch{
+		Origin:      query.Get("origin"),
+		Destination: query.Get("destination"),
+		Date:        query.Get("date"),
+		SortBy:      query.Get("sort"),
+	}
+	if passengers := query.Get("passengers"); passengers != "" {
+		count, err := strconv.Atoi(passengers)
+		if err != nil {
+			utils.HandleError(w, err)
+			return
+		}
+		search.Passengers = count
+	}
+
+	flights, err := h.FlightService.SearchFlights(search)
 	if err != nil {
 		utils.HandleError(w, err)
 		return
@@ -38,13 +79,16 @@ func (h *FlightHandler) GetFlightByID(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, err)
 		return
 	}
+	if flight != nil {
+		setETag(w, flight.Version)
+	}
 	utils.RespondWithJSON(w, http.StatusOK, flight)
 }
 
 // CreateFlight handles POST /flights
 func (h *FlightHandler) CreateFlight(w http.ResponseWriter, r *http.Request) {
 	var flight models.Flight
-	if err := json.NewDecoder(r.Body).Decode(&flight); err != nil {
+	if err := utils.DecodeJSON(r.Body, &flight); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
@@ -53,31 +97,84 @@ func (h *FlightHandler) CreateFlight(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, err)
 		return
 	}
+	setETag(w, flight.Version)
 	utils.RespondWithJSON(w, http.StatusCreated, flight)
 }
 
-// UpdateFlight handles PUT /flights/{id}
+// UpdateFlight handles PUT /flights/{id} with the ETag of the version being replaced in If-Match
 func (h *FlightHandler) UpdateFlight(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
 	var flight models.Flight
-	if err := json.NewDecoder(r.Body).Decode(&flight); err != nil {
+	if err := utils.DecodeJSON(r.Body, &flight); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
+	flight.Version = version
 	updatedFlight, err := h.FlightService.UpdateFlight(id, &flight)
 	if err != nil {
-		utils.HandleError(w, err)
+		handleWriteError(w, err)
 		return
 	}
+	setETag(w, updatedFlight.Version)
 	utils.RespondWithJSON(w, http.StatusOK, updatedFlight)
 }
 
-// DeleteFlight handles DELETE /flights/{id}
+// PatchFlight handles PATCH /flights/{id} with a JSON merge patch (RFC 7396) and
+// the ETag of the version being patched in If-Match
+func (h *FlightHandler) PatchFlight(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+	patched, err := h.FlightService.PatchFlight(id, patch, version)
+	if err != nil {
+		handleWriteError(w, err)
+		return
+	}
+	setETag(w, patched.Version)
+	utils.RespondWithJSON(w, http.StatusOK, patched)
+}
+
+// DeleteFlight handles DELETE /flights/{id} with the ETag of the current version in If-Match
 func (h *FlightHandler) DeleteFlight(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
-	if err := h.FlightService.DeleteFlight(id); err != nil {
-		utils.HandleError(w, err)
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := h.FlightService.DeleteFlight(id, version); err != nil {
+		handleWriteError(w, err)
 		return
 	}
 	utils.RespondWithJSON(w, http.StatusNoContent, nil)
 }
+
+// GetFlightSeats handles GET /flights/{id}/seats?class=&available=
+func (h *FlightHandler) GetFlightSeats(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	filter := models.SeatFilter{Class: r.URL.Query().Get("class")}
+	if available := r.URL.Query().Get("available"); available != "" {
+		isAvailable, err := strconv.ParseBool(available)
+		if err != nil {
+			utils.HandleError(w, err)
+			return
+		}
+		filter.Available = &isAvailable
+	}
+
+	seats, err := h.FlightService.GetFlightSeats(id, filter)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, seats)
+}
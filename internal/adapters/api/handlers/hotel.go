@@ -1,9 +1,11 @@
 package handlers
 
 import (
-	"encoding/json"
 	"github.com/gorilla/mux"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"travel-backend/internal/core/domain/models"
 	"travel-backend/internal/ports/api"
 	"travel-backend/pkg/utils"
@@ -19,9 +21,94 @@ func NewHotelHandler(hotelService api.HotelService) *HotelHandler {
 	return &HotelHandler{HotelService: hotelService}
 }
 
-// GetHotels handles GET /hotels
+// GetHotels handles GET /hotels?city=&country=&minStars=&sort=&limit=&cursor=
 func (h *HotelHandler) GetHotels(w http.ResponseWriter, r *http.Request) {
-	hotels, err := h.HotelService.GetAllHotels()
+	query := r.URL.Query()
+	page, err := parsePage(query)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	list := models.HotelQuery{
+		City:    query.Get("city"),
+		Country: query.Get("country"),
+		Page:    page,
+	}
+	if minStars := query.Get("minStars"); minStars != "" {
+		list.MinStars, err = strconv.Atoi(minStars)
+		if err != nil {
+			utils.HandleError(w, err)
+			return
+		}
+	}
+
+	hotels, err := h.HotelService.ListHotels(list)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, hotels)
+}
+
+// SearchHotels handles GET /hotels/search?city=&country=&lat=&lng=&radiusKm=&checkIn=&checkOut=&guests=&rooms=&minStars=&amenities=&sort=
+// where hotels are searched by city or by lat/lng and radiusKm, and amenities
+// is a comma-separated list that every hotel must offer
+func (h *HotelHandler) SearchHotels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	near, radiusKm, err := parseLocation(query)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	search := models.HotelSearch{
+		Near:         near,
+		RadiusKm:     radiusKm,
+		City:         query.Get("city"),
+		Country:      query.Get("country"),
+		CheckInDate:  query.Get("checkIn"),
+		CheckOutDate: query.Get("checkOut"),
+		SortBy:       query.Get("sort"),
+	}
+	if amenities := query.Get("amenities"); amenities != "" {
+		search.Amenities = strings.Split(amenities, ",")
+	}
+	counts := map[string]*int{
+		"guests":   &search.Guests,
+		"rooms":    &search.Rooms,
+		"minStars": &search.MinStars,
+	}
+	for name, count := range counts {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				utils.HandleError(w, err)
+				return
+			}
+			*count = n
+		}
+	}
+
+	hotels, err := h.HotelService.SearchHotels(search)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, hotels)
+}
+
+// GetHotelsNear handles GET /hotels/nearby?lat=&lng=&radiusKm=
+func (h *HotelHandler) GetHotelsNear(w http.ResponseWriter, r *http.Request) {
+	near, radiusKm, err := parseLocation(r.URL.Query())
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	if near == nil {
+		utils.HandleError(w, models.Invalid("lat and lng are required"))
+		return
+	}
+
+	hotels, err := h.HotelService.GetHotelsNear(*near, radiusKm)
 	if err != nil {
 		utils.HandleError(w, err)
 		return
@@ -37,13 +124,16 @@ func (h *HotelHandler) GetHotelByID(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, err)
 		return
 	}
+	if hotel != nil {
+		setETag(w, hotel.Version)
+	}
 	utils.RespondWithJSON(w, http.StatusOK, hotel)
 }
 
 // CreateHotel handles POST /hotels
 func (h *HotelHandler) CreateHotel(w http.ResponseWriter, r *http.Request) {
-	var hotel models.Hotel
-	if err := json.NewDecoder(r.Body).Decode(&hotel); err != nil {
+	var hotel models.Property
+	if err := utils.DecodeJSON(r.Body, &hotel); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
@@ -52,31 +142,176 @@ func (h *HotelHandler) CreateHotel(w http.ResponseWriter, r *http.Request) {
 		utils.HandleError(w, err)
 		return
 	}
+	setETag(w, hotel.Version)
 	utils.RespondWithJSON(w, http.StatusCreated, hotel)
 }
 
-// UpdateHotel handles PUT /hotels/{id}
+// UpdateHotel handles PUT /hotels/{id} with the ETag of the version being replaced in If-Match
 func (h *HotelHandler) UpdateHotel(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
-	var hotel models.Hotel
-	if err := json.NewDecoder(r.Body).Decode(&hotel); err != nil {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	var hotel models.Property
+	if err := utils.DecodeJSON(r.Body, &hotel); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
+	hotel.Version = version
 	updatedHotel, err := h.HotelService.UpdateHotel(id, &hotel)
 	if err != nil {
-		utils.HandleError(w, err)
+		handleWriteError(w, err)
 		return
 	}
+	setETag(w, updatedHotel.Version)
 	utils.RespondWithJSON(w, http.StatusOK, updatedHotel)
 }
 
-// DeleteHotel handles DELETE /hotels/{id}
+// PatchHotel handles PATCH /hotels/{id} with a JSON merge patch (RFC 7396) and
+// the ETag of the version being patched in If-Match
+func (h *HotelHandler) PatchHotel(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := readMergePatch(w, r)
+	if !ok {
+		return
+	}
+	patched, err := h.HotelService.PatchHotel(id, patch, version)
+	if err != nil {
+		handleWriteError(w, err)
+		return
+	}
+	setETag(w, patched.Version)
+	utils.RespondWithJSON(w, http.StatusOK, patched)
+}
+
+// DeleteHotel handles DELETE /hotels/{id} with the ETag of the current version in If-Match
 func (h *HotelHandler) DeleteHotel(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
-	if err := h.HotelService.DeleteHotel(id); err != nil {
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := h.HotelService.DeleteHotel(id, version); err != nil {
+		handleWriteError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// GetHotelRooms handles GET /hotels/{id}/rooms
+func (h *HotelHandler) GetHotelRooms(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	rooms, err := h.HotelService.GetHotelRooms(id)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, rooms)
+}
+
+// GetHotelRoom handles GET /hotels/{id}/rooms/{roomTypeID}
+func (h *HotelHandler) GetHotelRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	room, err := h.HotelService.GetHotelRoom(vars["id"], vars["roomTypeID"])
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, room)
+}
+
+// AddHotelRoom handles POST /hotels/{id}/rooms
+func (h *HotelHandler) AddHotelRoom(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var room models.RoomType
+	if err := utils.DecodeJSON(r.Body, &room); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	if err := h.HotelService.AddHotelRoom(id, &room); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, room)
+}
+
+// UpdateHotelRoom handles PUT /hotels/{id}/rooms/{roomTypeID}
+func (h *HotelHandler) UpdateHotelRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var room models.RoomType
+	if err := utils.DecodeJSON(r.Body, &room); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	updatedRoom, err := h.HotelService.UpdateHotelRoom(vars["id"], vars["roomTypeID"], &room)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, updatedRoom)
+}
+
+// RemoveHotelRoom handles DELETE /hotels/{id}/rooms/{roomTypeID}
+func (h *HotelHandler) RemoveHotelRoom(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.HotelService.RemoveHotelRoom(vars["id"], vars["roomTypeID"]); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// GetHotelAvailability handles GET /hotels/{id}/availability?from=&to=&roomTypeID=
+// where the calendar covers the nights from up to but excluding to
+func (h *HotelHandler) GetHotelAvailability(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	query := r.URL.Query()
+	availability, err := h.HotelService.GetHotelAvailability(id, query.Get("roomTypeID"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, availability)
+}
+
+// SetRoomAllotment handles PUT /hotels/{id}/rooms/{roomTypeID}/allotment
+func (h *HotelHandler) SetRoomAllotment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var allotment models.RoomAllotment
+	if err := utils.DecodeJSON(r.Body, &allotment); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	if err := h.HotelService.SetRoomAllotment(vars["id"], vars["roomTypeID"], allotment); err != nil {
 		utils.HandleError(w, err)
 		return
 	}
 	utils.RespondWithJSON(w, http.StatusNoContent, nil)
 }
+
+// parseLocation reads the lat, lng and radiusKm query parameters. The point is
+// nil when neither lat nor lng is given.
+func parseLocation(query url.Values) (*models.GeoPoint, float64, error) {
+	lat, lng := query.Get("lat"), query.Get("lng")
+	if lat == "" && lng == "" {
+		return nil, 0, nil
+	}
+	latitude, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return nil, 0, models.InvalidField("lat", "lat must be a number")
+	}
+	longitude, err := strconv.ParseFloat(lng, 64)
+	if err != nil {
+		return nil, 0, models.InvalidField("lng", "lng must be a number")
+	}
+	radiusKm, err := strconv.ParseFloat(query.Get("radiusKm"), 64)
+	if err != nil {
+		return nil, 0, models.InvalidField("radiusKm", "radiusKm must be a number")
+	}
+	return &models.GeoPoint{Latitude: latitude, Longitude: longitude}, radiusKm, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// HotelReservationHandler handles hotel reservation API requests
+type HotelReservationHandler struct {
+	ReservationService api.HotelReservationService
+}
+
+// NewHotelReservationHandler creates a new instance of HotelReservationHandler
+func NewHotelReservationHandler(reservationService api.HotelReservationService) *HotelReservationHandler {
+	return &HotelReservationHandler{ReservationService: reservationService}
+}
+
+// GetHotelReservations handles GET /hotels/{id}/reservations
+func (h *HotelReservationHandler) GetHotelReservations(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	reservations, err := h.ReservationService.GetHotelReservations(id)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, reservations)
+}
+
+// GetHotelReservation handles GET /hotels/{id}/reservations/{reservationID}
+func (h *HotelReservationHandler) GetHotelReservation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reservation, err := h.ReservationService.GetHotelReservation(vars["id"], vars["reservationID"])
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, reservation)
+}
+
+// CreateHotelReservation handles POST /hotels/{id}/reservations
+func (h *HotelReservationHandler) CreateHotelReservation(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var reservation models.HotelReservation
+	if err := utils.DecodeJSON(r.Body, &reservation); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	if err := h.ReservationService.CreateHotelReservation(id, &reservation); err != nil {
+		// Sold-out problems carry alternative dates the client can offer in their details
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, reservation)
+}
+
+// CancelHotelReservation handles DELETE /hotels/{id}/reservations/{reservationID}
+func (h *HotelReservationHandler) CancelHotelReservation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reservation, err := h.ReservationService.CancelHotelReservation(vars["id"], vars["reservationID"])
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, reservation)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+)
+
+// ItineraryHandler handles connecting-itinerary API requests
+type ItineraryHandler struct {
+	ItineraryService api.ItineraryService
+}
+
+// NewItineraryHandler creates a new instance of ItineraryHandler
+func NewItineraryHandler(itineraryService api.ItineraryService) *ItineraryHandler {
+	return &ItineraryHandler{ItineraryService: itineraryService}
+}
+
+// SearchItineraries handles GET /itineraries/search?origin=&destination=&date=&maxStops=
+func (h *ItineraryHandler) SearchItineraries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := models.ItinerarySearch{
+		Origin:      query.Get("origin"),
+		Destination: query.Get("destination"),
+		Date:        query.Get("date"),
+		MaxStops:    models.MaxItineraryStops,
+	}
+	if maxStops := query.Get("maxStops"); maxStops != "" {
+		stops, err := strconv.Atoi(maxStops)
+		if err != nil {
+			utils.HandleError(w, err)
+			return
+		}
+		search.MaxStops = stops
+	}
+
+	itineraries, err := h.ItineraryService.SearchItineraries(search)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, itineraries)
+}
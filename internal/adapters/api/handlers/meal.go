@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// MealHandler handles meal catalogue API requests
+type MealHandler struct {
+	MealService api.MealService
+}
+
+// NewMealHandler creates a new instance of MealHandler
+func NewMealHandler(mealService api.MealService) *MealHandler {
+	return &MealHandler{MealService: mealService}
+}
+
+// GetMeals handles GET /meals?class=
+func (h *MealHandler) GetMeals(w http.ResponseWriter, r *http.Request) {
+	meals, err := h.MealService.GetMeals(r.URL.Query().Get("class"))
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, meals)
+}
+
+// GetMealByID handles GET /meals/{id}
+func (h *MealHandler) GetMealByID(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	meal, err := h.MealService.GetMealByID(id)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, meal)
+}
+
+// AddMeal handles POST /meals
+func (h *MealHandler) AddMeal(w http.ResponseWriter, r *http.Request) {
+	var meal models.Meal
+	if err := utils.DecodeJSON(r.Body, &meal); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	err := h.MealService.AddMeal(&meal)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, meal)
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+	"travel-backend/internal/core/domain/models"
+)
+
+// parsePage reads the limit, cursor and sort query parameters of a list request
+func parsePage(query url.Values) (models.PageRequest, error) {
+	page := models.PageRequest{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return page, err
+		}
+		page.Limit = n
+	}
+	return page, nil
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// PassengerHandler handles passenger-related API requests
+type PassengerHandler struct {
+	PassengerService api.PassengerService
+}
+
+// NewPassengerHandler creates a new instance of PassengerHandler
+func NewPassengerHandler(passengerService api.PassengerService) *PassengerHandler {
+	return &PassengerHandler{PassengerService: passengerService}
+}
+
+// GetPassengers handles GET /bookings/{id}/passengers
+func (h *PassengerHandler) GetPassengers(w http.ResponseWriter, r *http.Request) {
+	bookingID := mux.Vars(r)["id"]
+	passengers, err := h.PassengerService.GetAllPassengersByBookingID(bookingID)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, passengers)
+}
+
+// GetPassengerByID handles GET /bookings/{id}/passengers/{passengerID}
+func (h *PassengerHandler) GetPassengerByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	passenger, err := h.PassengerService.GetPassengerByID(vars["id"], vars["passengerID"])
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, passenger)
+}
+
+// AddPassenger handles POST /bookings/{id}/passengers
+func (h *PassengerHandler) AddPassenger(w http.ResponseWriter, r *http.Request) {
+	var passenger models.Passenger
+	if err := utils.DecodeJSON(r.Body, &passenger); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	passenger.BookingID = mux.Vars(r)["id"]
+	err := h.PassengerService.AddPassenger(&passenger)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, passenger)
+}
+
+// UpdatePassenger handles PUT /bookings/{id}/passengers/{passengerID}
+func (h *PassengerHandler) UpdatePassenger(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var passenger models.Passenger
+	if err := utils.DecodeJSON(r.Body, &passenger); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	updatedPassenger, err := h.PassengerService.UpdatePassenger(vars["id"], vars["passengerID"], &passenger)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, updatedPassenger)
+}
+
+// RemovePassenger handles DELETE /bookings/{id}/passengers/{passengerID}
+func (h *PassengerHandler) RemovePassenger(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.PassengerService.RemovePassenger(vars["id"], vars["passengerID"]); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// AssignSeat handles PUT /bookings/{id}/passengers/{passengerID}/seat
+func (h *PassengerHandler) AssignSeat(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var seatRequest struct {
+		SeatNumber string `json:"seatNumber"`
+		FlightID   string `json:"flightID"` // needed when the booking has several flights
+	}
+	if err := utils.DecodeJSON(r.Body, &seatRequest); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	seat := models.Seat{
+		FlightID:    seatRequest.FlightID,
+		SeatNumber:  seatRequest.SeatNumber,
+		PassengerID: vars["passengerID"],
+		BookingID:   vars["id"],
+	}
+	if err := h.PassengerService.AssignSeat(&seat); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, seat)
+}
+
+// GetPassengerSeats handles GET /bookings/{id}/seats
+func (h *PassengerHandler) GetPassengerSeats(w http.ResponseWriter, r *http.Request) {
+	bookingID := mux.Vars(r)["id"]
+	seats, err := h.PassengerService.GetPassengerSeats(bookingID)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, seats)
+}
+
+// AssignMeal handles PUT /bookings/{id}/passengers/{passengerID}/meal
+func (h *PassengerHandler) AssignMeal(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var mealRequest struct {
+		MealID string `json:"mealID"`
+	}
+	if err := utils.DecodeJSON(r.Body, &mealRequest); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	selection := models.MealSelection{
+		PassengerID: vars["passengerID"],
+		BookingID:   vars["id"],
+		MealID:      mealRequest.MealID,
+	}
+	if err := h.PassengerService.AssignMeal(&selection); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, selection)
+}
+
+// GetPassengerMeals handles GET /bookings/{id}/meals
+func (h *PassengerHandler) GetPassengerMeals(w http.ResponseWriter, r *http.Request) {
+	bookingID := mux.Vars(r)["id"]
+	meals, err := h.PassengerService.GetPassengerMeals(bookingID)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, meals)
+}
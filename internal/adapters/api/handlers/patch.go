@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"travel-backend/pkg/mergepatch"
+	"travel-backend/pkg/utils"
+)
+
+// readMergePatch reads a JSON merge patch request body. Bodies sent as
+// application/merge-patch+json or plain application/json are accepted; ok is
+// false once an error response has been written.
+func readMergePatch(w http.ResponseWriter, r *http.Request) (patch []byte, ok bool) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergepatch.ContentType && mediaType != "application/json") {
+			utils.RespondWithProblem(w, utils.NewProblem(http.StatusUnsupportedMediaType, "unsupported_media_type",
+				"PATCH bodies must be "+mergepatch.ContentType))
+			return nil, false
+		}
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.HandleError(w, err)
+		return nil, false
+	}
+	return patch, true
+}
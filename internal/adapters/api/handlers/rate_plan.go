@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// RatePlanHandler handles hotel rate plan and pricing API requests
+type RatePlanHandler struct {
+	RatePlanService api.RatePlanService
+}
+
+// NewRatePlanHandler creates a new instance of RatePlanHandler
+func NewRatePlanHandler(ratePlanService api.RatePlanService) *RatePlanHandler {
+	return &RatePlanHandler{RatePlanService: ratePlanService}
+}
+
+// GetRatePlans handles GET /hotels/{id}/rate-plans
+func (h *RatePlanHandler) GetRatePlans(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ratePlans, err := h.RatePlanService.GetRatePlans(id)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, ratePlans)
+}
+
+// GetRatePlan handles GET /hotels/{id}/rate-plans/{ratePlanID}
+func (h *RatePlanHandler) GetRatePlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ratePlan, err := h.RatePlanService.GetRatePlan(vars["id"], vars["ratePlanID"])
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, ratePlan)
+}
+
+// CreateRatePlan handles POST /hotels/{id}/rate-plans
+func (h *RatePlanHandler) CreateRatePlan(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	var ratePlan models.RatePlan
+	if err := utils.DecodeJSON(r.Body, &ratePlan); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	if err := h.RatePlanService.CreateRatePlan(id, &ratePlan); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, ratePlan)
+}
+
+// UpdateRatePlan handles PUT /hotels/{id}/rate-plans/{ratePlanID}
+func (h *RatePlanHandler) UpdateRatePlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var ratePlan models.RatePlan
+	if err := utils.DecodeJSON(r.Body, &ratePlan); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	updatedRatePlan, err := h.RatePlanService.UpdateRatePlan(vars["id"], vars["ratePlanID"], &ratePlan)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, updatedRatePlan)
+}
+
+// DeleteRatePlan handles DELETE /hotels/{id}/rate-plans/{ratePlanID}
+func (h *RatePlanHandler) DeleteRatePlan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.RatePlanService.DeleteRatePlan(vars["id"], vars["ratePlanID"]); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// GetRoomRates handles GET /hotels/{id}/rate-plans/{ratePlanID}/rates?roomTypeID=&from=&to=
+// where the rates cover the nights from up to but excluding to
+func (h *RatePlanHandler) GetRoomRates(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+	rates, err := h.RatePlanService.GetRoomRates(vars["id"], vars["ratePlanID"], query.Get("roomTypeID"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, rates)
+}
+
+// SetRoomRates handles PUT /hotels/{id}/rate-plans/{ratePlanID}/rates
+func (h *RatePlanHandler) SetRoomRates(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var update models.RoomRateUpdate
+	if err := utils.DecodeJSON(r.Body, &update); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	if err := h.RatePlanService.SetRoomRates(vars["id"], vars["ratePlanID"], update); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// QuoteStay handles GET /hotels/{id}/quote?roomTypeID=&checkIn=&checkOut=&guests=&ratePlanID=
+func (h *RatePlanHandler) QuoteStay(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	query := r.URL.Query()
+	request := models.StayQuoteRequest{
+		RoomTypeID:   query.Get("roomTypeID"),
+		RatePlanID:   query.Get("ratePlanID"),
+		CheckInDate:  query.Get("checkIn"),
+		CheckOutDate: query.Get("checkOut"),
+	}
+	if guests := query.Get("guests"); guests != "" {
+		count, err := strconv.Atoi(guests)
+		if err != nil {
+			utils.HandleError(w, err)
+			return
+		}
+		request.NumberOfGuests = count
+	}
+
+	quotes, err := h.RatePlanService.QuoteStay(id, request)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, quotes)
+}
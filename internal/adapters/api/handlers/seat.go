@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// SeatHandler handles seat hold API requests
+type SeatHandler struct {
+	SeatService api.SeatService
+}
+
+// NewSeatHandler creates a new instance of SeatHandler
+func NewSeatHandler(seatService api.SeatService) *SeatHandler {
+	return &SeatHandler{SeatService: seatService}
+}
+
+// HoldSeat handles POST /flights/{id}/seats/{seatNumber}/hold
+func (h *SeatHandler) HoldSeat(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var holdRequest struct {
+		BookingID string `json:"bookingID"`
+	}
+	if err := utils.DecodeJSON(r.Body, &holdRequest); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	hold, err := h.SeatService.HoldSeat(r.Context(), vars["id"], vars["seatNumber"], holdRequest.BookingID)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, hold)
+}
+
+// ReleaseSeat handles DELETE /flights/{id}/seats/{seatNumber}/hold?bookingID=
+func (h *SeatHandler) ReleaseSeat(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bookingID := r.URL.Query().Get("bookingID")
+	if err := h.SeatService.ReleaseSeat(r.Context(), vars["id"], vars["seatNumber"], bookingID); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+// ConfirmSeat handles POST /flights/{id}/seats/{seatNumber}/confirm
+func (h *SeatHandler) ConfirmSeat(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	var confirmRequest struct {
+		BookingID   string `json:"bookingID"`
+		PassengerID string `json:"passengerID"`
+	}
+	if err := utils.DecodeJSON(r.Body, &confirmRequest); err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	seat, err := h.SeatService.ConfirmSeat(r.Context(), vars["id"], vars["seatNumber"], confirmRequest.BookingID, confirmRequest.PassengerID)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, seat)
+}
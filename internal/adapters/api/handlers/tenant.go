@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/utils"
+)
+
+// TenantHandler describes the travel agency a request is served for
+type TenantHandler struct {
+	Tenant *models.Tenant
+}
+
+// NewTenantHandler creates a new instance of TenantHandler
+func NewTenantHandler(tenant *models.Tenant) *TenantHandler {
+	return &TenantHandler{Tenant: tenant}
+}
+
+// GetTenant handles GET /tenant, returning the agency's branding, markup and
+// the airlines and hotel chains it sells
+func (h *TenantHandler) GetTenant(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, h.Tenant)
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// IdempotencyKeyHeader names the header clients send to make a POST safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks responses replayed from an earlier request
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// replayedHeaders are the response headers remembered and replayed besides
+// Content-Type
+var replayedHeaders = []string{"ETag", "Location"}
+
+// Idempotency returns middleware that makes POST requests carrying an
+// Idempotency-Key header safe to retry. The first request with a key runs as
+// usual and its response is remembered for ttl; repeats of the same request
+// get that response replayed instead of running again. Reusing a key for a
+// different method, path or body fails with 409 Conflict, as does a repeat
+// that arrives while the first request is still running; once lockTimeout has
+// passed without the first request completing, a repeat runs in its place.
+// Server errors, panics and refusals to authenticate or authorize the caller
+// are not remembered, so the client can retry them with the same key.
+func Idempotency(repo db.IdempotencyRepository, ttl, lockTimeout time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				utils.RespondWithProblem(w, utils.NewProblem(http.StatusBadRequest, "invalid_idempotency_key",
+					"Idempotency-Key must be at most 255 characters"))
+				return
+			}
+			// Keys belong to the caller, so nobody can replay someone else's response
+			if principal, ok := models.PrincipalFromContext(r.Context()); ok {
+				key = principal.UserID + "/" + key
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.HandleError(w, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now().UTC()
+			record := &models.IdempotencyRecord{
+				Key:         key,
+				Fingerprint: requestFingerprint(r, body),
+				CreatedAt:   now,
+				LockedUntil: now.Add(lockTimeout),
+				ExpiresAt:   now.Add(ttl),
+			}
+			err = repo.CreateIdempotencyRecord(record)
+			if errors.Is(err, db.ErrIdempotencyKeyInUse) {
+				replayIdempotent(w, repo, record)
+				return
+			}
+			if err != nil {
+				utils.HandleError(w, err)
+				return
+			}
+
+			release := func() {
+				if err := repo.DeleteIdempotencyRecord(key); err != nil {
+					log.Printf("Error releasing idempotency key after a failed request: %v", err)
+				}
+			}
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			switch {
+			case recorder.statusCode >= http.StatusInternalServerError,
+				recorder.statusCode == http.StatusUnauthorized,
+				recorder.statusCode == http.StatusForbidden:
+				release()
+				return
+			}
+			record.Completed = true
+			record.StatusCode = recorder.statusCode
+			record.ContentType = recorder.Header().Get("Content-Type")
+			for _, name := range replayedHeaders {
+				if value := recorder.Header().Get(name); value != "" {
+					if record.Headers == nil {
+						record.Headers = make(map[string]string)
+					}
+					record.Headers[name] = value
+				}
+			}
+			record.Body = recorder.body.Bytes()
+			if err := repo.CompleteIdempotencyRecord(record); err != nil {
+				log.Printf("Error saving response for idempotency key: %v", err)
+			}
+		})
+	}
+}
+
+// replayIdempotent answers a request whose key is already in use with the
+// response remembered for it
+func replayIdempotent(w http.ResponseWriter, repo db.IdempotencyRepository, request *models.IdempotencyRecord) {
+	existing, err := repo.GetIdempotencyRecord(request.Key)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	switch {
+	case existing == nil:
+		// The key expired between the two calls; the client can simply retry
+		utils.RespondWithProblem(w, utils.NewProblem(http.StatusConflict, "idempotency_key_expired",
+			"idempotency key expired while checking it, please retry"))
+	case existing.Fingerprint != request.Fingerprint:
+		utils.RespondWithProblem(w, utils.NewProblem(http.StatusConflict, "idempotency_key_reused",
+			"Idempotency-Key was already used for a different request"))
+	case !existing.Completed:
+		utils.RespondWithProblem(w, utils.NewProblem(http.StatusConflict, "idempotency_key_in_progress",
+			"a request with this Idempotency-Key is still being processed"))
+	default:
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		for name, value := range existing.Headers {
+			w.Header().Set(name, value)
+		}
+		w.Header().Set(idempotentReplayedHeader, "true")
+		w.WriteHeader(existing.StatusCode)
+		if _, err := w.Write(existing.Body); err != nil {
+			log.Printf("Error replaying idempotent response: %v", err)
+		}
+	}
+}
+
+// requestFingerprint hashes what makes two requests the same: method, path and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"travel-backend/internal/adapters/db/memory"
+	"travel-backend/internal/core/domain/models"
+)
+
+// post sends a POST with an idempotency key through handler
+func post(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/bookings/", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysHeaders(t *testing.T) {
+	calls := 0
+	handler := Idempotency(memory.NewIdempotencyRepo(memory.NewStore()), time.Hour, time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", `"1"`)
+			w.Header().Set("Location", "/bookings/b1")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"bookingID":"b1"}`))
+		}))
+
+	post(handler, "k1", "{}")
+	replayed := post(handler, "k1", "{}")
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if replayed.Code != http.StatusCreated || replayed.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Fatalf("replay status %d, replayed header %q, want 201 marked as replayed", replayed.Code, replayed.Header().Get(idempotentReplayedHeader))
+	}
+	for name, want := range map[string]string{"Content-Type": "application/json", "ETag": `"1"`, "Location": "/bookings/b1"} {
+		if got := replayed.Header().Get(name); got != want {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+	if replayed.Body.String() != `{"bookingID":"b1"}` {
+		t.Errorf("replayed body = %s", replayed.Body)
+	}
+}
+
+func TestIdempotencyForgetsFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(w http.ResponseWriter)
+	}{
+		{name: "server error", respond: func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) }},
+		{name: "unauthenticated", respond: func(w http.ResponseWriter) { w.WriteHeader(http.StatusUnauthorized) }},
+		{name: "forbidden", respond: func(w http.ResponseWriter) { w.WriteHeader(http.StatusForbidden) }},
+		{name: "panic", respond: func(w http.ResponseWriter) { panic("handler failed") }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			handler := Idempotency(memory.NewIdempotencyRepo(memory.NewStore()), time.Hour, time.Minute)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls++
+					if calls == 1 {
+						test.respond(w)
+						return
+					}
+					w.WriteHeader(http.StatusCreated)
+				}))
+
+			func() {
+				defer func() { recover() }()
+				post(handler, "k1", "{}")
+			}()
+			if retry := post(handler, "k1", "{}"); retry.Code != http.StatusCreated || calls != 2 {
+				t.Fatalf("retry status %d after %d calls, want 201 from a second call", retry.Code, calls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyTakesOverStaleLocks(t *testing.T) {
+	now := time.Now().UTC()
+	req := httptest.NewRequest(http.MethodPost, "/bookings/", strings.NewReader("{}"))
+	fingerprint := requestFingerprint(req, []byte("{}"))
+
+	tests := []struct {
+		name        string
+		fingerprint string
+		lockedUntil time.Time
+		wantStatus  int
+	}{
+		{name: "request still running", fingerprint: fingerprint, lockedUntil: now.Add(time.Minute), wantStatus: http.StatusConflict},
+		{name: "request died", fingerprint: fingerprint, lockedUntil: now.Add(-time.Second), wantStatus: http.StatusCreated},
+		{name: "different request", fingerprint: "other", lockedUntil: now.Add(-time.Second), wantStatus: http.StatusConflict},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := memory.NewIdempotencyRepo(memory.NewStore())
+			err := repo.CreateIdempotencyRecord(&models.IdempotencyRecord{
+				Key: "k1", Fingerprint: test.fingerprint, CreatedAt: now.Add(-time.Minute),
+				LockedUntil: test.lockedUntil, ExpiresAt: now.Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatalf("CreateIdempotencyRecord() error = %v", err)
+			}
+			handler := Idempotency(repo, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			}))
+			if got := post(handler, "k1", "{}"); got.Code != test.wantStatus {
+				t.Fatalf("status %d, want %d", got.Code, test.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/api"
+	"travel-backend/pkg/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// Policy decides whether a request may reach its route. It returns nil to let
+// the request through, or the error to answer it with: models.Unauthenticated
+// for anonymous callers and models.Forbidden for callers without permission.
+type Policy func(r *http.Request) error
+
+// allow guards handler with policy
+func allow(policy Policy, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := policy(r); err != nil {
+			utils.HandleError(w, err)
+			return
+		}
+		handler(w, r)
+	})
+}
+
+// anyone lets every request through, signed in or not
+func anyone(*http.Request) error {
+	return nil
+}
+
+// signedIn lets through requests from any authenticated caller
+func signedIn(r *http.Request) error {
+	_, err := models.RequirePrincipal(r.Context())
+	return err
+}
+
+// withRole lets through callers holding any of roles
+func withRole(roles ...models.Role) Policy {
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return func(r *http.Request) error {
+		principal, err := models.RequirePrincipal(r.Context())
+		if err != nil {
+			return err
+		}
+		if !principal.HasRole(roles...) {
+			return models.Forbidden(models.CodeInsufficientRole, "this operation requires the role %s", strings.Join(names, " or "))
+		}
+		return nil
+	}
+}
+
+// Policies of the routes that manage the catalogue and other users' trips
+var (
+	staff     = withRole(models.RoleAgent, models.RoleOpsAdmin)
+	operators = withRole(models.RoleOpsAdmin)
+)
+
+// bookingOwner lets through callers who may see the booking named by the
+// route's {id}, as decided by the booking service: its owner and staff
+func bookingOwner(bookings api.BookingService) Policy {
+	return func(r *http.Request) error {
+		_, err := bookings.GetBookingByID(r.Context(), mux.Vars(r)["id"])
+		return err
+	}
+}
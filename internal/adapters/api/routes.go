@@ -7,28 +7,91 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// SetupRoutes sets up the API routes
-func SetupRoutes(router *mux.Router, hotelHandler *handlers.HotelHandler, flightHandler *handlers.FlightHandler, bookingHandler *handlers.BookingHandler) {
+// SetupRoutes sets up the API routes, each guarded by the policy of who may
+// call it: anyone, any signed-in caller, staff (agents and ops admins) or
+// operators (ops admins only)
+func SetupRoutes(router *mux.Router, hotelHandler *handlers.HotelHandler, flightHandler *handlers.FlightHandler, bookingHandler *handlers.BookingHandler, seatHandler *handlers.SeatHandler, passengerHandler *handlers.PassengerHandler, mealHandler *handlers.MealHandler, itineraryHandler *handlers.ItineraryHandler, fareHandler *handlers.FareHandler, reservationHandler *handlers.HotelReservationHandler, ratePlanHandler *handlers.RatePlanHandler, tenantHandler *handlers.TenantHandler) {
+	// Tenant routes
+	router.Handle("/tenant", allow(anyone, tenantHandler.GetTenant)).Methods(http.MethodGet)
+
 	// Hotel routes
 	hotelRouter := router.PathPrefix("/hotels").Subrouter()
-	hotelRouter.HandleFunc("/", hotelHandler.GetHotels).Methods(http.MethodGet)
-	hotelRouter.HandleFunc("/{id}", hotelHandler.GetHotelByID).Methods(http.MethodGet)
-	hotelRouter.HandleFunc("/", hotelHandler.CreateHotel).Methods(http.MethodPost)
-	hotelRouter.HandleFunc("/{id}", hotelHandler.UpdateHotel).Methods(http.MethodPut)
-	hotelRouter.HandleFunc("/{id}", hotelHandler.DeleteHotel).Methods(http.MethodDelete)
+	hotelRouter.Handle("/", allow(anyone, hotelHandler.GetHotels)).Methods(http.MethodGet)
+	hotelRouter.Handle("/search", allow(anyone, hotelHandler.SearchHotels)).Methods(http.MethodGet)
+	hotelRouter.Handle("/nearby", allow(anyone, hotelHandler.GetHotelsNear)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}", allow(anyone, hotelHandler.GetHotelByID)).Methods(http.MethodGet)
+	hotelRouter.Handle("/", allow(operators, hotelHandler.CreateHotel)).Methods(http.MethodPost)
+	hotelRouter.Handle("/{id}", allow(operators, hotelHandler.UpdateHotel)).Methods(http.MethodPut)
+	hotelRouter.Handle("/{id}", allow(operators, hotelHandler.PatchHotel)).Methods(http.MethodPatch)
+	hotelRouter.Handle("/{id}", allow(operators, hotelHandler.DeleteHotel)).Methods(http.MethodDelete)
+	hotelRouter.Handle("/{id}/rooms", allow(anyone, hotelHandler.GetHotelRooms)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/rooms", allow(operators, hotelHandler.AddHotelRoom)).Methods(http.MethodPost)
+	hotelRouter.Handle("/{id}/rooms/{roomTypeID}", allow(anyone, hotelHandler.GetHotelRoom)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/rooms/{roomTypeID}", allow(operators, hotelHandler.UpdateHotelRoom)).Methods(http.MethodPut)
+	hotelRouter.Handle("/{id}/rooms/{roomTypeID}", allow(operators, hotelHandler.RemoveHotelRoom)).Methods(http.MethodDelete)
+	hotelRouter.Handle("/{id}/rooms/{roomTypeID}/allotment", allow(operators, hotelHandler.SetRoomAllotment)).Methods(http.MethodPut)
+	hotelRouter.Handle("/{id}/availability", allow(anyone, hotelHandler.GetHotelAvailability)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/rate-plans", allow(anyone, ratePlanHandler.GetRatePlans)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/rate-plans", allow(operators, ratePlanHandler.CreateRatePlan)).Methods(http.MethodPost)
+	hotelRouter.Handle("/{id}/rate-plans/{ratePlanID}", allow(anyone, ratePlanHandler.GetRatePlan)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/rate-plans/{ratePlanID}", allow(operators, ratePlanHandler.UpdateRatePlan)).Methods(http.MethodPut)
+	hotelRouter.Handle("/{id}/rate-plans/{ratePlanID}", allow(operators, ratePlanHandler.DeleteRatePlan)).Methods(http.MethodDelete)
+	hotelRouter.Handle("/{id}/rate-plans/{ratePlanID}/rates", allow(anyone, ratePlanHandler.GetRoomRates)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/rate-plans/{ratePlanID}/rates", allow(operators, ratePlanHandler.SetRoomRates)).Methods(http.MethodPut)
+	hotelRouter.Handle("/{id}/quote", allow(anyone, ratePlanHandler.QuoteStay)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/reservations", allow(staff, reservationHandler.GetHotelReservations)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/reservations", allow(staff, reservationHandler.CreateHotelReservation)).Methods(http.MethodPost)
+	hotelRouter.Handle("/{id}/reservations/{reservationID}", allow(staff, reservationHandler.GetHotelReservation)).Methods(http.MethodGet)
+	hotelRouter.Handle("/{id}/reservations/{reservationID}", allow(staff, reservationHandler.CancelHotelReservation)).Methods(http.MethodDelete)
 
 	// Flight routes
 	flightRouter := router.PathPrefix("/flights").Subrouter()
-	flightRouter.HandleFunc("/", flightHandler.GetFlights).Methods(http.MethodGet)
-	flightRouter.HandleFunc("/{id}", flightHandler.GetFlightByID).Methods(http.MethodGet)
-	flightRouter.HandleFunc("/", flightHandler.CreateFlight).Methods(http.MethodPost)
-	flightRouter.HandleFunc("/{id}", flightHandler.UpdateFlight).Methods(http.MethodPut)
-	flightRouter.HandleFunc("/{id}", flightHandler.DeleteFlight).Methods(http.MethodDelete)
+	flightRouter.Handle("/", allow(anyone, flightHandler.GetFlights)).Methods(http.MethodGet)
+	flightRouter.Handle("/search", allow(anyone, flightHandler.SearchFlights)).Methods(http.MethodGet)
+	flightRouter.Handle("/{id}", allow(anyone, flightHandler.GetFlightByID)).Methods(http.MethodGet)
+	flightRouter.Handle("/", allow(operators, flightHandler.CreateFlight)).Methods(http.MethodPost)
+	flightRouter.Handle("/{id}", allow(operators, flightHandler.UpdateFlight)).Methods(http.MethodPut)
+	flightRouter.Handle("/{id}", allow(operators, flightHandler.PatchFlight)).Methods(http.MethodPatch)
+	flightRouter.Handle("/{id}", allow(operators, flightHandler.DeleteFlight)).Methods(http.MethodDelete)
+	flightRouter.Handle("/{id}/seats", allow(anyone, flightHandler.GetFlightSeats)).Methods(http.MethodGet)
+	flightRouter.Handle("/{id}/seats/{seatNumber}/hold", allow(signedIn, seatHandler.HoldSeat)).Methods(http.MethodPost)
+	flightRouter.Handle("/{id}/seats/{seatNumber}/hold", allow(signedIn, seatHandler.ReleaseSeat)).Methods(http.MethodDelete)
+	flightRouter.Handle("/{id}/seats/{seatNumber}/confirm", allow(signedIn, seatHandler.ConfirmSeat)).Methods(http.MethodPost)
+	flightRouter.Handle("/{id}/fares", allow(anyone, fareHandler.GetFlightFares)).Methods(http.MethodGet)
+	flightRouter.Handle("/{id}/fares", allow(operators, fareHandler.CreateFare)).Methods(http.MethodPost)
+	flightRouter.Handle("/{id}/quote", allow(anyone, fareHandler.QuoteFare)).Methods(http.MethodPost)
+
+	// Itinerary routes
+	itineraryRouter := router.PathPrefix("/itineraries").Subrouter()
+	itineraryRouter.Handle("/search", allow(anyone, itineraryHandler.SearchItineraries)).Methods(http.MethodGet)
 
-	// Booking routes
+	// Booking routes. Customers reach only their own bookings, which the
+	// booking service checks, and their bookings' passengers through ownBooking
+	ownBooking := bookingOwner(bookingHandler.BookingService)
 	bookingRouter := router.PathPrefix("/bookings").Subrouter()
-	bookingRouter.HandleFunc("/", bookingHandler.CreateBooking).Methods(http.MethodPost)
-	bookingRouter.HandleFunc("/{id}", bookingHandler.GetBookingByID).Methods(http.MethodGet)
-	bookingRouter.HandleFunc("/{id}", bookingHandler.UpdateBooking).Methods(http.MethodPut)
-	bookingRouter.HandleFunc("/{id}", bookingHandler.DeleteBooking).Methods(http.MethodDelete)
+	bookingRouter.Handle("/", allow(signedIn, bookingHandler.GetBookings)).Methods(http.MethodGet)
+	bookingRouter.Handle("/", allow(signedIn, bookingHandler.CreateBooking)).Methods(http.MethodPost)
+	bookingRouter.Handle("/locator/{code}", allow(anyone, bookingHandler.GetBookingByLocator)).Methods(http.MethodGet)
+	bookingRouter.Handle("/{id}", allow(signedIn, bookingHandler.GetBookingByID)).Methods(http.MethodGet)
+	bookingRouter.Handle("/{id}", allow(signedIn, bookingHandler.UpdateBooking)).Methods(http.MethodPut)
+	bookingRouter.Handle("/{id}", allow(signedIn, bookingHandler.PatchBooking)).Methods(http.MethodPatch)
+	bookingRouter.Handle("/{id}", allow(signedIn, bookingHandler.DeleteBooking)).Methods(http.MethodDelete)
+	bookingRouter.Handle("/{id}/status", allow(signedIn, bookingHandler.UpdateBookingStatus)).Methods(http.MethodPut)
+	bookingRouter.Handle("/{id}/seats", allow(ownBooking, passengerHandler.GetPassengerSeats)).Methods(http.MethodGet)
+	bookingRouter.Handle("/{id}/meals", allow(ownBooking, passengerHandler.GetPassengerMeals)).Methods(http.MethodGet)
+
+	// Passenger routes
+	bookingRouter.Handle("/{id}/passengers", allow(ownBooking, passengerHandler.GetPassengers)).Methods(http.MethodGet)
+	bookingRouter.Handle("/{id}/passengers", allow(ownBooking, passengerHandler.AddPassenger)).Methods(http.MethodPost)
+	bookingRouter.Handle("/{id}/passengers/{passengerID}", allow(ownBooking, passengerHandler.GetPassengerByID)).Methods(http.MethodGet)
+	bookingRouter.Handle("/{id}/passengers/{passengerID}", allow(ownBooking, passengerHandler.UpdatePassenger)).Methods(http.MethodPut)
+	bookingRouter.Handle("/{id}/passengers/{passengerID}", allow(ownBooking, passengerHandler.RemovePassenger)).Methods(http.MethodDelete)
+	bookingRouter.Handle("/{id}/passengers/{passengerID}/seat", allow(ownBooking, passengerHandler.AssignSeat)).Methods(http.MethodPut)
+	bookingRouter.Handle("/{id}/passengers/{passengerID}/meal", allow(ownBooking, passengerHandler.AssignMeal)).Methods(http.MethodPut)
+
+	// Meal catalogue routes
+	mealRouter := router.PathPrefix("/meals").Subrouter()
+	mealRouter.Handle("/", allow(anyone, mealHandler.GetMeals)).Methods(http.MethodGet)
+	mealRouter.Handle("/{id}", allow(anyone, mealHandler.GetMealByID)).Methods(http.MethodGet)
+	mealRouter.Handle("/", allow(operators, mealHandler.AddMeal)).Methods(http.MethodPost)
 }
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/utils"
+)
+
+// TenantRouter serves every request with the handler of its tenant, each of
+// which has repositories and services of its own, so that no request can
+// reach another tenant's data. It must run after Authenticate.
+//
+// The tenant is the one named by the caller's token, or else the one owning
+// the API key sent in the X-API-Key header, or else the default tenant. A
+// request whose token and API key name different tenants is refused.
+type TenantRouter struct {
+	tenants       map[string]*models.Tenant
+	apiKeys       map[[sha256.Size]byte]*models.Tenant
+	defaultTenant *models.Tenant
+	handlers      map[string]http.Handler
+}
+
+// NewTenantRouter builds the handler of every tenant with build. defaultID
+// names the tenant of requests that name none; when empty they are refused.
+func NewTenantRouter(tenants []models.Tenant, defaultID string, build func(tenant *models.Tenant) http.Handler) *TenantRouter {
+	t := &TenantRouter{
+		tenants:  make(map[string]*models.Tenant, len(tenants)),
+		apiKeys:  make(map[[sha256.Size]byte]*models.Tenant),
+		handlers: make(map[string]http.Handler, len(tenants)),
+	}
+	for i := range tenants {
+		tenant := &tenants[i]
+		t.tenants[tenant.ID] = tenant
+		for _, key := range tenant.APIKeys {
+			t.apiKeys[sha256.Sum256([]byte(key))] = tenant
+		}
+		t.handlers[tenant.ID] = build(tenant)
+	}
+	t.defaultTenant = t.tenants[defaultID]
+	return t
+}
+
+func (t *TenantRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenant, err := t.resolve(r)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+	ctx := models.ContextWithTenant(r.Context(), tenant)
+	t.handlers[tenant.ID].ServeHTTP(w, r.WithContext(ctx))
+}
+
+// resolve finds the tenant a request is made for
+func (t *TenantRouter) resolve(r *http.Request) (*models.Tenant, error) {
+	// Keys are looked up by their digest, so how long the lookup takes
+	// reveals nothing about the keys themselves
+	var keyTenant *models.Tenant
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		keyTenant = t.apiKeys[sha256.Sum256([]byte(key))]
+		if keyTenant == nil {
+			return nil, models.Unauthenticated(models.CodeInvalidAPIKey, "API key is not recognised")
+		}
+	}
+
+	principal, signedIn := models.PrincipalFromContext(r.Context())
+	switch {
+	case signedIn:
+		tenant := t.defaultTenant
+		if principal.TenantID != "" {
+			tenant = t.tenants[principal.TenantID]
+			if tenant == nil {
+				return nil, models.Forbidden(models.CodeUnknownTenant, "token names unknown tenant %q", principal.TenantID)
+			}
+		}
+		if tenant == nil {
+			return nil, models.Forbidden(models.CodeTenantRequired, "token does not name a tenant")
+		}
+		if keyTenant != nil && keyTenant != tenant {
+			return nil, models.Forbidden(models.CodeTenantMismatch, "API key belongs to a different tenant than the token")
+		}
+		principal.TenantID = tenant.ID
+		return tenant, nil
+	case keyTenant != nil:
+		return keyTenant, nil
+	case t.defaultTenant != nil:
+		return t.defaultTenant, nil
+	}
+	return nil, models.Unauthenticated(models.CodeTenantRequired, "requests must carry an API key or a bearer token naming a tenant")
+}
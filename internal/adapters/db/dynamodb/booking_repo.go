@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"log"
+	"time"
 	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -12,6 +14,15 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+const (
+	bookingsTable = "Bookings"
+	// bookingsUserIDIndex is a GSI with UserID, a copy of userID, as partition key
+	bookingsUserIDIndex = "UserID-index"
+	// bookingLocatorsTable maps each record locator to its booking, with
+	// locator as partition key, so that a locator can only be claimed once
+	bookingLocatorsTable = "BookingLocators"
+)
+
 type BookingRepo struct {
 	client *dynamodb.Client
 }
@@ -20,33 +31,57 @@ func NewBookingRepo(client *dynamodb.Client) *BookingRepo {
 	return &BookingRepo{client: client}
 }
 
-func (r *BookingRepo) GetAllBookings() ([]models.Booking, error) {
-	input := &dynamodb.ScanInput{
-		TableName: aws.String("Bookings"),
+// ListBookings returns one page of the bookings matching query. A user's
+// bookings are read through the UserID index; everyone's are scanned. Pages
+// follow the index or table order; sorting would mean reading every matching
+// booking first, so sort orders are refused.
+func (r *BookingRepo) ListBookings(query models.BookingQuery) (*models.BookingPage, error) {
+	if err := checkUnsorted(query.Page); err != nil {
+		return nil, err
 	}
 
-	result, err := r.client.Scan(context.Background(), input)
-	if err != nil {
-		log.Printf("Error fetching bookings: %v", err)
+	var filter scanFilter
+	if query.Status != "" {
+		filter.add("bookingStatus", "=", &types.AttributeValueMemberS{Value: string(query.Status)})
+	}
+	if err := filter.dateRange("createdAt", query.From, query.To); err != nil {
 		return nil, err
 	}
 
-	var bookings []models.Booking
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &bookings)
+	var (
+		bookings = []models.Booking{}
+		next     string
+		err      error
+	)
+	if query.UserID != "" {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(bookingsTable),
+			IndexName:              aws.String(bookingsUserIDIndex),
+			KeyConditionExpression: aws.String("UserID = :userID"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":userID": &types.AttributeValueMemberS{Value: query.UserID},
+			},
+		}
+		filter.applyQuery(input)
+		next, err = queryPage(r.client, input, query.Page, &bookings)
+	} else {
+		input := &dynamodb.ScanInput{
+			TableName: aws.String(bookingsTable),
+		}
+		filter.apply(input)
+		next, err = scanPage(r.client, input, query.Page, &bookings)
+	}
 	if err != nil {
-		log.Printf("Error unmarshalling bookings: %v", err)
+		log.Printf("Error fetching bookings: %v", err)
 		return nil, err
 	}
-
-	return bookings, nil
+	return &models.BookingPage{Items: bookings, NextCursor: next}, nil
 }
 
 func (r *BookingRepo) GetBookingByID(id string) (*models.Booking, error) {
 	input := &dynamodb.GetItemInput{
-		TableName: aws.String("Bookings"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
+		TableName: aws.String(bookingsTable),
+		Key:       bookingKey(id),
 	}
 
 	result, err := r.client.GetItem(context.Background(), input)
@@ -54,6 +89,9 @@ func (r *BookingRepo) GetBookingByID(id string) (*models.Booking, error) {
 		log.Printf("Error fetching booking: %v", err)
 		return nil, err
 	}
+	if result.Item == nil {
+		return nil, nil
+	}
 
 	var booking models.Booking
 	err = attributevalue.UnmarshalMap(result.Item, &booking)
@@ -65,25 +103,75 @@ func (r *BookingRepo) GetBookingByID(id string) (*models.Booking, error) {
 	return &booking, nil
 }
 
-// CreateBooking adds a new booking to the database
+// GetBookingByLocator retrieves the booking with the given record locator
+func (r *BookingRepo) GetBookingByLocator(locator string) (*models.Booking, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(bookingLocatorsTable),
+		Key:       bookingLocatorKey(locator),
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching booking locator %s: %v", locator, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var entry struct {
+		BookingID string `dynamodbav:"bookingID"`
+	}
+	if err := attributevalue.UnmarshalMap(result.Item, &entry); err != nil {
+		log.Printf("Error unmarshalling booking locator: %v", err)
+		return nil, err
+	}
+
+	return r.GetBookingByID(entry.BookingID)
+}
+
+// CreateBooking adds a new booking to the database and claims its locator in
+// the same transaction, failing with db.ErrLocatorTaken if the locator is in use
 func (r *BookingRepo) CreateBooking(booking *models.Booking) error {
 	if booking == nil {
 		return errors.New("booking details are nil")
 	}
 
-	item, err := attributevalue.MarshalMap(booking)
+	booking.Version = 1
+	item, err := bookingItem(booking)
 	if err != nil {
 		log.Printf("Error marshalling booking: %v", err)
 		return err
 	}
-
-	input := &dynamodb.PutItemInput{
-		TableName: aws.String("Bookings"),
-		Item:      item,
+	locatorItem := bookingLocatorKey(booking.Locator)
+	locatorItem["bookingID"] = &types.AttributeValueMemberS{Value: booking.BookingID}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           aws.String(bookingsTable),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(bookingID)"),
+			}},
+			{Put: &types.Put{
+				TableName:           aws.String(bookingLocatorsTable),
+				Item:                locatorItem,
+				ConditionExpression: aws.String("attribute_not_exists(locator)"),
+			}},
+		},
 	}
 
-	_, err = r.client.PutItem(context.Background(), input)
+	_, err = r.client.TransactWriteItems(context.Background(), input)
 	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) && len(canceled.CancellationReasons) == 2 {
+			if aws.ToString(canceled.CancellationReasons[1].Code) == "ConditionalCheckFailed" {
+				return db.ErrLocatorTaken
+			}
+			if aws.ToString(canceled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+				return models.Conflict("booking_exists", "booking already exists")
+			}
+		}
 		log.Printf("Error inserting booking: %v", err)
 		return err
 	}
@@ -91,28 +179,54 @@ func (r *BookingRepo) CreateBooking(booking *models.Booking) error {
 	return nil
 }
 
-// UpdateBookingStatus updates the status of a booking by ID
-func (r *BookingRepo) UpdateBookingStatus(id string, status string) error {
-	if id == "" || status == "" {
+// UpdateBookingStatus applies a status change, provided the booking is still in
+// the status the change starts from, and appends it to the booking's history
+func (r *BookingRepo) UpdateBookingStatus(id string, change models.BookingStatusChange) error {
+	if id == "" || change.To == "" {
 		return errors.New("id or status cannot be empty")
 	}
 
+	entry, err := attributevalue.Marshal(change)
+	if err != nil {
+		log.Printf("Error marshalling booking status change: %v", err)
+		return err
+	}
+	changedAt, err := attributevalue.Marshal(change.ChangedAt)
+	if err != nil {
+		log.Printf("Error marshalling booking status change: %v", err)
+		return err
+	}
+
+	condition := "bookingStatus = :from"
+	values := map[string]types.AttributeValue{
+		":to":     &types.AttributeValueMemberS{Value: string(change.To)},
+		":at":     changedAt,
+		":change": &types.AttributeValueMemberL{Value: []types.AttributeValue{entry}},
+		":empty":  &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		":zero":   &types.AttributeValueMemberN{Value: "0"},
+		":one":    &types.AttributeValueMemberN{Value: "1"},
+	}
+	if change.From == "" {
+		condition = "attribute_exists(bookingID) AND attribute_not_exists(bookingStatus)"
+	} else {
+		values[":from"] = &types.AttributeValueMemberS{Value: string(change.From)}
+	}
+
 	input := &dynamodb.UpdateItemInput{
-		TableName: aws.String("Bookings"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
-		UpdateExpression: aws.String("SET #status = :status"),
-		ExpressionAttributeNames: map[string]string{
-			"#status": "Status",
-		},
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":status": &types.AttributeValueMemberS{Value: status},
-		},
+		TableName:                 aws.String(bookingsTable),
+		Key:                       bookingKey(id),
+		UpdateExpression:          aws.String("SET bookingStatus = :to, updatedAt = :at, statusHistory = list_append(if_not_exists(statusHistory, :empty), :change), #version = if_not_exists(#version, :zero) + :one"),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeNames:  map[string]string{"#version": "version"},
+		ExpressionAttributeValues: values,
 	}
 
-	_, err := r.client.UpdateItem(context.Background(), input)
+	_, err = r.client.UpdateItem(context.Background(), input)
 	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return db.ErrBookingStatusChanged
+		}
 		log.Printf("Error updating booking status: %v", err)
 		return err
 	}
@@ -120,96 +234,203 @@ func (r *BookingRepo) UpdateBookingStatus(id string, status string) error {
 	return nil
 }
 
-// GetBookingsByUserID retrieves bookings for a specific user
-func (r *BookingRepo) GetBookingsByUserID(userID string) ([]models.Booking, error) {
-	if userID == "" {
-		return nil, errors.New("userID cannot be empty")
+// UpdateBookingSegments replaces the segments of a booking, keeping the list of
+// its flights used to find a flight's bookings in step, provided the booking is
+// still at version
+func (r *BookingRepo) UpdateBookingSegments(id string, segments []models.BookingSegment, version int64) error {
+	if id == "" {
+		return errors.New("invalid booking ID")
 	}
 
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String("Bookings"),
-		IndexName:              aws.String("UserID-index"), // Assuming you have a secondary index on UserID
-		KeyConditionExpression: aws.String("UserID = :userID"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":userID": &types.AttributeValueMemberS{Value: userID},
-		},
+	list, err := attributevalue.Marshal(segments)
+	if err != nil {
+		log.Printf("Error marshalling booking segments: %v", err)
+		return err
 	}
-
-	result, err := r.client.Query(context.Background(), input)
+	flightIDs, err := attributevalue.Marshal(models.Booking{Segments: segments}.FlightIDs())
 	if err != nil {
-		log.Printf("Error fetching bookings for userID %s: %v", userID, err)
-		return nil, err
+		log.Printf("Error marshalling booking segments: %v", err)
+		return err
+	}
+	updatedAt, err := attributevalue.Marshal(time.Now().UTC())
+	if err != nil {
+		log.Printf("Error marshalling booking segments: %v", err)
+		return err
 	}
 
-	var bookings []models.Booking
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &bookings)
+	condition, names, values := versionCondition("bookingID", version)
+	if values == nil {
+		values = make(map[string]types.AttributeValue)
+	}
+	values[":segments"] = list
+	values[":flightIDs"] = flightIDs
+	values[":at"] = updatedAt
+	values[":zero"] = &types.AttributeValueMemberN{Value: "0"}
+	values[":one"] = &types.AttributeValueMemberN{Value: "1"}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                           aws.String(bookingsTable),
+		Key:                                 bookingKey(id),
+		UpdateExpression:                    aws.String("SET segments = :segments, flightIDs = :flightIDs, updatedAt = :at, #version = if_not_exists(#version, :zero) + :one"),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+
+	_, err = r.client.UpdateItem(context.Background(), input)
+	err = versionError(err)
+	if errors.Is(err, errItemNotFound) {
+		return models.NotFound("booking")
+	}
 	if err != nil {
-		log.Printf("Error unmarshalling bookings: %v", err)
-		return nil, err
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error updating segments of booking %s: %v", id, err)
+		}
+		return err
 	}
 
-	return bookings, nil
+	return nil
 }
 
-// DeleteBooking deletes a booking by ID
-func (r *BookingRepo) DeleteBooking(id string) error {
+// DeleteBooking deletes a booking by ID, provided it is still at version, and
+// frees its locator
+func (r *BookingRepo) DeleteBooking(id string, version int64) error {
 	if id == "" {
 		return errors.New("invalid booking ID")
 	}
 
-	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String("Bookings"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
+	deleted, err := deleteVersioned(r.client, bookingsTable, "bookingID", bookingKey(id), version)
+	if errors.Is(err, errItemNotFound) {
+		return models.NotFound("booking")
+	}
+	if err != nil {
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error deleting booking: %v", err)
+		}
+		return err
 	}
 
-	_, err := r.client.DeleteItem(context.Background(), input)
+	// Free the booking's locator, guarding against one since reused by another booking
+	locator, ok := deleted["locator"].(*types.AttributeValueMemberS)
+	if !ok || locator.Value == "" {
+		return nil
+	}
+	_, err = r.client.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName:           aws.String(bookingLocatorsTable),
+		Key:                 bookingLocatorKey(locator.Value),
+		ConditionExpression: aws.String("bookingID = :bookingID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":bookingID": &types.AttributeValueMemberS{Value: id},
+		},
+	})
 	if err != nil {
-		log.Printf("Error deleting booking: %v", err)
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil
+		}
+		log.Printf("Error deleting locator of booking %s: %v", id, err)
 		return err
 	}
 
 	return nil
 }
 
-// UpdateBooking updates an existing booking by ID
+// PatchBooking writes the named fields of a booking, provided it is still at
+// booking.Version, and bumps the version. Attribute names match the JSON field names.
+func (r *BookingRepo) PatchBooking(id string, booking *models.Booking, fields []string) (*models.Booking, error) {
+	if id == "" || booking == nil {
+		return nil, errors.New("invalid booking ID or booking details")
+	}
+
+	updated := *booking
+	updated.BookingID = id
+	updated.Version++
+	item, err := bookingItem(&updated)
+	if err != nil {
+		log.Printf("Error marshalling patched booking: %v", err)
+		return nil, err
+	}
+
+	for _, field := range fields {
+		if field == "userID" {
+			// Keep the index copy of the owner in step
+			fields = append(fields[:len(fields):len(fields)], "UserID")
+			break
+		}
+	}
+
+	err = patchVersioned(r.client, bookingsTable, "bookingID", bookingKey(id), item, fields, booking.Version)
+	if errors.Is(err, errItemNotFound) {
+		return nil, models.NotFound("booking")
+	}
+	if err != nil {
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error patching booking %s: %v", id, err)
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// UpdateBooking replaces a booking, provided it is still at booking.Version,
+// and bumps the version
 func (r *BookingRepo) UpdateBooking(id string, booking *models.Booking) (*models.Booking, error) {
 	if id == "" || booking == nil {
 		return nil, errors.New("invalid booking ID or booking details")
 	}
 
-	// Marshall the updated booking details into a DynamoDB item
-	_, err := attributevalue.MarshalMap(booking)
+	updated := *booking
+	updated.BookingID = id
+	updated.Version++
+	item, err := bookingItem(&updated)
 	if err != nil {
 		log.Printf("Error marshalling updated booking: %v", err)
 		return nil, err
 	}
 
-	input := &dynamodb.UpdateItemInput{
-		TableName: aws.String("Bookings"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
-		UpdateExpression: aws.String("SET #status = :status, #userID = :userID, #otherAttributes = :otherAttributes"),
-		ExpressionAttributeNames: map[string]string{
-			"#status":          "Status",
-			"#userID":          "UserID",
-			"#otherAttributes": "OtherAttributes", // Add other fields you want to update
-		},
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			// ":status":          &types.AttributeValueMemberS{Value: booking.Status},
-			":userID": &types.AttributeValueMemberS{Value: booking.UserID},
-			// ":otherAttributes": &types.AttributeValueMemberM{Value: booking.OtherAttributes}, // Add other fields here
-		},
+	err = putVersioned(r.client, bookingsTable, "bookingID", item, booking.Version)
+	if errors.Is(err, errItemNotFound) {
+		return nil, models.NotFound("booking")
 	}
-
-	_, err = r.client.UpdateItem(context.Background(), input)
 	if err != nil {
-		log.Printf("Error updating booking: %v", err)
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error updating booking: %v", err)
+		}
 		return nil, err
 	}
 
-	// Return the updated booking
-	return booking, nil
+	return &updated, nil
+}
+
+func bookingKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"bookingID": &types.AttributeValueMemberS{Value: id},
+	}
+}
+
+func bookingLocatorKey(locator string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"locator": &types.AttributeValueMemberS{Value: locator},
+	}
+}
+
+// bookingItem marshals a booking together with flightIDs, the flights of its
+// segments, which GetFlightBookings filters on, and UserID, the partition key
+// of the index a user's bookings are read through
+func bookingItem(booking *models.Booking) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(booking)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != "" {
+		item["UserID"] = &types.AttributeValueMemberS{Value: booking.UserID}
+	}
+	flightIDs, err := attributevalue.Marshal(booking.FlightIDs())
+	if err != nil {
+		return nil, err
+	}
+	item["flightIDs"] = flightIDs
+	return item, nil
 }
@@ -42,8 +42,11 @@ func NewDynamoDBClient() *dynamodb.Client {
 	// Log: AWS configuration loaded successfully
 	log.Println("AWS configuration loaded successfully.")
 
-	// Create DynamoDB client from the loaded config
-	client := dynamodb.NewFromConfig(cfg)
+	// Create DynamoDB client from the loaded config, reporting outages as
+	// unavailable rather than as internal errors
+	client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, unavailableErrors)
+	})
 
 	// Log: Attempting to test DynamoDB connection
 	log.Println("Testing DynamoDB connection...")
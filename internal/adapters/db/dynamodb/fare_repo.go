@@ -0,0 +1,105 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	faresTable         = "Fares"
+	faresFlightIDIndex = "flightID-index" // GSI with flightID as partition key
+)
+
+type FareRepo struct {
+	client *dynamodb.Client
+}
+
+func NewFareRepo(client *dynamodb.Client) *FareRepo {
+	return &FareRepo{client: client}
+}
+
+// GetFaresByFlightID retrieves the fares sold on a flight
+func (r *FareRepo) GetFaresByFlightID(flightID string) ([]models.Fare, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(faresTable),
+		IndexName:              aws.String(faresFlightIDIndex),
+		KeyConditionExpression: aws.String("flightID = :flightID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":flightID": &types.AttributeValueMemberS{Value: flightID},
+		},
+	}
+
+	var fares []models.Fare
+	if err := queryAll(r.client, input, &fares); err != nil {
+		log.Printf("Error fetching fares for flight %s: %v", flightID, err)
+		return nil, err
+	}
+
+	return fares, nil
+}
+
+// GetFareByID retrieves a fare by its ID
+func (r *FareRepo) GetFareByID(fareID string) (*models.Fare, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(faresTable),
+		Key: map[string]types.AttributeValue{
+			"fareID": &types.AttributeValueMemberS{Value: fareID},
+		},
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching fare %s: %v", fareID, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var fare models.Fare
+	err = attributevalue.UnmarshalMap(result.Item, &fare)
+	if err != nil {
+		log.Printf("Error unmarshalling fare: %v", err)
+		return nil, err
+	}
+
+	return &fare, nil
+}
+
+// CreateFare stores a new fare, refusing to overwrite an existing one
+func (r *FareRepo) CreateFare(fare *models.Fare) error {
+	if fare == nil {
+		return errors.New("fare details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(fare)
+	if err != nil {
+		log.Printf("Error marshalling fare: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(faresTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(fareID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("fare_exists", "fare already exists")
+		}
+		log.Printf("Error inserting fare: %v", err)
+		return err
+	}
+
+	return nil
+}
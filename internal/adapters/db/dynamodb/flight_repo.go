@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"log"
+	"strings"
 	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -12,6 +14,16 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+const (
+	flightsTable = "Flights"
+	// flightsRouteDateIndex is a GSI with routeDate (ORIGIN#DESTINATION#YYYY-MM-DD)
+	// as partition key and departureTime as sort key
+	flightsRouteDateIndex = "routeDate-departureTime-index"
+	// flightsOriginDateIndex is a GSI with originDate (ORIGIN#YYYY-MM-DD) as
+	// partition key and departureTime as sort key
+	flightsOriginDateIndex = "originDate-departureTime-index"
+)
+
 type FlightRepo struct {
 	client *dynamodb.Client
 }
@@ -20,35 +32,46 @@ func NewFlightRepo(client *dynamodb.Client) *FlightRepo {
 	return &FlightRepo{client: client}
 }
 
-// GetAllFlights retrieves all flights from the DynamoDB table
-func (r *FlightRepo) GetAllFlights() ([]models.Flight, error) {
-	input := &dynamodb.ScanInput{
-		TableName: aws.String("Flights"),
+// ListFlights returns one page of the flights matching query, in the table's
+// scan order. Sorting would mean reading every matching flight first, so
+// sort orders are refused.
+func (r *FlightRepo) ListFlights(query models.FlightQuery) (*models.FlightPage, error) {
+	if err := checkUnsorted(query.Page); err != nil {
+		return nil, err
 	}
 
-	result, err := r.client.Scan(context.Background(), input)
-	if err != nil {
-		log.Printf("Error fetching flights: %v", err)
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(flightsTable),
+	}
+	var filter scanFilter
+	for attribute, value := range map[string]string{
+		"airline":     query.Airline,
+		"origin":      query.Origin,
+		"destination": query.Destination,
+	} {
+		if value != "" {
+			filter.add(attribute, "=", &types.AttributeValueMemberS{Value: value})
+		}
+	}
+	if err := filter.dateRange("departureTime", query.From, query.To); err != nil {
 		return nil, err
 	}
+	filter.apply(input)
 
-	var flights []models.Flight
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &flights)
+	flights := []models.Flight{}
+	next, err := scanPage(r.client, input, query.Page, &flights)
 	if err != nil {
-		log.Printf("Error unmarshalling flights: %v", err)
+		log.Printf("Error fetching flights: %v", err)
 		return nil, err
 	}
-
-	return flights, nil
+	return &models.FlightPage{Items: flights, NextCursor: next}, nil
 }
 
 // GetFlightByID retrieves a flight by its ID
 func (r *FlightRepo) GetFlightByID(id string) (*models.Flight, error) {
 	input := &dynamodb.GetItemInput{
-		TableName: aws.String("Flights"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
+		TableName: aws.String(flightsTable),
+		Key:       flightKey(id),
 	}
 
 	result, err := r.client.GetItem(context.Background(), input)
@@ -56,6 +79,9 @@ func (r *FlightRepo) GetFlightByID(id string) (*models.Flight, error) {
 		log.Printf("Error fetching flight: %v", err)
 		return nil, err
 	}
+	if result.Item == nil {
+		return nil, nil
+	}
 
 	var flight models.Flight
 	err = attributevalue.UnmarshalMap(result.Item, &flight)
@@ -67,26 +93,33 @@ func (r *FlightRepo) GetFlightByID(id string) (*models.Flight, error) {
 	return &flight, nil
 }
 
-// CreateFlight creates a new flight record in DynamoDB
+// CreateFlight creates a new flight record in DynamoDB, refusing to
+// overwrite an existing one
 func (r *FlightRepo) CreateFlight(flight *models.Flight) error {
 	if flight == nil {
 		return errors.New("flight details cannot be nil")
 	}
 
 	// Marshal the flight struct into a map to store in DynamoDB
-	item, err := attributevalue.MarshalMap(flight)
+	flight.Version = 1
+	item, err := flightItem(flight)
 	if err != nil {
 		log.Printf("Error marshalling flight: %v", err)
 		return err
 	}
 
 	input := &dynamodb.PutItemInput{
-		TableName: aws.String("Flights"),
-		Item:      item,
+		TableName:           aws.String(flightsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(flightID)"),
 	}
 
 	_, err = r.client.PutItem(context.Background(), input)
 	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("flight_exists", "flight already exists")
+		}
 		log.Printf("Error creating flight: %v", err)
 		return err
 	}
@@ -94,98 +127,187 @@ func (r *FlightRepo) CreateFlight(flight *models.Flight) error {
 	return nil
 }
 
-// UpdateFlight updates an existing flight by ID
+// SearchFlights retrieves the flights from origin to destination departing on
+// date through the route-date index rather than scanning the table
+func (r *FlightRepo) SearchFlights(origin, destination, date string) ([]models.Flight, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(flightsTable),
+		IndexName:              aws.String(flightsRouteDateIndex),
+		KeyConditionExpression: aws.String("routeDate = :routeDate"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":routeDate": &types.AttributeValueMemberS{Value: routeDate(origin, destination, date)},
+		},
+	}
+
+	var flights []models.Flight
+	if err := queryAll(r.client, input, &flights); err != nil {
+		log.Printf("Error searching flights %s-%s on %s: %v", origin, destination, date, err)
+		return nil, err
+	}
+
+	return flights, nil
+}
+
+// GetFlightsByOrigin retrieves the flights leaving origin on date through the
+// origin-date index
+func (r *FlightRepo) GetFlightsByOrigin(origin, date string) ([]models.Flight, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(flightsTable),
+		IndexName:              aws.String(flightsOriginDateIndex),
+		KeyConditionExpression: aws.String("originDate = :originDate"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":originDate": &types.AttributeValueMemberS{Value: originDate(origin, date)},
+		},
+	}
+
+	var flights []models.Flight
+	if err := queryAll(r.client, input, &flights); err != nil {
+		log.Printf("Error fetching flights from %s on %s: %v", origin, date, err)
+		return nil, err
+	}
+
+	return flights, nil
+}
+
+// UpdateFlight replaces a flight, provided it is still at flight.Version, and
+// bumps the version. The search index keys are rewritten with the flight.
 func (r *FlightRepo) UpdateFlight(id string, flight *models.Flight) (*models.Flight, error) {
 	if id == "" || flight == nil {
 		return nil, errors.New("invalid flight ID or flight details")
 	}
 
-	// Marshal the updated flight details into a DynamoDB item
-	_, err := attributevalue.MarshalMap(flight)
+	updated := *flight
+	updated.FlightID = id
+	updated.Version++
+	item, err := flightItem(&updated)
 	if err != nil {
 		log.Printf("Error marshalling updated flight: %v", err)
 		return nil, err
 	}
 
-	input := &dynamodb.UpdateItemInput{
-		TableName: aws.String("Flights"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
-		UpdateExpression: aws.String("SET #flightName = :flightName, #destination = :destination, #otherAttributes = :otherAttributes"),
-		ExpressionAttributeNames: map[string]string{
-			"#flightName":      "FlightName",
-			"#destination":     "Destination",
-			"#otherAttributes": "OtherAttributes", // Add other fields to update
-		},
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			// ":flightName":      &types.AttributeValueMemberS{Value: flight.FlightName},
-			":destination": &types.AttributeValueMemberS{Value: flight.Destination},
-			// ":otherAttributes": &types.AttributeValueMemberM{Value: flight.OtherAttributes}, // Add other fields here
-		},
+	err = putVersioned(r.client, flightsTable, "flightID", item, flight.Version)
+	if errors.Is(err, errItemNotFound) {
+		return nil, models.NotFound("flight")
+	}
+	if err != nil {
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error updating flight: %v", err)
+		}
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// PatchFlight writes the named fields of a flight, provided it is still at
+// flight.Version, and bumps the version. Attribute names match the JSON field
+// names, and the search index keys are rewritten when the route or departure changes.
+func (r *FlightRepo) PatchFlight(id string, flight *models.Flight, fields []string) (*models.Flight, error) {
+	if id == "" || flight == nil {
+		return nil, errors.New("invalid flight ID or flight details")
+	}
+
+	updated := *flight
+	updated.FlightID = id
+	updated.Version++
+	item, err := flightItem(&updated)
+	if err != nil {
+		log.Printf("Error marshalling patched flight: %v", err)
+		return nil, err
 	}
 
-	_, err = r.client.UpdateItem(context.Background(), input)
+	names := fields
+	for _, field := range fields {
+		if field == "origin" || field == "destination" || field == "departureTime" {
+			names = append(names, "routeDate", "originDate")
+			break
+		}
+	}
+
+	err = patchVersioned(r.client, flightsTable, "flightID", flightKey(id), item, names, flight.Version)
+	if errors.Is(err, errItemNotFound) {
+		return nil, models.NotFound("flight")
+	}
 	if err != nil {
-		log.Printf("Error updating flight: %v", err)
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error patching flight %s: %v", id, err)
+		}
 		return nil, err
 	}
 
-	// Return the updated flight
-	return flight, nil
+	return &updated, nil
 }
 
-// DeleteFlight deletes a flight by ID
-func (r *FlightRepo) DeleteFlight(id string) error {
+// DeleteFlight deletes a flight by ID, provided it is still at version
+func (r *FlightRepo) DeleteFlight(id string, version int64) error {
 	if id == "" {
 		return errors.New("invalid flight ID")
 	}
 
-	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String("Flights"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
+	_, err := deleteVersioned(r.client, flightsTable, "flightID", flightKey(id), version)
+	if errors.Is(err, errItemNotFound) {
+		return models.NotFound("flight")
 	}
-
-	_, err := r.client.DeleteItem(context.Background(), input)
 	if err != nil {
-		log.Printf("Error deleting flight: %v", err)
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error deleting flight: %v", err)
+		}
 		return err
 	}
 
 	return nil
 }
 
-// GetFlightBookings retrieves all bookings for a given flight ID.
+// GetFlightBookings retrieves all bookings with a leg on the given flight.
+// Bookings are keyed by booking ID alone, so this scans the table filtering on
+// the flightIDs list kept on every booking.
 func (r *FlightRepo) GetFlightBookings(flightID string) ([]models.Booking, error) {
 	if flightID == "" {
 		return nil, errors.New("flight ID cannot be empty")
 	}
 
-	// Define the DynamoDB query input to fetch bookings for the specified flight ID
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String("Bookings"), // Assuming there's a 'Bookings' table
-		KeyConditionExpression: aws.String("FlightID = :flightID"),
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(bookingsTable),
+		FilterExpression: aws.String("contains(flightIDs, :flightID)"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":flightID": &types.AttributeValueMemberS{Value: flightID},
 		},
 	}
 
-	// Execute the query
-	result, err := r.client.Query(context.Background(), input)
-	if err != nil {
+	var bookings []models.Booking
+	if err := scanAll(r.client, input, &bookings); err != nil {
 		log.Printf("Error fetching bookings for flight %s: %v", flightID, err)
 		return nil, err
 	}
 
-	// Unmarshal the result into a list of bookings
-	var bookings []models.Booking
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &bookings)
+	return bookings, nil
+}
+
+func flightKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"flightID": &types.AttributeValueMemberS{Value: id},
+	}
+}
+
+// flightItem marshals a flight together with the keys of the search indexes
+func flightItem(flight *models.Flight) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(flight)
 	if err != nil {
-		log.Printf("Error unmarshalling bookings for flight %s: %v", flightID, err)
 		return nil, err
 	}
+	item["routeDate"] = &types.AttributeValueMemberS{
+		Value: routeDate(flight.Origin, flight.Destination, flight.DepartureDate()),
+	}
+	item["originDate"] = &types.AttributeValueMemberS{
+		Value: originDate(flight.Origin, flight.DepartureDate()),
+	}
+	return item, nil
+}
 
-	return bookings, nil
+func routeDate(origin, destination, date string) string {
+	return strings.ToUpper(origin) + "#" + strings.ToUpper(destination) + "#" + date
+}
+
+func originDate(origin, date string) string {
+	return strings.ToUpper(origin) + "#" + date
 }
@@ -0,0 +1,35 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"travel-backend/internal/core/domain/models"
+)
+
+func TestCreateFlightRefusesDuplicates(t *testing.T) {
+	server := newTestServer(t, "test_")
+	repo := NewFlightRepo(ForTenant(server.Client(), "acme"))
+
+	departure := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	flight := func(airline string) *models.Flight {
+		return &models.Flight{
+			FlightID: "f1", Airline: airline, Origin: "DEL", Destination: "BOM",
+			DepartureTime: departure, ArrivalTime: departure.Add(2 * time.Hour), AircraftType: "A320",
+		}
+	}
+	if err := repo.CreateFlight(flight("AI")); err != nil {
+		t.Fatalf("CreateFlight() error = %v", err)
+	}
+
+	err := repo.CreateFlight(flight("6E"))
+	var domainErr *models.Error
+	if !errors.As(err, &domainErr) || domainErr.Kind != models.KindConflict || domainErr.Code != "flight_exists" {
+		t.Fatalf("CreateFlight() of an existing flight error = %v, want the flight_exists conflict", err)
+	}
+
+	got, err := repo.GetFlightByID("f1")
+	if err != nil || got == nil || got.Airline != "AI" {
+		t.Fatalf("GetFlightByID() = %+v, %v, want the original AI flight", got, err)
+	}
+}
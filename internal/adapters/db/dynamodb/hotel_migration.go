@@ -0,0 +1,212 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// legacyHotel is the shape of Hotels rows written before properties, room
+// types and reservations were split apart: one row per hotel carrying a
+// single reservation.
+type legacyHotel struct {
+	HotelID           string         `dynamodbav:"hotelID"`
+	BookingID         string         `dynamodbav:"bookingID"`
+	UserID            string         `dynamodbav:"userID"`
+	CheckInDate       time.Time      `dynamodbav:"checkInDate"`
+	CheckOutDate      time.Time      `dynamodbav:"checkOutDate"`
+	IsCheckinFlexible bool           `dynamodbav:"isCheckinFlexible"`
+	BookingStatus     string         `dynamodbav:"bookingStatus"`
+	CreatedAt         time.Time      `dynamodbav:"createdAt"`
+	UpdatedAt         time.Time      `dynamodbav:"updatedAt"`
+	PaymentStatus     string         `dynamodbav:"paymentStatus"`
+	RoomType          string         `dynamodbav:"roomType"`
+	NumberOfGuests    int            `dynamodbav:"numberOfGuests"`
+	SpecialRequests   string         `dynamodbav:"specialRequests"`
+	Assets            []models.Asset `dynamodbav:"assets"`
+}
+
+// HotelMigrationResult summarises a MigrateLegacyHotels run
+type HotelMigrationResult struct {
+	Scanned  int
+	Migrated int
+	Skipped  int
+	Failed   int
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// MigrateLegacyHotels splits every legacy Hotels row into a Property, a
+// RoomType and a HotelReservation. Legacy rows predate tenants, so they are
+// read through source, a client that is not limited to a tenant, and the
+// items they are split into are written through target, a client returned by
+// ForTenant. Each row is migrated in its own transaction that only succeeds
+// while the tenant has no hotel of that ID yet, so the migration can safely be
+// re-run. Legacy rows are left in place; no tenant can read them. With dryRun
+// set the rows are converted and counted but nothing is written.
+//
+// Legacy rows carry no room count, so migrated room types have TotalRooms of
+// zero and must be filled in before they can be sold.
+func MigrateLegacyHotels(source, target *dynamodb.Client, dryRun bool) (HotelMigrationResult, error) {
+	var result HotelMigrationResult
+
+	paginator := dynamodb.NewScanPaginator(source, &dynamodb.ScanInput{
+		TableName: aws.String(hotelsTable),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			log.Printf("Error scanning hotels for migration: %v", err)
+			return result, err
+		}
+
+		for _, item := range page.Items {
+			result.Scanned++
+			if !isLegacyHotel(item) {
+				result.Skipped++
+				continue
+			}
+
+			var legacy legacyHotel
+			if err := attributevalue.UnmarshalMap(item, &legacy); err != nil {
+				log.Printf("Error unmarshalling legacy hotel: %v", err)
+				result.Failed++
+				continue
+			}
+
+			property, roomType, reservation := splitLegacyHotel(legacy)
+			if dryRun {
+				log.Printf("Would migrate hotel %s (reservation for booking %s)", legacy.HotelID, legacy.BookingID)
+				result.Migrated++
+				continue
+			}
+			err := writeMigratedHotel(target, property, roomType, reservation)
+			if errors.Is(err, errHotelExists) {
+				result.Skipped++
+				continue
+			}
+			if err != nil {
+				log.Printf("Error migrating hotel %s: %v", legacy.HotelID, err)
+				result.Failed++
+				continue
+			}
+			result.Migrated++
+		}
+	}
+
+	return result, nil
+}
+
+// errHotelExists is returned by writeMigratedHotel when the tenant already has
+// a hotel with the legacy row's ID, most likely from an earlier run
+var errHotelExists = errors.New("hotel already exists")
+
+// isLegacyHotel reports whether a Hotels row still carries reservation data
+func isLegacyHotel(item map[string]types.AttributeValue) bool {
+	_, hasBooking := item["bookingID"]
+	_, hasCheckIn := item["checkInDate"]
+	return hasBooking || hasCheckIn
+}
+
+func splitLegacyHotel(legacy legacyHotel) (models.Property, *models.RoomType, models.HotelReservation) {
+	now := time.Now().UTC()
+
+	property := models.Property{
+		HotelID:   legacy.HotelID,
+		Name:      legacy.HotelID, // legacy rows never stored a name
+		Assets:    legacy.Assets,
+		CreatedAt: legacy.CreatedAt,
+		UpdatedAt: now,
+	}
+
+	var roomType *models.RoomType
+	if legacy.RoomType != "" {
+		roomType = &models.RoomType{
+			RoomTypeID: legacy.HotelID + "-" + strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(legacy.RoomType), "-"), "-"),
+			HotelID:    legacy.HotelID,
+			Name:       legacy.RoomType,
+			MaxGuests:  legacy.NumberOfGuests,
+		}
+	}
+
+	status := models.ReservationConfirmed
+	switch strings.ToUpper(legacy.BookingStatus) {
+	case "CANCELLED", "CANCELED":
+		status = models.ReservationCancelled
+	}
+
+	reservation := models.HotelReservation{
+		ReservationID:     utils.NewID(),
+		HotelID:           legacy.HotelID,
+		BookingID:         legacy.BookingID,
+		UserID:            legacy.UserID,
+		CheckInDate:       legacy.CheckInDate,
+		CheckOutDate:      legacy.CheckOutDate,
+		IsCheckinFlexible: legacy.IsCheckinFlexible,
+		NumberOfGuests:    legacy.NumberOfGuests,
+		Status:            status,
+		PaymentStatus:     legacy.PaymentStatus,
+		SpecialRequests:   legacy.SpecialRequests,
+		CreatedAt:         legacy.CreatedAt,
+		UpdatedAt:         now,
+	}
+	if roomType != nil {
+		reservation.RoomTypeID = roomType.RoomTypeID
+	}
+
+	return property, roomType, reservation
+}
+
+func writeMigratedHotel(client *dynamodb.Client, property models.Property, roomType *models.RoomType, reservation models.HotelReservation) error {
+	propertyItem, err := hotelItem(&property)
+	if err != nil {
+		return err
+	}
+	reservationItem, err := attributevalue.MarshalMap(reservation)
+	if err != nil {
+		return err
+	}
+
+	items := []types.TransactWriteItem{
+		{Put: &types.Put{
+			TableName:           aws.String(hotelsTable),
+			Item:                propertyItem,
+			ConditionExpression: aws.String("attribute_not_exists(hotelID)"),
+		}},
+		{Put: &types.Put{
+			TableName:           aws.String(hotelReservationsTable),
+			Item:                reservationItem,
+			ConditionExpression: aws.String("attribute_not_exists(reservationID)"),
+		}},
+	}
+	if roomType != nil {
+		roomTypeItem, err := attributevalue.MarshalMap(roomType)
+		if err != nil {
+			return err
+		}
+		items = append(items, types.TransactWriteItem{Put: &types.Put{
+			TableName: aws.String(roomTypesTable),
+			Item:      roomTypeItem,
+		}})
+	}
+
+	_, err = client.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	var canceled *types.TransactionCanceledException
+	if errors.As(err, &canceled) && len(canceled.CancellationReasons) > 0 &&
+		aws.ToString(canceled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+		return errHotelExists
+	}
+	return err
+}
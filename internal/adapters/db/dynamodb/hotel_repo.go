@@ -2,8 +2,13 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
 	"log"
+	"sort"
+	"strings"
 	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/geohash"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -11,6 +16,17 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+const (
+	hotelsTable = "Hotels"
+	// hotelsCityIndex is a GSI with cityKey (the lower-cased city name) as partition key
+	hotelsCityIndex = "cityKey-index"
+	// hotelsGeohashIndex is a GSI with geohashPrefix (the first
+	// geohashPrefixLength characters of the location's geohash) as partition
+	// key and the full geohash as sort key
+	hotelsGeohashIndex  = "geohashPrefix-geohash-index"
+	geohashPrefixLength = 3
+)
+
 type HotelRepo struct {
 	client *dynamodb.Client
 }
@@ -19,34 +35,42 @@ func NewHotelRepo(client *dynamodb.Client) *HotelRepo {
 	return &HotelRepo{client: client}
 }
 
-func (r *HotelRepo) GetAllHotels() ([]models.Hotel, error) {
+// ListHotels returns one page of the hotels matching query, in the table's
+// scan order. Sorting would mean reading every matching hotel first, so
+// sort orders are refused.
+func (r *HotelRepo) ListHotels(query models.HotelQuery) (*models.HotelPage, error) {
+	if err := checkUnsorted(query.Page); err != nil {
+		return nil, err
+	}
 
 	input := &dynamodb.ScanInput{
-		TableName: aws.String("Hotels"),
+		TableName: aws.String(hotelsTable),
 	}
-
-	result, err := r.client.Scan(context.Background(), input)
-	if err != nil {
-		log.Printf("Error fetching hotels: %v", err)
-		return nil, err
+	var filter scanFilter
+	if query.City != "" {
+		filter.add("cityKey", "=", &types.AttributeValueMemberS{Value: cityKey(query.City)})
+	}
+	if query.Country != "" {
+		filter.add("address.country", "=", &types.AttributeValueMemberS{Value: query.Country})
+	}
+	if query.MinStars > 0 {
+		filter.add("starRating", ">=", numberValue(query.MinStars))
 	}
+	filter.apply(input)
 
-	var hotels []models.Hotel
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &hotels)
+	hotels := []models.Property{}
+	next, err := scanPage(r.client, input, query.Page, &hotels)
 	if err != nil {
-		log.Printf("Error unmarshalling hotels: %v", err)
+		log.Printf("Error fetching hotels: %v", err)
 		return nil, err
 	}
-
-	return hotels, nil
+	return &models.HotelPage{Items: hotels, NextCursor: next}, nil
 }
 
-func (r *HotelRepo) GetHotelByID(id string) (*models.Hotel, error) {
+func (r *HotelRepo) GetHotelByID(id string) (*models.Property, error) {
 	input := &dynamodb.GetItemInput{
-		TableName: aws.String("Hotels"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
+		TableName: aws.String(hotelsTable),
+		Key:       hotelKey(id),
 	}
 
 	result, err := r.client.GetItem(context.Background(), input)
@@ -54,8 +78,11 @@ func (r *HotelRepo) GetHotelByID(id string) (*models.Hotel, error) {
 		log.Printf("Error fetching hotel: %v", err)
 		return nil, err
 	}
+	if result.Item == nil {
+		return nil, nil
+	}
 
-	var hotel models.Hotel
+	var hotel models.Property
 	err = attributevalue.UnmarshalMap(result.Item, &hotel)
 	if err != nil {
 		log.Printf("Error unmarshalling hotel: %v", err)
@@ -65,99 +92,216 @@ func (r *HotelRepo) GetHotelByID(id string) (*models.Hotel, error) {
 	return &hotel, nil
 }
 
-func (r *HotelRepo) CreateHotel(hotel *models.Hotel) error {
-	av, err := attributevalue.MarshalMap(hotel)
+// GetHotelsByCity queries the city index for the hotels in a city
+func (r *HotelRepo) GetHotelsByCity(city string) ([]models.Property, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(hotelsTable),
+		IndexName:              aws.String(hotelsCityIndex),
+		KeyConditionExpression: aws.String("cityKey = :cityKey"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":cityKey": &types.AttributeValueMemberS{Value: cityKey(city)},
+		},
+	}
+
+	var hotels []models.Property
+	if err := queryAll(r.client, input, &hotels); err != nil {
+		log.Printf("Error fetching hotels in %s: %v", city, err)
+		return nil, err
+	}
+
+	return hotels, nil
+}
+
+// GetHotelsNear returns the hotels within radiusKm of a point, nearest first.
+// The geohash cells covering the circle are read from the geohash index, one
+// query per cell, and the hotels outside the radius are dropped.
+func (r *HotelRepo) GetHotelsNear(center models.GeoPoint, radiusKm float64) ([]models.NearbyHotel, error) {
+	var inputs []*dynamodb.QueryInput
+	for _, cell := range geohash.Cover(center.Latitude, center.Longitude, radiusKm, geohash.MaxPrecision) {
+		if len(cell) >= geohashPrefixLength {
+			inputs = append(inputs, geohashQuery(cell[:geohashPrefixLength], cell))
+			continue
+		}
+		// Cells wider than a partition are read one partition at a time
+		for _, prefix := range geohash.Children(cell, geohashPrefixLength) {
+			inputs = append(inputs, geohashQuery(prefix, ""))
+		}
+	}
+
+	seen := make(map[string]bool)
+	var hotels []models.NearbyHotel
+	for _, input := range inputs {
+		var found []models.Property
+		if err := queryAll(r.client, input, &found); err != nil {
+			log.Printf("Error fetching hotels near %f,%f: %v", center.Latitude, center.Longitude, err)
+			return nil, err
+		}
+		for _, hotel := range found {
+			if seen[hotel.HotelID] {
+				continue
+			}
+			seen[hotel.HotelID] = true
+			if distance := center.DistanceKm(hotel.Location); distance <= radiusKm {
+				hotels = append(hotels, models.NearbyHotel{Property: hotel, DistanceKm: distance})
+			}
+		}
+	}
+
+	sort.SliceStable(hotels, func(i, j int) bool {
+		return hotels[i].DistanceKm < hotels[j].DistanceKm
+	})
+	return hotels, nil
+}
+
+func (r *HotelRepo) CreateHotel(hotel *models.Property) error {
+	hotel.Version = 1
+	av, err := hotelItem(hotel)
 	if err != nil {
 		log.Printf("Error marshalling hotel: %v", err)
 		return err
 	}
 
 	input := &dynamodb.PutItemInput{
-		TableName: aws.String("Hotels"),
-		Item:      av,
+		TableName:           aws.String(hotelsTable),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(hotelID)"),
 	}
 
 	_, err = r.client.PutItem(context.Background(), input)
-	return err
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("hotel_exists", "hotel already exists")
+		}
+		log.Printf("Error inserting hotel: %v", err)
+		return err
+	}
+
+	return nil
 }
 
-func (r *HotelRepo) GetHotelBookings(hotelID string) ([]models.Booking, error) {
-	// Create a query input to fetch bookings by hotelID
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String("Bookings"),
-		KeyConditionExpression: aws.String("HotelID = :hotelID"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":hotelID": &types.AttributeValueMemberS{Value: hotelID},
-		},
-	}
+// UpdateHotel replaces a hotel, provided it is still at hotel.Version, and bumps the version
+func (r *HotelRepo) UpdateHotel(id string, hotel *models.Property) (*models.Property, error) {
+	updated := *hotel
+	updated.HotelID = id
+	updated.Version++
 
-	result, err := r.client.Query(context.Background(), input)
+	// Marshal the updated hotel details
+	item, err := hotelItem(&updated)
 	if err != nil {
-		log.Printf("Error fetching bookings for hotel %s: %v", hotelID, err)
+		log.Printf("Error marshalling updated hotel: %v", err)
 		return nil, err
 	}
 
-	var bookings []models.Booking
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &bookings)
+	// Replace the whole property so that no legacy reservation attributes survive
+	err = putVersioned(r.client, hotelsTable, "hotelID", item, hotel.Version)
+	if errors.Is(err, errItemNotFound) {
+		return nil, models.NotFound("hotel")
+	}
 	if err != nil {
-		log.Printf("Error unmarshalling bookings: %v", err)
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error updating hotel %s: %v", id, err)
+		}
 		return nil, err
 	}
 
-	return bookings, nil
+	return &updated, nil
 }
 
-func (r *HotelRepo) UpdateHotel(id string, hotel *models.Hotel) (*models.Hotel, error) {
-	// Marshal the updated hotel details
-	updatedValues, err := attributevalue.MarshalMap(hotel)
+// PatchHotel writes the named fields of a hotel, provided it is still at
+// hotel.Version, and bumps the version. Attribute names match the JSON field
+// names, and the city and geohash index keys follow the address and location.
+func (r *HotelRepo) PatchHotel(id string, hotel *models.Property, fields []string) (*models.Property, error) {
+	updated := *hotel
+	updated.HotelID = id
+	updated.Version++
+	item, err := hotelItem(&updated)
 	if err != nil {
-		log.Printf("Error marshalling updated hotel: %v", err)
+		log.Printf("Error marshalling patched hotel: %v", err)
 		return nil, err
 	}
 
-	// Prepare the update input
-	input := &dynamodb.UpdateItemInput{
-		TableName: aws.String("Hotels"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
-		AttributeUpdates: map[string]types.AttributeValueUpdate{},
-	}
-
-	// Add updated attributes to the input
-	for key, value := range updatedValues {
-		input.AttributeUpdates[key] = types.AttributeValueUpdate{
-			Value:  value,
-			Action: types.AttributeActionPut,
+	names := fields
+	for _, field := range fields {
+		switch field {
+		case "address":
+			names = append(names, "cityKey")
+		case "location":
+			names = append(names, "geohash", "geohashPrefix")
 		}
 	}
 
-	// Perform the update
-	_, err = r.client.UpdateItem(context.Background(), input)
+	err = patchVersioned(r.client, hotelsTable, "hotelID", hotelKey(id), item, names, hotel.Version)
+	if errors.Is(err, errItemNotFound) {
+		return nil, models.NotFound("hotel")
+	}
 	if err != nil {
-		log.Printf("Error updating hotel %s: %v", id, err)
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error patching hotel %s: %v", id, err)
+		}
 		return nil, err
 	}
 
-	// Return the updated hotel (you can also fetch it again if needed)
-	return hotel, nil
+	return &updated, nil
 }
 
-func (r *HotelRepo) DeleteHotel(id string) error {
-	// Prepare the delete input
-	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String("Hotels"),
-		Key: map[string]types.AttributeValue{
-			"ID": &types.AttributeValueMemberS{Value: id},
-		},
+// DeleteHotel deletes a hotel, provided it is still at version
+func (r *HotelRepo) DeleteHotel(id string, version int64) error {
+	_, err := deleteVersioned(r.client, hotelsTable, "hotelID", hotelKey(id), version)
+	if errors.Is(err, errItemNotFound) {
+		return models.NotFound("hotel")
 	}
-
-	// Perform the deletion
-	_, err := r.client.DeleteItem(context.Background(), input)
 	if err != nil {
-		log.Printf("Error deleting hotel %s: %v", id, err)
+		if !errors.Is(err, db.ErrVersionConflict) {
+			log.Printf("Error deleting hotel %s: %v", id, err)
+		}
 		return err
 	}
 
 	return nil
 }
+
+func hotelKey(id string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"hotelID": &types.AttributeValueMemberS{Value: id},
+	}
+}
+
+// hotelItem marshals a hotel together with the keys of the search indexes
+func hotelItem(hotel *models.Property) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(hotel)
+	if err != nil {
+		return nil, err
+	}
+	if key := cityKey(hotel.Address.City); key != "" {
+		item["cityKey"] = &types.AttributeValueMemberS{Value: key}
+	}
+	if !hotel.Location.IsZero() {
+		hash := geohash.Encode(hotel.Location.Latitude, hotel.Location.Longitude, geohash.MaxPrecision)
+		item["geohash"] = &types.AttributeValueMemberS{Value: hash}
+		item["geohashPrefix"] = &types.AttributeValueMemberS{Value: hash[:geohashPrefixLength]}
+	}
+	return item, nil
+}
+
+// geohashQuery reads one partition of the geohash index, narrowed to the
+// hashes starting with cell when set
+func geohashQuery(prefix, cell string) *dynamodb.QueryInput {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(hotelsTable),
+		IndexName:              aws.String(hotelsGeohashIndex),
+		KeyConditionExpression: aws.String("geohashPrefix = :prefix"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefix": &types.AttributeValueMemberS{Value: prefix},
+		},
+	}
+	if cell != "" {
+		input.KeyConditionExpression = aws.String("geohashPrefix = :prefix AND begins_with(geohash, :cell)")
+		input.ExpressionAttributeValues[":cell"] = &types.AttributeValueMemberS{Value: cell}
+	}
+	return input
+}
+
+func cityKey(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
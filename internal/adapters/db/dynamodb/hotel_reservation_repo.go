@@ -0,0 +1,152 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	hotelReservationsTable          = "HotelReservations"
+	hotelReservationsHotelIDIndex   = "hotelID-index"   // GSI with hotelID as partition key
+	hotelReservationsBookingIDIndex = "bookingID-index" // GSI with bookingID as partition key
+)
+
+type HotelReservationRepo struct {
+	client *dynamodb.Client
+}
+
+func NewHotelReservationRepo(client *dynamodb.Client) *HotelReservationRepo {
+	return &HotelReservationRepo{client: client}
+}
+
+// GetReservationsByHotelID retrieves the reservations made at a property
+func (r *HotelReservationRepo) GetReservationsByHotelID(hotelID string) ([]models.HotelReservation, error) {
+	return r.queryReservations(hotelReservationsHotelIDIndex, "hotelID", hotelID)
+}
+
+// GetReservationsByBookingID retrieves the hotel reservations sold as part of a booking
+func (r *HotelReservationRepo) GetReservationsByBookingID(bookingID string) ([]models.HotelReservation, error) {
+	return r.queryReservations(hotelReservationsBookingIDIndex, "bookingID", bookingID)
+}
+
+// GetReservationByID retrieves a reservation by its ID
+func (r *HotelReservationRepo) GetReservationByID(reservationID string) (*models.HotelReservation, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(hotelReservationsTable),
+		Key:       reservationKey(reservationID),
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching reservation %s: %v", reservationID, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var reservation models.HotelReservation
+	err = attributevalue.UnmarshalMap(result.Item, &reservation)
+	if err != nil {
+		log.Printf("Error unmarshalling reservation: %v", err)
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// CreateReservation stores a new reservation, refusing to overwrite an existing one
+func (r *HotelReservationRepo) CreateReservation(reservation *models.HotelReservation) error {
+	if reservation == nil {
+		return errors.New("reservation details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(reservation)
+	if err != nil {
+		log.Printf("Error marshalling reservation: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(hotelReservationsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(reservationID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("reservation_exists", "reservation already exists")
+		}
+		log.Printf("Error inserting reservation: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateReservation replaces an existing reservation
+func (r *HotelReservationRepo) UpdateReservation(reservationID string, reservation *models.HotelReservation) (*models.HotelReservation, error) {
+	if reservationID == "" || reservation == nil {
+		return nil, errors.New("invalid reservation ID or reservation details")
+	}
+
+	updated := *reservation
+	updated.ReservationID = reservationID
+	item, err := attributevalue.MarshalMap(updated)
+	if err != nil {
+		log.Printf("Error marshalling updated reservation: %v", err)
+		return nil, err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(hotelReservationsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(reservationID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, models.NotFound("reservation")
+		}
+		log.Printf("Error updating reservation %s: %v", reservationID, err)
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (r *HotelReservationRepo) queryReservations(index, attribute, value string) ([]models.HotelReservation, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(hotelReservationsTable),
+		IndexName:              aws.String(index),
+		KeyConditionExpression: aws.String(attribute + " = :value"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":value": &types.AttributeValueMemberS{Value: value},
+		},
+	}
+
+	var reservations []models.HotelReservation
+	if err := queryAll(r.client, input, &reservations); err != nil {
+		log.Printf("Error fetching reservations by %s %s: %v", attribute, value, err)
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+func reservationKey(reservationID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"reservationID": &types.AttributeValueMemberS{Value: reservationID},
+	}
+}
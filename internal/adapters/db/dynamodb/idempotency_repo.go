@@ -0,0 +1,151 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// idempotencyKeysTable has idempotencyKey as partition key and a TTL on
+// expiresAt, so DynamoDB eventually removes expired keys by itself
+const idempotencyKeysTable = "IdempotencyKeys"
+
+type IdempotencyRepo struct {
+	client *dynamodb.Client
+}
+
+func NewIdempotencyRepo(client *dynamodb.Client) *IdempotencyRepo {
+	return &IdempotencyRepo{client: client}
+}
+
+// GetIdempotencyRecord retrieves the unexpired record for a key. TTL deletion
+// lags behind expiry, so expired items are filtered out here.
+func (r *IdempotencyRepo) GetIdempotencyRecord(key string) (*models.IdempotencyRecord, error) {
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(idempotencyKeysTable),
+		Key:            idempotencyKey(key),
+		ConsistentRead: aws.Bool(true),
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching idempotency key: %v", err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var record models.IdempotencyRecord
+	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
+		log.Printf("Error unmarshalling idempotency record: %v", err)
+		return nil, err
+	}
+	if !record.IsActive(time.Now()) {
+		return nil, nil
+	}
+
+	return &record, nil
+}
+
+// CreateIdempotencyRecord claims a key, failing with db.ErrIdempotencyKeyInUse
+// while an unexpired record exists for it that cannot be taken over. The
+// condition mirrors models.IdempotencyRecord's CanTakeOver.
+func (r *IdempotencyRepo) CreateIdempotencyRecord(record *models.IdempotencyRecord) error {
+	if record == nil {
+		return errors.New("idempotency record cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		log.Printf("Error marshalling idempotency record: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(idempotencyKeysTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(idempotencyKey) OR expiresAt <= :now OR (completed = :false AND lockedUntil <= :now AND fingerprint = :fingerprint)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now":         unixTime(time.Now()),
+			":false":       &types.AttributeValueMemberBOOL{Value: false},
+			":fingerprint": &types.AttributeValueMemberS{Value: record.Fingerprint},
+		},
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return db.ErrIdempotencyKeyInUse
+		}
+		log.Printf("Error inserting idempotency record: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// CompleteIdempotencyRecord stores the response sent for a claimed key
+func (r *IdempotencyRepo) CompleteIdempotencyRecord(record *models.IdempotencyRecord) error {
+	if record == nil {
+		return errors.New("idempotency record cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		log.Printf("Error marshalling idempotency record: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(idempotencyKeysTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(idempotencyKey) AND fingerprint = :fingerprint"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":fingerprint": &types.AttributeValueMemberS{Value: record.Fingerprint},
+		},
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.NotFound("idempotency record")
+		}
+		log.Printf("Error completing idempotency record: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyRecord forgets a key so that it can be used again
+func (r *IdempotencyRepo) DeleteIdempotencyRecord(key string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(idempotencyKeysTable),
+		Key:       idempotencyKey(key),
+	}
+
+	_, err := r.client.DeleteItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error deleting idempotency key: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func idempotencyKey(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"idempotencyKey": &types.AttributeValueMemberS{Value: key},
+	}
+}
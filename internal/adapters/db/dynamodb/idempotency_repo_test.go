@@ -0,0 +1,49 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+func TestCreateIdempotencyRecordTakesOverStaleLocks(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name        string
+		existing    models.IdempotencyRecord
+		fingerprint string
+		wantErr     error
+	}{
+		{name: "locked", existing: models.IdempotencyRecord{Fingerprint: "f", LockedUntil: now.Add(time.Minute)}, fingerprint: "f", wantErr: db.ErrIdempotencyKeyInUse},
+		{name: "lock lapsed", existing: models.IdempotencyRecord{Fingerprint: "f", LockedUntil: now.Add(-time.Minute)}, fingerprint: "f"},
+		{name: "lock lapsed for another request", existing: models.IdempotencyRecord{Fingerprint: "g", LockedUntil: now.Add(-time.Minute)}, fingerprint: "f", wantErr: db.ErrIdempotencyKeyInUse},
+		{name: "completed", existing: models.IdempotencyRecord{Fingerprint: "f", Completed: true, LockedUntil: now.Add(-time.Minute)}, fingerprint: "f", wantErr: db.ErrIdempotencyKeyInUse},
+		{name: "expired", existing: models.IdempotencyRecord{Fingerprint: "g", Completed: true, ExpiresAt: now.Add(-time.Minute)}, fingerprint: "f"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t, "test_")
+			repo := NewIdempotencyRepo(ForTenant(server.Client(), "acme"))
+
+			existing := test.existing
+			existing.Key = "u1/k1"
+			existing.CreatedAt = now.Add(-2 * time.Minute)
+			if existing.ExpiresAt.IsZero() {
+				existing.ExpiresAt = now.Add(time.Hour)
+			}
+			if err := repo.CreateIdempotencyRecord(&existing); err != nil {
+				t.Fatalf("CreateIdempotencyRecord() of the existing record error = %v", err)
+			}
+
+			err := repo.CreateIdempotencyRecord(&models.IdempotencyRecord{
+				Key: "u1/k1", Fingerprint: test.fingerprint, CreatedAt: now,
+				LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour),
+			})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("CreateIdempotencyRecord() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
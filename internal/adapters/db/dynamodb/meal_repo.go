@@ -0,0 +1,213 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const mealsTable = "Meals"
+
+type MealRepo struct {
+	client *dynamodb.Client
+}
+
+func NewMealRepo(client *dynamodb.Client) *MealRepo {
+	return &MealRepo{client: client}
+}
+
+// GetAllMeals retrieves the whole meal catalogue
+func (r *MealRepo) GetAllMeals() ([]models.Meal, error) {
+	return r.scanMeals(&dynamodb.ScanInput{
+		TableName: aws.String(mealsTable),
+	})
+}
+
+// GetMealByID retrieves a catalogue meal by its ID
+func (r *MealRepo) GetMealByID(mealID string) (*models.Meal, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(mealsTable),
+		Key: map[string]types.AttributeValue{
+			"mealID": &types.AttributeValueMemberS{Value: mealID},
+		},
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching meal %s: %v", mealID, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var meal models.Meal
+	err = attributevalue.UnmarshalMap(upgradeLegacyMeal(result.Item), &meal)
+	if err != nil {
+		log.Printf("Error unmarshalling meal: %v", err)
+		return nil, err
+	}
+
+	return &meal, nil
+}
+
+// GetMealsByClass retrieves the meals served in a cabin class
+func (r *MealRepo) GetMealsByClass(class string) ([]models.Meal, error) {
+	meals, err := r.scanMeals(&dynamodb.ScanInput{
+		TableName:        aws.String(mealsTable),
+		FilterExpression: aws.String("contains(availableClasses, :class)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":class": &types.AttributeValueMemberS{Value: class},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// contains() does substring matching on legacy string rows, so re-check
+	filtered := meals[:0]
+	for _, meal := range meals {
+		if meal.AvailableClasses.Contains(class) {
+			filtered = append(filtered, meal)
+		}
+	}
+	return filtered, nil
+}
+
+// AddMeal adds a meal to the catalogue, refusing to overwrite an existing one
+func (r *MealRepo) AddMeal(meal *models.Meal) error {
+	if meal == nil {
+		return errors.New("meal details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(meal)
+	if err != nil {
+		log.Printf("Error marshalling meal: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(mealsTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(mealID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("meal_exists", "meal already exists")
+		}
+		log.Printf("Error inserting meal: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetPassengerMeals retrieves the meals chosen by passengers on a booking
+func (r *MealRepo) GetPassengerMeals(bookingID string) ([]models.MealSelection, error) {
+	return queryMealSelections(r.client, bookingID)
+}
+
+// AssignMealToPassenger records the meal chosen by the passenger named on it
+func (r *MealRepo) AssignMealToPassenger(passengerMeal *models.MealSelection) error {
+	return putMealSelection(r.client, passengerMeal)
+}
+
+// scanMeals runs a scan over the catalogue, following LastEvaluatedKey across pages
+func (r *MealRepo) scanMeals(input *dynamodb.ScanInput) ([]models.Meal, error) {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			log.Printf("Error fetching meals: %v", err)
+			return nil, err
+		}
+		for _, item := range page.Items {
+			items = append(items, upgradeLegacyMeal(item))
+		}
+	}
+
+	var meals []models.Meal
+	err := attributevalue.UnmarshalListOfMaps(items, &meals)
+	if err != nil {
+		log.Printf("Error unmarshalling meals: %v", err)
+		return nil, err
+	}
+
+	return meals, nil
+}
+
+// upgradeLegacyMeal converts the old free-form availableClasses string, such as
+// "Economy, Business", into the list form the model now expects
+func upgradeLegacyMeal(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	legacy, ok := item["availableClasses"].(*types.AttributeValueMemberS)
+	if !ok {
+		return item
+	}
+
+	classes := &types.AttributeValueMemberL{}
+	for _, class := range strings.Split(legacy.Value, ",") {
+		class = strings.ToUpper(strings.Join(strings.Fields(class), "_"))
+		if class != "" {
+			classes.Value = append(classes.Value, &types.AttributeValueMemberS{Value: class})
+		}
+	}
+	item["availableClasses"] = classes
+	return item
+}
+
+// putMealSelection stores a passenger's meal choice, replacing any earlier one
+func putMealSelection(client *dynamodb.Client, passengerMeal *models.MealSelection) error {
+	if passengerMeal == nil {
+		return errors.New("meal details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(passengerMeal)
+	if err != nil {
+		log.Printf("Error marshalling passenger meal: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(passengerMealsTable),
+		Item:      item,
+	}
+
+	_, err = client.PutItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error assigning meal to passenger %s: %v", passengerMeal.PassengerID, err)
+		return err
+	}
+
+	return nil
+}
+
+// queryMealSelections retrieves the meal choices made on a booking
+func queryMealSelections(client *dynamodb.Client, bookingID string) ([]models.MealSelection, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(passengerMealsTable),
+		IndexName:              aws.String(passengerMealsBookingIndex),
+		KeyConditionExpression: aws.String("bookingID = :bookingID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":bookingID": &types.AttributeValueMemberS{Value: bookingID},
+		},
+	}
+
+	var meals []models.MealSelection
+	if err := queryAll(client, input, &meals); err != nil {
+		log.Printf("Error fetching meals for booking %s: %v", bookingID, err)
+		return nil, err
+	}
+
+	return meals, nil
+}
@@ -0,0 +1,207 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	passengersTable            = "Passengers"
+	passengersBookingIDIndex   = "bookingID-index" // GSI with bookingID as partition key
+	passengerMealsTable        = "PassengerMeals"  // one meal selection per passengerID
+	passengerMealsBookingIndex = "bookingID-index" // GSI with bookingID as partition key
+	seatsBookingIDIndex        = "bookingID-index" // sparse GSI on assigned seats
+)
+
+type PassengerRepo struct {
+	client *dynamodb.Client
+}
+
+func NewPassengerRepo(client *dynamodb.Client) *PassengerRepo {
+	return &PassengerRepo{client: client}
+}
+
+// GetAllPassengersByBookingID retrieves the passengers travelling on a booking
+func (r *PassengerRepo) GetAllPassengersByBookingID(bookingID string) ([]models.Passenger, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(passengersTable),
+		IndexName:              aws.String(passengersBookingIDIndex),
+		KeyConditionExpression: aws.String("bookingID = :bookingID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":bookingID": &types.AttributeValueMemberS{Value: bookingID},
+		},
+	}
+
+	var passengers []models.Passenger
+	if err := queryAll(r.client, input, &passengers); err != nil {
+		log.Printf("Error fetching passengers for booking %s: %v", bookingID, err)
+		return nil, err
+	}
+
+	return passengers, nil
+}
+
+// GetPassengerByID retrieves a passenger by ID
+func (r *PassengerRepo) GetPassengerByID(passengerID string) (*models.Passenger, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(passengersTable),
+		Key:       passengerKey(passengerID),
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching passenger %s: %v", passengerID, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var passenger models.Passenger
+	err = attributevalue.UnmarshalMap(result.Item, &passenger)
+	if err != nil {
+		log.Printf("Error unmarshalling passenger: %v", err)
+		return nil, err
+	}
+
+	return &passenger, nil
+}
+
+// AddPassenger stores a new passenger, refusing to overwrite an existing one
+func (r *PassengerRepo) AddPassenger(passenger *models.Passenger) error {
+	if passenger == nil {
+		return errors.New("passenger details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(passenger)
+	if err != nil {
+		log.Printf("Error marshalling passenger: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(passengersTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(passengerID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("passenger_exists", "passenger already exists")
+		}
+		log.Printf("Error inserting passenger: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePassenger replaces an existing passenger
+func (r *PassengerRepo) UpdatePassenger(passengerID string, passenger *models.Passenger) (*models.Passenger, error) {
+	if passengerID == "" || passenger == nil {
+		return nil, errors.New("invalid passenger ID or passenger details")
+	}
+
+	updated := *passenger
+	updated.PassengerID = passengerID
+	item, err := attributevalue.MarshalMap(updated)
+	if err != nil {
+		log.Printf("Error marshalling updated passenger: %v", err)
+		return nil, err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(passengersTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(passengerID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, models.NotFound("passenger")
+		}
+		log.Printf("Error updating passenger %s: %v", passengerID, err)
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeletePassenger removes a passenger together with their meal selection
+func (r *PassengerRepo) DeletePassenger(passengerID string) error {
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Delete: &types.Delete{
+				TableName: aws.String(passengersTable),
+				Key:       passengerKey(passengerID),
+			}},
+			{Delete: &types.Delete{
+				TableName: aws.String(passengerMealsTable),
+				Key:       passengerKey(passengerID),
+			}},
+		},
+	}
+
+	_, err := r.client.TransactWriteItems(context.Background(), input)
+	if err != nil {
+		log.Printf("Error deleting passenger %s: %v", passengerID, err)
+		return err
+	}
+
+	return nil
+}
+
+// AssignSeat atomically assigns the seat to the passenger named on it
+func (r *PassengerRepo) AssignSeat(passengerSeat *models.Seat) error {
+	if passengerSeat == nil {
+		return errors.New("seat details cannot be nil")
+	}
+	return assignSeat(r.client, passengerSeat.SeatID, passengerSeat.PassengerID, passengerSeat.BookingID)
+}
+
+// GetPassengerSeats retrieves the seats assigned to passengers on a booking
+func (r *PassengerRepo) GetPassengerSeats(bookingID string) ([]models.Seat, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(seatsTable),
+		IndexName:              aws.String(seatsBookingIDIndex),
+		KeyConditionExpression: aws.String("bookingID = :bookingID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":bookingID": &types.AttributeValueMemberS{Value: bookingID},
+		},
+	}
+
+	var seats []models.Seat
+	if err := queryAll(r.client, input, &seats); err != nil {
+		log.Printf("Error fetching seats for booking %s: %v", bookingID, err)
+		return nil, err
+	}
+
+	return seats, nil
+}
+
+// AssignMeal records the meal chosen by the passenger named on it
+func (r *PassengerRepo) AssignMeal(passengerMeal *models.MealSelection) error {
+	return putMealSelection(r.client, passengerMeal)
+}
+
+// GetPassengerMeals retrieves the meals chosen by passengers on a booking
+func (r *PassengerRepo) GetPassengerMeals(bookingID string) ([]models.MealSelection, error) {
+	return queryMealSelections(r.client, bookingID)
+}
+
+func passengerKey(passengerID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"passengerID": &types.AttributeValueMemberS{Value: passengerID},
+	}
+}
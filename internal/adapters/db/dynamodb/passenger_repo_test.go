@@ -0,0 +1,37 @@
+package dynamodb
+
+import (
+	"testing"
+	"time"
+	"travel-backend/internal/core/domain/models"
+)
+
+func TestDeletePassengerRemovesMealSelection(t *testing.T) {
+	server := newTestServer(t, "test_")
+	repo := NewPassengerRepo(ForTenant(server.Client(), "acme"))
+
+	for _, passengerID := range []string{"p1", "p2"} {
+		if err := repo.AddPassenger(&models.Passenger{PassengerID: passengerID, BookingID: "b1", Name: "Asha Rao"}); err != nil {
+			t.Fatalf("AddPassenger(%s) error = %v", passengerID, err)
+		}
+		meal := &models.MealSelection{PassengerID: passengerID, BookingID: "b1", MealID: "m1", Class: "ECONOMY", SelectedAt: time.Now().UTC()}
+		if err := repo.AssignMeal(meal); err != nil {
+			t.Fatalf("AssignMeal(%s) error = %v", passengerID, err)
+		}
+	}
+
+	if err := repo.DeletePassenger("p1"); err != nil {
+		t.Fatalf("DeletePassenger() error = %v", err)
+	}
+
+	meals, err := repo.GetPassengerMeals("b1")
+	if err != nil {
+		t.Fatalf("GetPassengerMeals() error = %v", err)
+	}
+	if len(meals) != 1 || meals[0].PassengerID != "p2" {
+		t.Errorf("GetPassengerMeals() = %+v, want only p2's selection", meals)
+	}
+	if items := server.Items("test_PassengerMeals"); len(items) != 1 {
+		t.Errorf("test_PassengerMeals holds %d items, want 1", len(items))
+	}
+}
@@ -0,0 +1,225 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/pagination"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// queryAll runs a query to completion, following LastEvaluatedKey across pages,
+// and unmarshals every returned item into out, which must point to a slice
+func queryAll(client *dynamodb.Client, input *dynamodb.QueryInput, out interface{}) error {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewQueryPaginator(client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return err
+		}
+		items = append(items, page.Items...)
+	}
+
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
+
+// scanAll scans a table to completion, following LastEvaluatedKey across pages,
+// and unmarshals every returned item into out, which must point to a slice
+func scanAll(client *dynamodb.Client, input *dynamodb.ScanInput, out interface{}) error {
+	var items []map[string]types.AttributeValue
+	paginator := dynamodb.NewScanPaginator(client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return err
+		}
+		items = append(items, page.Items...)
+	}
+
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
+
+// scanPage reads one page of a scan in the table's own order, resuming from
+// the LastEvaluatedKey carried by the page's cursor, and unmarshals the items
+// into out, which must point to a slice. It returns the cursor of the next
+// page, which is empty once the scan is exhausted.
+func scanPage(client *dynamodb.Client, input *dynamodb.ScanInput, page models.PageRequest, out interface{}) (string, error) {
+	startKey, err := pageStartKey(page)
+	if err != nil {
+		return "", err
+	}
+	input.ExclusiveStartKey = startKey
+
+	// Limit caps the items read rather than the items passing the filter, so
+	// keep scanning until the page is full or the table is exhausted
+	var items []map[string]types.AttributeValue
+	for {
+		input.Limit = aws.Int32(int32(page.Limit - len(items)))
+		result, err := client.Scan(context.Background(), input)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, result.Items...)
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+		if len(result.LastEvaluatedKey) == 0 || len(items) >= page.Limit {
+			break
+		}
+	}
+	if err := attributevalue.UnmarshalListOfMaps(items, out); err != nil {
+		return "", err
+	}
+	return nextPageCursor(input.ExclusiveStartKey)
+}
+
+// queryPage reads one page of a query in the order of its sort key, the same
+// way scanPage reads a page of a scan
+func queryPage(client *dynamodb.Client, input *dynamodb.QueryInput, page models.PageRequest, out interface{}) (string, error) {
+	startKey, err := pageStartKey(page)
+	if err != nil {
+		return "", err
+	}
+	input.ExclusiveStartKey = startKey
+
+	var items []map[string]types.AttributeValue
+	for {
+		input.Limit = aws.Int32(int32(page.Limit - len(items)))
+		result, err := client.Query(context.Background(), input)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, result.Items...)
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+		if len(result.LastEvaluatedKey) == 0 || len(items) >= page.Limit {
+			break
+		}
+	}
+	if err := attributevalue.UnmarshalListOfMaps(items, out); err != nil {
+		return "", err
+	}
+	return nextPageCursor(input.ExclusiveStartKey)
+}
+
+// checkUnsorted refuses a sort order for a list that can only be paged through
+// in the order of its table or index, rather than read whole and sorted in
+// memory
+func checkUnsorted(page models.PageRequest) error {
+	if page.Sort != "" {
+		return models.InvalidField("sort", "this list cannot be sorted")
+	}
+	return nil
+}
+
+// pageStartKey returns the LastEvaluatedKey carried by a page's cursor, if any
+func pageStartKey(page models.PageRequest) (map[string]types.AttributeValue, error) {
+	cursor, err := pagination.Decode(page.Cursor, page.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.ID != "" {
+		return nil, pagination.ErrInvalidCursor
+	}
+	if len(cursor.Key) == 0 {
+		return nil, nil
+	}
+	startKey := make(map[string]types.AttributeValue, len(cursor.Key))
+	for name, value := range cursor.Key {
+		startKey[name] = &types.AttributeValueMemberS{Value: value}
+	}
+	return startKey, nil
+}
+
+// nextPageCursor encodes the key a read stopped at as the cursor of the next
+// page, which is empty when there is none
+func nextPageCursor(lastKey map[string]types.AttributeValue) (string, error) {
+	if len(lastKey) == 0 {
+		return "", nil
+	}
+
+	key := make(map[string]string, len(lastKey))
+	for name, value := range lastKey {
+		s, ok := value.(*types.AttributeValueMemberS)
+		if !ok {
+			return "", fmt.Errorf("key attribute %s is not a string", name)
+		}
+		key[name] = s.Value
+	}
+	return pagination.Encode(pagination.Cursor{Key: key}), nil
+}
+
+// scanFilter builds the FilterExpression of a scan out of conditions that
+// must all hold
+type scanFilter struct {
+	conditions []string
+	names      map[string]string
+	values     map[string]types.AttributeValue
+}
+
+// add appends the condition "attribute op value", where attribute may be a
+// dotted path into a map attribute
+func (f *scanFilter) add(attribute, op string, value types.AttributeValue) {
+	if f.names == nil {
+		f.names = make(map[string]string)
+		f.values = make(map[string]types.AttributeValue)
+	}
+	var path []string
+	for _, part := range strings.Split(attribute, ".") {
+		f.names["#"+part] = part
+		path = append(path, "#"+part)
+	}
+	placeholder := fmt.Sprintf(":filter%d", len(f.values))
+	f.values[placeholder] = value
+	f.conditions = append(f.conditions, strings.Join(path, ".")+" "+op+" "+placeholder)
+}
+
+// apply sets the filter on a scan, if there are any conditions
+func (f *scanFilter) apply(input *dynamodb.ScanInput) {
+	if len(f.conditions) == 0 {
+		return
+	}
+	input.FilterExpression = aws.String(strings.Join(f.conditions, " AND "))
+	input.ExpressionAttributeNames = f.names
+	input.ExpressionAttributeValues = f.values
+}
+
+// applyQuery sets the filter on a query, next to the names and values its key
+// condition already uses, if there are any conditions
+func (f *scanFilter) applyQuery(input *dynamodb.QueryInput) {
+	if len(f.conditions) == 0 {
+		return
+	}
+	input.FilterExpression = aws.String(strings.Join(f.conditions, " AND "))
+	if input.ExpressionAttributeNames == nil {
+		input.ExpressionAttributeNames = make(map[string]string, len(f.names))
+	}
+	for name, attribute := range f.names {
+		input.ExpressionAttributeNames[name] = attribute
+	}
+	if input.ExpressionAttributeValues == nil {
+		input.ExpressionAttributeValues = make(map[string]types.AttributeValue, len(f.values))
+	}
+	for placeholder, value := range f.values {
+		input.ExpressionAttributeValues[placeholder] = value
+	}
+}
+
+// dateRange adds the conditions for a timestamp attribute stored as an RFC 3339
+// string to fall between the calendar days from and to, both inclusive
+func (f *scanFilter) dateRange(attribute, from, to string) error {
+	if from != "" {
+		f.add(attribute, ">=", &types.AttributeValueMemberS{Value: from})
+	}
+	if to != "" {
+		end, err := models.NextDay(to)
+		if err != nil {
+			return err
+		}
+		f.add(attribute, "<", &types.AttributeValueMemberS{Value: end})
+	}
+	return nil
+}
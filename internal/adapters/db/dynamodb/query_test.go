@@ -0,0 +1,34 @@
+package dynamodb
+
+import (
+	"errors"
+	"testing"
+	"travel-backend/internal/core/domain/models"
+)
+
+func TestListsRefuseSortOrders(t *testing.T) {
+	client := ForTenant(newTestServer(t, "test_").Client(), "acme")
+	page := models.PageRequest{Limit: 10, Sort: "-createdAt"}
+
+	lists := map[string]func() error{
+		"ListBookings": func() error {
+			_, err := NewBookingRepo(client).ListBookings(models.BookingQuery{UserID: "u1", Page: page})
+			return err
+		},
+		"ListFlights": func() error {
+			_, err := NewFlightRepo(client).ListFlights(models.FlightQuery{Page: page})
+			return err
+		},
+		"ListHotels": func() error {
+			_, err := NewHotelRepo(client).ListHotels(models.HotelQuery{Page: page})
+			return err
+		},
+	}
+	for name, list := range lists {
+		err := list()
+		var domainErr *models.Error
+		if !errors.As(err, &domainErr) || domainErr.Kind != models.KindValidation || len(domainErr.Fields) != 1 || domainErr.Fields[0].Field != "sort" {
+			t.Errorf("%s() with a sort order error = %v, want a validation error on sort", name, err)
+		}
+	}
+}
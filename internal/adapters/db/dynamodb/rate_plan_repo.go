@@ -0,0 +1,257 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// roomRatesTable has rateKey (ratePlanID#roomTypeID) as partition key and date
+// as sort key, so the rates of a stay are read with a single query
+const (
+	ratePlansTable           = "RatePlans"
+	ratePlansHotelIDIndex    = "hotelID-index" // GSI with hotelID as partition key
+	roomRatesTable           = "RoomRates"
+	roomRatesRatePlanIDIndex = "ratePlanID-index" // GSI with ratePlanID as partition key
+)
+
+type RatePlanRepo struct {
+	client *dynamodb.Client
+}
+
+func NewRatePlanRepo(client *dynamodb.Client) *RatePlanRepo {
+	return &RatePlanRepo{client: client}
+}
+
+// GetRatePlansByHotelID retrieves the rate plans of a hotel
+func (r *RatePlanRepo) GetRatePlansByHotelID(hotelID string) ([]models.RatePlan, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(ratePlansTable),
+		IndexName:              aws.String(ratePlansHotelIDIndex),
+		KeyConditionExpression: aws.String("hotelID = :hotelID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hotelID": &types.AttributeValueMemberS{Value: hotelID},
+		},
+	}
+
+	var ratePlans []models.RatePlan
+	if err := queryAll(r.client, input, &ratePlans); err != nil {
+		log.Printf("Error fetching rate plans for hotel %s: %v", hotelID, err)
+		return nil, err
+	}
+
+	return ratePlans, nil
+}
+
+// GetRatePlanByID retrieves a rate plan by its ID
+func (r *RatePlanRepo) GetRatePlanByID(ratePlanID string) (*models.RatePlan, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(ratePlansTable),
+		Key:       ratePlanKey(ratePlanID),
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching rate plan %s: %v", ratePlanID, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var ratePlan models.RatePlan
+	err = attributevalue.UnmarshalMap(result.Item, &ratePlan)
+	if err != nil {
+		log.Printf("Error unmarshalling rate plan: %v", err)
+		return nil, err
+	}
+
+	return &ratePlan, nil
+}
+
+// CreateRatePlan stores a new rate plan, refusing to overwrite an existing one
+func (r *RatePlanRepo) CreateRatePlan(ratePlan *models.RatePlan) error {
+	if ratePlan == nil {
+		return errors.New("rate plan details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(ratePlan)
+	if err != nil {
+		log.Printf("Error marshalling rate plan: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(ratePlansTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(ratePlanID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("rate_plan_exists", "rate plan already exists")
+		}
+		log.Printf("Error inserting rate plan: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateRatePlan replaces an existing rate plan
+func (r *RatePlanRepo) UpdateRatePlan(ratePlanID string, ratePlan *models.RatePlan) (*models.RatePlan, error) {
+	if ratePlanID == "" || ratePlan == nil {
+		return nil, errors.New("invalid rate plan ID or rate plan details")
+	}
+
+	updated := *ratePlan
+	updated.RatePlanID = ratePlanID
+	item, err := attributevalue.MarshalMap(updated)
+	if err != nil {
+		log.Printf("Error marshalling updated rate plan: %v", err)
+		return nil, err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(ratePlansTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(ratePlanID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, models.NotFound("rate plan")
+		}
+		log.Printf("Error updating rate plan %s: %v", ratePlanID, err)
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteRatePlan removes a rate plan together with its nightly rates
+func (r *RatePlanRepo) DeleteRatePlan(ratePlanID string) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(roomRatesTable),
+		IndexName:              aws.String(roomRatesRatePlanIDIndex),
+		KeyConditionExpression: aws.String("ratePlanID = :ratePlanID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ratePlanID": &types.AttributeValueMemberS{Value: ratePlanID},
+		},
+	}
+
+	var rates []models.RoomRate
+	if err := queryAll(r.client, input, &rates); err != nil {
+		log.Printf("Error fetching rates of rate plan %s: %v", ratePlanID, err)
+		return err
+	}
+
+	requests := make([]types.WriteRequest, 0, len(rates))
+	for _, rate := range rates {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{
+			Key: roomRateKey(rate.RatePlanID, rate.RoomTypeID, rate.Date),
+		}})
+	}
+	if err := batchWrite(r.client, roomRatesTable, requests); err != nil {
+		return err
+	}
+
+	_, err := r.client.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(ratePlansTable),
+		Key:       ratePlanKey(ratePlanID),
+	})
+	if err != nil {
+		log.Printf("Error deleting rate plan %s: %v", ratePlanID, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetRoomRates retrieves the stored rates of a room type under a rate plan among the given nights
+func (r *RatePlanRepo) GetRoomRates(ratePlanID, roomTypeID string, dates []string) ([]models.RoomRate, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	first, last := dates[0], dates[0]
+	wanted := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		wanted[date] = true
+		if date < first {
+			first = date
+		}
+		if date > last {
+			last = date
+		}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(roomRatesTable),
+		KeyConditionExpression: aws.String("rateKey = :key AND #date BETWEEN :first AND :last"),
+		ExpressionAttributeNames: map[string]string{
+			"#date": "date",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":key":   &types.AttributeValueMemberS{Value: rateKey(ratePlanID, roomTypeID)},
+			":first": &types.AttributeValueMemberS{Value: first},
+			":last":  &types.AttributeValueMemberS{Value: last},
+		},
+	}
+
+	var stored []models.RoomRate
+	if err := queryAll(r.client, input, &stored); err != nil {
+		log.Printf("Error fetching rates for %s/%s: %v", ratePlanID, roomTypeID, err)
+		return nil, err
+	}
+
+	rates := stored[:0]
+	for _, rate := range stored {
+		if wanted[rate.Date] {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+// SetRoomRates stores nightly rates in batches, replacing any already set for the same nights
+func (r *RatePlanRepo) SetRoomRates(rates []models.RoomRate) error {
+	requests := make([]types.WriteRequest, 0, len(rates))
+	for _, rate := range rates {
+		item, err := attributevalue.MarshalMap(rate)
+		if err != nil {
+			log.Printf("Error marshalling room rate: %v", err)
+			return err
+		}
+		item["rateKey"] = &types.AttributeValueMemberS{Value: rateKey(rate.RatePlanID, rate.RoomTypeID)}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	return batchWrite(r.client, roomRatesTable, requests)
+}
+
+func ratePlanKey(ratePlanID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"ratePlanID": &types.AttributeValueMemberS{Value: ratePlanID},
+	}
+}
+
+func roomRateKey(ratePlanID, roomTypeID, date string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"rateKey": &types.AttributeValueMemberS{Value: rateKey(ratePlanID, roomTypeID)},
+		"date":    &types.AttributeValueMemberS{Value: date},
+	}
+}
+
+func rateKey(ratePlanID, roomTypeID string) string {
+	return ratePlanID + "#" + roomTypeID
+}
@@ -0,0 +1,254 @@
+package dynamodb
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// roomInventoryTable has inventoryKey (hotelID#roomTypeID) as partition key and
+// date as sort key. Besides total, sold and held each row keeps an available
+// counter so that sales can be guarded by a plain condition expression.
+const (
+	roomInventoryTable = "RoomInventory"
+	// maxTransactItems is the DynamoDB limit on items in one transaction
+	maxTransactItems = 100
+)
+
+var roomNightNames = map[string]string{
+	"#total":     "total",
+	"#sold":      "sold",
+	"#held":      "held",
+	"#available": "available",
+}
+
+type RoomInventoryRepo struct {
+	client *dynamodb.Client
+}
+
+func NewRoomInventoryRepo(client *dynamodb.Client) *RoomInventoryRepo {
+	return &RoomInventoryRepo{client: client}
+}
+
+// GetRoomNights retrieves the stored inventory rows among the given nights
+func (r *RoomInventoryRepo) GetRoomNights(hotelID, roomTypeID string, dates []string) ([]models.RoomNight, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	first, last := dates[0], dates[0]
+	wanted := make(map[string]bool, len(dates))
+	for _, date := range dates {
+		wanted[date] = true
+		if date < first {
+			first = date
+		}
+		if date > last {
+			last = date
+		}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(roomInventoryTable),
+		KeyConditionExpression: aws.String("inventoryKey = :key AND #date BETWEEN :first AND :last"),
+		ExpressionAttributeNames: map[string]string{
+			"#date": "date",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":key":   &types.AttributeValueMemberS{Value: inventoryKey(hotelID, roomTypeID)},
+			":first": &types.AttributeValueMemberS{Value: first},
+			":last":  &types.AttributeValueMemberS{Value: last},
+		},
+	}
+
+	var stored []models.RoomNight
+	if err := queryAll(r.client, input, &stored); err != nil {
+		log.Printf("Error fetching room inventory for %s/%s: %v", hotelID, roomTypeID, err)
+		return nil, err
+	}
+
+	nights := stored[:0]
+	for _, night := range stored {
+		if wanted[night.Date] {
+			nights = append(nights, night)
+		}
+	}
+	return nights, nil
+}
+
+// SetAllotment sets the number of rooms for sale on each of the given nights,
+// at most 100, in a single transaction. It fails with db.ErrAllotmentBelowSold,
+// or with db.ErrRoomInventoryChanged if a night was sold in the meantime,
+// without changing any night.
+func (r *RoomInventoryRepo) SetAllotment(hotelID, roomTypeID string, dates []string, total int) error {
+	if len(dates) > maxTransactItems {
+		return models.InvalidField("to", "an allotment can cover at most %d nights", maxTransactItems)
+	}
+
+	existing, err := r.GetRoomNights(hotelID, roomTypeID, dates)
+	if err != nil {
+		return err
+	}
+	stored := make(map[string]models.RoomNight, len(existing))
+	for _, night := range existing {
+		if total < night.Sold+night.Held {
+			return db.ErrAllotmentBelowSold
+		}
+		stored[night.Date] = night
+	}
+
+	var items []types.TransactWriteItem
+	for _, date := range dates {
+		night, ok := stored[date]
+		if !ok {
+			item, err := roomNightItem(models.RoomNight{HotelID: hotelID, RoomTypeID: roomTypeID, Date: date, Total: total})
+			if err != nil {
+				return err
+			}
+			items = append(items, types.TransactWriteItem{Put: &types.Put{
+				TableName:           aws.String(roomInventoryTable),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(inventoryKey)"),
+			}})
+			continue
+		}
+
+		items = append(items, types.TransactWriteItem{Update: &types.Update{
+			TableName:                aws.String(roomInventoryTable),
+			Key:                      roomNightKey(hotelID, roomTypeID, date),
+			UpdateExpression:         aws.String("SET #total = :total, #available = :available"),
+			ConditionExpression:      aws.String("#sold = :sold AND #held = :held"),
+			ExpressionAttributeNames: roomNightNames,
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":total":     numberValue(total),
+				":available": numberValue(total - night.Sold - night.Held),
+				":sold":      numberValue(night.Sold),
+				":held":      numberValue(night.Held),
+			},
+		}})
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+	_, err = r.client.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if err != nil {
+		if isTransactionConditionFailure(err) {
+			return db.ErrRoomInventoryChanged
+		}
+		log.Printf("Error setting allotment for %s/%s: %v", hotelID, roomTypeID, err)
+		return err
+	}
+	return nil
+}
+
+// ReserveRoomNights sells one room on every given night in a single
+// transaction, so either every night is sold or none is
+func (r *RoomInventoryRepo) ReserveRoomNights(hotelID, roomTypeID string, dates []string, defaultTotal int) error {
+	if len(dates) > maxTransactItems {
+		return models.Invalid("a stay can last at most %d nights", maxTransactItems)
+	}
+
+	// Nights without a row start from the room type's default allotment
+	condition := "#available >= :one"
+	if defaultTotal >= 1 {
+		condition = "attribute_not_exists(#available) OR #available >= :one"
+	}
+
+	items := make([]types.TransactWriteItem, 0, len(dates))
+	for _, date := range dates {
+		items = append(items, types.TransactWriteItem{Update: &types.Update{
+			TableName: aws.String(roomInventoryTable),
+			Key:       roomNightKey(hotelID, roomTypeID, date),
+			UpdateExpression: aws.String("SET hotelID = :hotelID, roomTypeID = :roomTypeID, " +
+				"#total = if_not_exists(#total, :default), #held = if_not_exists(#held, :zero), " +
+				"#sold = if_not_exists(#sold, :zero) + :one, #available = if_not_exists(#available, :default) - :one"),
+			ConditionExpression:      aws.String(condition),
+			ExpressionAttributeNames: roomNightNames,
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":hotelID":    &types.AttributeValueMemberS{Value: hotelID},
+				":roomTypeID": &types.AttributeValueMemberS{Value: roomTypeID},
+				":default":    numberValue(defaultTotal),
+				":zero":       numberValue(0),
+				":one":        numberValue(1),
+			},
+		}})
+	}
+
+	_, err := r.client.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if err != nil {
+		if isTransactionConditionFailure(err) {
+			return db.ErrRoomsSoldOut
+		}
+		log.Printf("Error reserving rooms for %s/%s: %v", hotelID, roomTypeID, err)
+		return err
+	}
+	return nil
+}
+
+// ReleaseRoomNights gives back one sold room on every given night
+func (r *RoomInventoryRepo) ReleaseRoomNights(hotelID, roomTypeID string, dates []string) error {
+	if len(dates) > maxTransactItems {
+		return models.Invalid("a stay can last at most %d nights", maxTransactItems)
+	}
+
+	items := make([]types.TransactWriteItem, 0, len(dates))
+	for _, date := range dates {
+		items = append(items, types.TransactWriteItem{Update: &types.Update{
+			TableName:                aws.String(roomInventoryTable),
+			Key:                      roomNightKey(hotelID, roomTypeID, date),
+			UpdateExpression:         aws.String("SET #sold = #sold - :one, #available = #available + :one"),
+			ConditionExpression:      aws.String("#sold >= :one"),
+			ExpressionAttributeNames: map[string]string{"#sold": "sold", "#available": "available"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":one": numberValue(1),
+			},
+		}})
+	}
+
+	_, err := r.client.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if err != nil {
+		log.Printf("Error releasing rooms for %s/%s: %v", hotelID, roomTypeID, err)
+		return err
+	}
+	return nil
+}
+
+// roomNightItem marshals a night together with its table key and available counter
+func roomNightItem(night models.RoomNight) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(night)
+	if err != nil {
+		return nil, err
+	}
+	item["inventoryKey"] = &types.AttributeValueMemberS{Value: inventoryKey(night.HotelID, night.RoomTypeID)}
+	item["available"] = numberValue(night.Total - night.Sold - night.Held)
+	return item, nil
+}
+
+func roomNightKey(hotelID, roomTypeID, date string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"inventoryKey": &types.AttributeValueMemberS{Value: inventoryKey(hotelID, roomTypeID)},
+		"date":         &types.AttributeValueMemberS{Value: date},
+	}
+}
+
+func inventoryKey(hotelID, roomTypeID string) string {
+	return hotelID + "#" + roomTypeID
+}
+
+func numberValue(n int) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(n)}
+}
@@ -0,0 +1,158 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	roomTypesTable        = "RoomTypes"
+	roomTypesHotelIDIndex = "hotelID-index" // GSI with hotelID as partition key
+)
+
+type RoomTypeRepo struct {
+	client *dynamodb.Client
+}
+
+func NewRoomTypeRepo(client *dynamodb.Client) *RoomTypeRepo {
+	return &RoomTypeRepo{client: client}
+}
+
+// GetRoomTypesByHotelID retrieves the room types of a property
+func (r *RoomTypeRepo) GetRoomTypesByHotelID(hotelID string) ([]models.RoomType, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(roomTypesTable),
+		IndexName:              aws.String(roomTypesHotelIDIndex),
+		KeyConditionExpression: aws.String("hotelID = :hotelID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":hotelID": &types.AttributeValueMemberS{Value: hotelID},
+		},
+	}
+
+	var roomTypes []models.RoomType
+	if err := queryAll(r.client, input, &roomTypes); err != nil {
+		log.Printf("Error fetching room types for hotel %s: %v", hotelID, err)
+		return nil, err
+	}
+
+	return roomTypes, nil
+}
+
+// GetRoomTypeByID retrieves a room type by its ID
+func (r *RoomTypeRepo) GetRoomTypeByID(roomTypeID string) (*models.RoomType, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(roomTypesTable),
+		Key:       roomTypeKey(roomTypeID),
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching room type %s: %v", roomTypeID, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var roomType models.RoomType
+	err = attributevalue.UnmarshalMap(result.Item, &roomType)
+	if err != nil {
+		log.Printf("Error unmarshalling room type: %v", err)
+		return nil, err
+	}
+
+	return &roomType, nil
+}
+
+// CreateRoomType stores a new room type, refusing to overwrite an existing one
+func (r *RoomTypeRepo) CreateRoomType(roomType *models.RoomType) error {
+	if roomType == nil {
+		return errors.New("room type details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(roomType)
+	if err != nil {
+		log.Printf("Error marshalling room type: %v", err)
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(roomTypesTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(roomTypeID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.Conflict("room_type_exists", "room type already exists")
+		}
+		log.Printf("Error inserting room type: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateRoomType replaces an existing room type
+func (r *RoomTypeRepo) UpdateRoomType(roomTypeID string, roomType *models.RoomType) (*models.RoomType, error) {
+	if roomTypeID == "" || roomType == nil {
+		return nil, errors.New("invalid room type ID or room type details")
+	}
+
+	updated := *roomType
+	updated.RoomTypeID = roomTypeID
+	item, err := attributevalue.MarshalMap(updated)
+	if err != nil {
+		log.Printf("Error marshalling updated room type: %v", err)
+		return nil, err
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(roomTypesTable),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_exists(roomTypeID)"),
+	}
+
+	_, err = r.client.PutItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return nil, models.NotFound("room type")
+		}
+		log.Printf("Error updating room type %s: %v", roomTypeID, err)
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteRoomType removes a room type
+func (r *RoomTypeRepo) DeleteRoomType(roomTypeID string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(roomTypesTable),
+		Key:       roomTypeKey(roomTypeID),
+	}
+
+	_, err := r.client.DeleteItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error deleting room type %s: %v", roomTypeID, err)
+		return err
+	}
+
+	return nil
+}
+
+func roomTypeKey(roomTypeID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"roomTypeID": &types.AttributeValueMemberS{Value: roomTypeID},
+	}
+}
@@ -0,0 +1,601 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	seatsTable           = "Seats"
+	seatsFlightIDIndex   = "flightID-index" // GSI with flightID as partition key
+	seatHoldsTable       = "SeatHolds"      // TTL enabled on the expiresAt attribute
+	seatHoldsFlightIndex = "flightID-index" // GSI with flightID as partition key
+	maxBatchWriteItems   = 25
+	maxBatchGetItems     = 100
+	maxBatchRetries      = 5
+)
+
+// flightInventoryTable holds the capacity and seatsSold counters of every flight, by flightID
+const flightInventoryTable = "FlightInventory"
+
+type SeatRepo struct {
+	client *dynamodb.Client
+}
+
+func NewSeatRepo(client *dynamodb.Client) *SeatRepo {
+	return &SeatRepo{client: client}
+}
+
+// CreateSeats writes a flight's seat inventory in batches
+func (r *SeatRepo) CreateSeats(seats []models.Seat) error {
+	requests := make([]types.WriteRequest, 0, len(seats))
+	for _, seat := range seats {
+		item, err := attributevalue.MarshalMap(seat)
+		if err != nil {
+			log.Printf("Error marshalling seat %s: %v", seat.SeatID, err)
+			return err
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+
+	if err := batchWrite(r.client, seatsTable, requests); err != nil {
+		return err
+	}
+
+	// Count the new seats into their flights' capacity, so that searches
+	// read it with the seats sold instead of counting seats
+	capacity := make(map[string]int)
+	for _, seat := range seats {
+		capacity[seat.FlightID]++
+	}
+	for flightID, count := range capacity {
+		_, err := r.client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+			TableName:        aws.String(flightInventoryTable),
+			Key:              flightInventoryKey(flightID),
+			UpdateExpression: aws.String("ADD capacity :count"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":count": &types.AttributeValueMemberN{Value: strconv.Itoa(count)},
+			},
+		})
+		if err != nil {
+			log.Printf("Error recording capacity of flight %s: %v", flightID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSeatByID retrieves a seat by its ID
+func (r *SeatRepo) GetSeatByID(seatID string) (*models.Seat, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(seatsTable),
+		Key:       seatKey(seatID),
+	}
+
+	result, err := r.client.GetItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error fetching seat %s: %v", seatID, err)
+		return nil, err
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var seat models.Seat
+	err = attributevalue.UnmarshalMap(result.Item, &seat)
+	if err != nil {
+		log.Printf("Error unmarshalling seat: %v", err)
+		return nil, err
+	}
+
+	return &seat, nil
+}
+
+// GetSeatsByFlightID retrieves every seat of a flight through the flightID index
+func (r *SeatRepo) GetSeatsByFlightID(flightID string) ([]models.Seat, error) {
+	return r.querySeats(&dynamodb.QueryInput{
+		TableName:              aws.String(seatsTable),
+		IndexName:              aws.String(seatsFlightIDIndex),
+		KeyConditionExpression: aws.String("flightID = :flightID"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":flightID": &types.AttributeValueMemberS{Value: flightID},
+		},
+	})
+}
+
+// UpdateSeatAvailability sets the availability flag of a seat, clearing its
+// assignment when the seat is made available again
+func (r *SeatRepo) UpdateSeatAvailability(seatID string, isAvailable bool) error {
+	updateExpression := "SET isAvailable = :isAvailable"
+	if isAvailable {
+		updateExpression += " REMOVE passengerID, bookingID"
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(seatsTable),
+		Key:                 seatKey(seatID),
+		UpdateExpression:    aws.String(updateExpression),
+		ConditionExpression: aws.String("attribute_exists(seatID)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":isAvailable": &types.AttributeValueMemberBOOL{Value: isAvailable},
+		},
+	}
+
+	_, err := r.client.UpdateItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return models.NotFound("seat")
+		}
+		log.Printf("Error updating seat %s availability: %v", seatID, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetAvailableSeatsByFlightID retrieves the seats of a flight that are neither
+// assigned nor under an active hold. TTL deletion lags behind expiry, so holds
+// are filtered on expiresAt rather than trusted to have disappeared.
+func (r *SeatRepo) GetAvailableSeatsByFlightID(flightID string) ([]models.Seat, error) {
+	seats, err := r.querySeats(&dynamodb.QueryInput{
+		TableName:              aws.String(seatsTable),
+		IndexName:              aws.String(seatsFlightIDIndex),
+		KeyConditionExpression: aws.String("flightID = :flightID"),
+		FilterExpression:       aws.String("isAvailable = :available"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":flightID":  &types.AttributeValueMemberS{Value: flightID},
+			":available": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	holds, err := r.queryActiveHolds(flightID)
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return seats, nil
+	}
+
+	held := make(map[string]bool, len(holds))
+	for _, hold := range holds {
+		held[hold.SeatID] = true
+	}
+	available := make([]models.Seat, 0, len(seats))
+	for _, seat := range seats {
+		if !held[seat.SeatID] {
+			available = append(available, seat)
+		}
+	}
+
+	return available, nil
+}
+
+// AssignSeatToPassenger gives a seat to a passenger. The write is conditional on
+// the seat still being available and not held by another booking, so two
+// concurrent assignments cannot both win.
+func (r *SeatRepo) AssignSeatToPassenger(seatID, passengerID, bookingID string) error {
+	return assignSeat(r.client, seatID, passengerID, bookingID)
+}
+
+// DeleteSeatsByFlightID removes a flight's whole seat inventory
+func (r *SeatRepo) DeleteSeatsByFlightID(flightID string) error {
+	seats, err := r.GetSeatsByFlightID(flightID)
+	if err != nil {
+		return err
+	}
+
+	requests := make([]types.WriteRequest, 0, len(seats))
+	for _, seat := range seats {
+		requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: seatKey(seat.SeatID)}})
+	}
+	if err := batchWrite(r.client, seatsTable, requests); err != nil {
+		return err
+	}
+	_, err = r.client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:           aws.String(flightInventoryTable),
+		Key:                 flightInventoryKey(flightID),
+		UpdateExpression:    aws.String("REMOVE capacity"),
+		ConditionExpression: aws.String("attribute_exists(flightID)"),
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionErr) {
+		log.Printf("Error clearing capacity of flight %s: %v", flightID, err)
+		return err
+	}
+
+	// Expired holds are left for TTL to collect
+	holds, err := r.queryActiveHolds(flightID)
+	if err != nil {
+		return err
+	}
+	holdRequests := make([]types.WriteRequest, 0, len(holds))
+	for _, hold := range holds {
+		holdRequests = append(holdRequests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: seatKey(hold.SeatID)}})
+	}
+
+	return batchWrite(r.client, seatHoldsTable, holdRequests)
+}
+
+// HoldSeat blocks an available seat for a booking until hold.ExpiresAt. Holding a
+// seat the booking already holds extends the hold.
+func (r *SeatRepo) HoldSeat(hold *models.SeatHold) error {
+	if hold == nil {
+		return errors.New("seat hold details cannot be nil")
+	}
+
+	item, err := attributevalue.MarshalMap(hold)
+	if err != nil {
+		log.Printf("Error marshalling seat hold: %v", err)
+		return err
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{ConditionCheck: &types.ConditionCheck{
+				TableName:           aws.String(seatsTable),
+				Key:                 seatKey(hold.SeatID),
+				ConditionExpression: aws.String("isAvailable = :available"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":available": &types.AttributeValueMemberBOOL{Value: true},
+				},
+			}},
+			{Put: &types.Put{
+				TableName:           aws.String(seatHoldsTable),
+				Item:                item,
+				ConditionExpression: aws.String("attribute_not_exists(seatID) OR expiresAt <= :now OR bookingID = :bookingID"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":now":       unixTime(time.Now()),
+					":bookingID": &types.AttributeValueMemberS{Value: hold.BookingID},
+				},
+			}},
+		},
+	}
+
+	_, err = r.client.TransactWriteItems(context.Background(), input)
+	if err != nil {
+		if isTransactionConditionFailure(err) {
+			return db.ErrSeatUnavailable
+		}
+		log.Printf("Error holding seat %s: %v", hold.SeatID, err)
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseSeatHold drops the booking's active hold on a seat
+func (r *SeatRepo) ReleaseSeatHold(seatID, bookingID string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(seatHoldsTable),
+		Key:                       seatKey(seatID),
+		ConditionExpression:       aws.String(activeHoldCondition),
+		ExpressionAttributeValues: activeHoldValues(bookingID),
+	}
+
+	_, err := r.client.DeleteItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return db.ErrSeatHoldNotFound
+		}
+		log.Printf("Error releasing hold on seat %s: %v", seatID, err)
+		return err
+	}
+
+	return nil
+}
+
+// ConfirmSeatHold consumes the booking's active hold and assigns the seat to the
+// passenger in a single transaction
+func (r *SeatRepo) ConfirmSeatHold(seatID, passengerID, bookingID string) error {
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Delete: &types.Delete{
+				TableName:                 aws.String(seatHoldsTable),
+				Key:                       seatKey(seatID),
+				ConditionExpression:       aws.String(activeHoldCondition),
+				ExpressionAttributeValues: activeHoldValues(bookingID),
+			}},
+			{Update: assignSeatUpdate(seatID, passengerID, bookingID)},
+		},
+	}
+
+	_, err := r.client.TransactWriteItems(context.Background(), input)
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if errors.As(err, &canceled) && len(canceled.CancellationReasons) > 0 &&
+			aws.ToString(canceled.CancellationReasons[0].Code) == "ConditionalCheckFailed" {
+			return db.ErrSeatHoldNotFound
+		}
+		if isTransactionConditionFailure(err) {
+			return db.ErrSeatUnavailable
+		}
+		log.Printf("Error confirming hold on seat %s: %v", seatID, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetFlightInventories reads the seat counters of several flights in batches,
+// keyed by flightID. Flights without a counter are left out, and counters
+// written before capacity was recorded have none.
+func (r *SeatRepo) GetFlightInventories(flightIDs []string) (map[string]models.FlightInventory, error) {
+	requested := make(map[string]bool, len(flightIDs))
+	keys := make([]map[string]types.AttributeValue, 0, len(flightIDs))
+	for _, flightID := range flightIDs {
+		if !requested[flightID] {
+			requested[flightID] = true
+			keys = append(keys, flightInventoryKey(flightID))
+		}
+	}
+
+	items, err := batchGet(r.client, flightInventoryTable, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var counters []models.FlightInventory
+	if err := attributevalue.UnmarshalListOfMaps(items, &counters); err != nil {
+		log.Printf("Error unmarshalling flight inventory: %v", err)
+		return nil, err
+	}
+	inventories := make(map[string]models.FlightInventory, len(counters))
+	for _, inventory := range counters {
+		inventories[inventory.FlightID] = inventory
+	}
+	return inventories, nil
+}
+
+// ReserveSeats adds count to a flight's sold-seat counter. The write is
+// conditional on the counter staying within capacity, so concurrent bookings
+// cannot oversell the flight.
+func (r *SeatRepo) ReserveSeats(flightID string, count, capacity int) error {
+	if count > capacity {
+		return db.ErrSeatsSoldOut
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(flightInventoryTable),
+		Key:                 flightInventoryKey(flightID),
+		UpdateExpression:    aws.String("ADD seatsSold :count"),
+		ConditionExpression: aws.String("attribute_not_exists(seatsSold) OR seatsSold <= :max"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":count": numberValue(count),
+			":max":   numberValue(capacity - count),
+		},
+	}
+
+	_, err := r.client.UpdateItem(context.Background(), input)
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return db.ErrSeatsSoldOut
+		}
+		log.Printf("Error reserving seats on flight %s: %v", flightID, err)
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseSeats takes count off a flight's sold-seat counter, never below zero
+func (r *SeatRepo) ReleaseSeats(flightID string, count int) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(flightInventoryTable),
+		Key:                 flightInventoryKey(flightID),
+		UpdateExpression:    aws.String("ADD seatsSold :release"),
+		ConditionExpression: aws.String("seatsSold >= :count"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":release": numberValue(-count),
+			":count":   numberValue(count),
+		},
+	}
+
+	_, err := r.client.UpdateItem(context.Background(), input)
+	if err != nil {
+		log.Printf("Error releasing seats on flight %s: %v", flightID, err)
+		return err
+	}
+
+	return nil
+}
+
+// queryActiveHolds returns the unexpired holds on a flight's seats
+func (r *SeatRepo) queryActiveHolds(flightID string) ([]models.SeatHold, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(seatHoldsTable),
+		IndexName:              aws.String(seatHoldsFlightIndex),
+		KeyConditionExpression: aws.String("flightID = :flightID"),
+		FilterExpression:       aws.String("expiresAt > :now"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":flightID": &types.AttributeValueMemberS{Value: flightID},
+			":now":      unixTime(time.Now()),
+		},
+	}
+
+	var holds []models.SeatHold
+	if err := queryAll(r.client, input, &holds); err != nil {
+		log.Printf("Error querying seat holds for flight %s: %v", flightID, err)
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// querySeats runs a seat query, following LastEvaluatedKey until every page is read
+func (r *SeatRepo) querySeats(input *dynamodb.QueryInput) ([]models.Seat, error) {
+	var seats []models.Seat
+	if err := queryAll(r.client, input, &seats); err != nil {
+		log.Printf("Error querying seats: %v", err)
+		return nil, err
+	}
+
+	return seats, nil
+}
+
+// activeHoldCondition matches a hold that belongs to :bookingID and has not expired
+const activeHoldCondition = "bookingID = :bookingID AND expiresAt > :now"
+
+func activeHoldValues(bookingID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		":bookingID": &types.AttributeValueMemberS{Value: bookingID},
+		":now":       unixTime(time.Now()),
+	}
+}
+
+// assignSeat atomically assigns an available seat that is not held by another booking
+func assignSeat(client *dynamodb.Client, seatID, passengerID, bookingID string) error {
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{ConditionCheck: &types.ConditionCheck{
+				TableName:           aws.String(seatHoldsTable),
+				Key:                 seatKey(seatID),
+				ConditionExpression: aws.String("attribute_not_exists(seatID) OR expiresAt <= :now OR bookingID = :bookingID"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":now":       unixTime(time.Now()),
+					":bookingID": &types.AttributeValueMemberS{Value: bookingID},
+				},
+			}},
+			{Update: assignSeatUpdate(seatID, passengerID, bookingID)},
+		},
+	}
+
+	_, err := client.TransactWriteItems(context.Background(), input)
+	if err != nil {
+		if isTransactionConditionFailure(err) {
+			return db.ErrSeatUnavailable
+		}
+		log.Printf("Error assigning seat %s: %v", seatID, err)
+		return err
+	}
+
+	return nil
+}
+
+// assignSeatUpdate marks an available seat as taken by a passenger
+func assignSeatUpdate(seatID, passengerID, bookingID string) *types.Update {
+	return &types.Update{
+		TableName:           aws.String(seatsTable),
+		Key:                 seatKey(seatID),
+		UpdateExpression:    aws.String("SET isAvailable = :taken, passengerID = :passengerID, bookingID = :bookingID"),
+		ConditionExpression: aws.String("attribute_exists(seatID) AND isAvailable = :available"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":taken":       &types.AttributeValueMemberBOOL{Value: false},
+			":available":   &types.AttributeValueMemberBOOL{Value: true},
+			":passengerID": &types.AttributeValueMemberS{Value: passengerID},
+			":bookingID":   &types.AttributeValueMemberS{Value: bookingID},
+		},
+	}
+}
+
+// isTransactionConditionFailure reports whether a transaction was cancelled
+// because one of its condition expressions did not hold
+func isTransactionConditionFailure(err error) bool {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
+	}
+	for _, reason := range canceled.CancellationReasons {
+		if aws.ToString(reason.Code) == "ConditionalCheckFailed" {
+			return true
+		}
+	}
+	return false
+}
+
+// unixTime encodes a time the same way as the `unixtime` attributevalue tag option
+func unixTime(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}
+}
+
+func seatKey(seatID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"seatID": &types.AttributeValueMemberS{Value: seatID},
+	}
+}
+
+func flightInventoryKey(flightID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"flightID": &types.AttributeValueMemberS{Value: flightID},
+	}
+}
+
+// batchWrite sends write requests in chunks of 25, retrying unprocessed items
+func batchWrite(client *dynamodb.Client, table string, requests []types.WriteRequest) error {
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+
+		pending := map[string][]types.WriteRequest{table: requests[start:end]}
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt > maxBatchRetries {
+				return fmt.Errorf("unprocessed items remain after writing batch to %s", table)
+			}
+			if attempt > 0 {
+				time.Sleep(time.Duration(50<<attempt) * time.Millisecond)
+			}
+
+			result, err := client.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{
+				RequestItems: pending,
+			})
+			if err != nil {
+				log.Printf("Error writing batch to %s: %v", table, err)
+				return err
+			}
+			pending = result.UnprocessedItems
+		}
+	}
+
+	return nil
+}
+
+// batchGet reads items by key in chunks of 100, retrying unprocessed keys
+func batchGet(client *dynamodb.Client, table string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	for start := 0; start < len(keys); start += maxBatchGetItems {
+		end := start + maxBatchGetItems
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		pending := map[string]types.KeysAndAttributes{table: {Keys: keys[start:end], ConsistentRead: aws.Bool(true)}}
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt > maxBatchRetries {
+				return nil, fmt.Errorf("unprocessed keys remain after reading batch from %s", table)
+			}
+			if attempt > 0 {
+				time.Sleep(time.Duration(50<<attempt) * time.Millisecond)
+			}
+
+			result, err := client.BatchGetItem(context.Background(), &dynamodb.BatchGetItemInput{
+				RequestItems: pending,
+			})
+			if err != nil {
+				log.Printf("Error reading batch from %s: %v", table, err)
+				return nil, err
+			}
+			items = append(items, result.Responses[table]...)
+			pending = result.UnprocessedKeys
+		}
+	}
+
+	return items, nil
+}
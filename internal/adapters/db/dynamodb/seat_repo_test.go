@@ -0,0 +1,38 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+	"travel-backend/internal/core/domain/models"
+)
+
+func TestGetFlightInventories(t *testing.T) {
+	server := newTestServer(t, "test_")
+	repo := NewSeatRepo(ForTenant(server.Client(), "acme"))
+
+	var seats []models.Seat
+	for _, seat := range []struct{ flightID, number string }{{"f1", "1A"}, {"f1", "1B"}, {"f1", "1C"}, {"f2", "1A"}, {"f2", "1B"}} {
+		seats = append(seats, models.Seat{SeatID: seat.flightID + "-" + seat.number, FlightID: seat.flightID, SeatNumber: seat.number, IsAvailable: true})
+	}
+	if err := repo.CreateSeats(seats); err != nil {
+		t.Fatalf("CreateSeats() error = %v", err)
+	}
+	if err := repo.ReserveSeats("f1", 2, 3); err != nil {
+		t.Fatalf("ReserveSeats() error = %v", err)
+	}
+	if err := repo.DeleteSeatsByFlightID("f2"); err != nil {
+		t.Fatalf("DeleteSeatsByFlightID() error = %v", err)
+	}
+
+	got, err := repo.GetFlightInventories([]string{"f1", "f2", "f3", "f1"})
+	if err != nil {
+		t.Fatalf("GetFlightInventories() error = %v", err)
+	}
+	want := map[string]models.FlightInventory{
+		"f1": {FlightID: "f1", Capacity: 3, SeatsSold: 2},
+		"f2": {FlightID: "f2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFlightInventories() = %+v, want %+v", got, want)
+	}
+}
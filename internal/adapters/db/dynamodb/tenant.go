@@ -0,0 +1,563 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"travel-backend/customConfig"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// tenantKeyAttributes lists, for every table, the attributes whose values are
+// stored with the tenant's prefix: the table's partition key first, then the
+// partition keys of its indexes. Prefixing every partition a query can name
+// is what keeps one tenant from ever reading another's items.
+var tenantKeyAttributes = map[string][]string{
+	bookingsTable:          {"bookingID", "userID", "UserID"},
+	bookingLocatorsTable:   {"locator"},
+	faresTable:             {"fareID", "flightID"},
+	flightsTable:           {"flightID", "routeDate", "originDate"},
+	flightInventoryTable:   {"flightID"},
+	hotelsTable:            {"hotelID", "cityKey", "geohashPrefix"},
+	hotelReservationsTable: {"reservationID", "hotelID", "bookingID"},
+	idempotencyKeysTable:   {"idempotencyKey"},
+	mealsTable:             {"mealID"},
+	passengersTable:        {"passengerID", "bookingID"},
+	passengerMealsTable:    {"passengerID", "bookingID"},
+	ratePlansTable:         {"ratePlanID", "hotelID"},
+	roomRatesTable:         {"rateKey", "ratePlanID"},
+	roomInventoryTable:     {"inventoryKey"},
+	roomTypesTable:         {"roomTypeID", "hotelID"},
+	seatsTable:             {"seatID", "flightID", "bookingID"},
+	seatHoldsTable:         {"seatID", "flightID"},
+}
+
+// expressionToken matches one token of a condition, filter, key condition or
+// update expression: an operator, a parenthesis or comma, a value placeholder,
+// or a name, which is an attribute path, a keyword or a function
+var expressionToken = regexp.MustCompile(`^\s*(<>|<=|>=|[=<>(),+\-]|:\w+|[#\w][#\w.\[\]]*)`)
+
+// tenantScope confines a client to the items of one tenant. On the way in it
+// prepends the configured table prefix to table names and the tenant's prefix
+// to the values of tenantKeyAttributes, in items, keys and the expression
+// values compared with or assigned to them, and limits scans to the items
+// carrying the tenant's prefix. On the way out it strips the prefixes again,
+// so repositories never see them. Operations it does not know, and
+// expressions using a key attribute in a way it cannot prefix, are refused
+// rather than let through unscoped.
+type tenantScope struct {
+	tablePrefix string
+	keyPrefix   string
+}
+
+// ForTenant returns a copy of client whose calls only ever read and write the
+// items of tenantID, in tables named with the configured TablePrefix
+func ForTenant(client *dynamodb.Client, tenantID string) *dynamodb.Client {
+	scope := &tenantScope{
+		tablePrefix: customConfig.AppConfig.AWS.DynamoDB.TablePrefix,
+		keyPrefix:   tenantID + "#",
+	}
+	return dynamodb.New(client.Options(), func(o *dynamodb.Options) {
+		// Copy the options so that clients of different tenants never share them
+		o.APIOptions = append(append([]func(*middleware.Stack) error(nil), o.APIOptions...), scope.register)
+	})
+}
+
+func (s *tenantScope) register(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TenantScope",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			params, table, err := s.scopeInput(in.Parameters)
+			if err != nil {
+				return middleware.InitializeOutput{}, middleware.Metadata{}, err
+			}
+			in.Parameters = params
+
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if err == nil {
+				s.unscopeOutput(table, out.Result)
+			}
+			return out, metadata, err
+		}), middleware.Before)
+}
+
+// scopeInput returns a scoped copy of an operation's input and the table it
+// names. Inputs are copied rather than changed in place, since callers such
+// as paginators send the same input again.
+func (s *tenantScope) scopeInput(params interface{}) (interface{}, string, error) {
+	switch in := params.(type) {
+	case *dynamodb.GetItemInput:
+		scoped := *in
+		table, err := s.table(&scoped.TableName)
+		if err != nil {
+			return nil, "", err
+		}
+		scoped.Key = s.scopeItem(table, in.Key)
+		return &scoped, table, nil
+
+	case *dynamodb.PutItemInput:
+		scoped := *in
+		table, err := s.table(&scoped.TableName)
+		if err != nil {
+			return nil, "", err
+		}
+		scoped.Item = s.scopeItem(table, in.Item)
+		if scoped.ExpressionAttributeValues, err = s.scopeValues(table, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.ConditionExpression); err != nil {
+			return nil, "", err
+		}
+		return &scoped, table, nil
+
+	case *dynamodb.DeleteItemInput:
+		scoped := *in
+		table, err := s.table(&scoped.TableName)
+		if err != nil {
+			return nil, "", err
+		}
+		scoped.Key = s.scopeItem(table, in.Key)
+		if scoped.ExpressionAttributeValues, err = s.scopeValues(table, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.ConditionExpression); err != nil {
+			return nil, "", err
+		}
+		return &scoped, table, nil
+
+	case *dynamodb.UpdateItemInput:
+		scoped := *in
+		table, err := s.table(&scoped.TableName)
+		if err != nil {
+			return nil, "", err
+		}
+		scoped.Key = s.scopeItem(table, in.Key)
+		if scoped.ExpressionAttributeValues, err = s.scopeValues(table, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.UpdateExpression, in.ConditionExpression); err != nil {
+			return nil, "", err
+		}
+		return &scoped, table, nil
+
+	case *dynamodb.QueryInput:
+		scoped := *in
+		table, err := s.table(&scoped.TableName)
+		if err != nil {
+			return nil, "", err
+		}
+		partitions, err := s.keyPlaceholders(table, in.ExpressionAttributeNames, in.KeyConditionExpression)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(partitions) == 0 {
+			return nil, "", fmt.Errorf("query of %s must name a partition in its key condition", table)
+		}
+		scoped.ExclusiveStartKey = s.scopeItem(table, in.ExclusiveStartKey)
+		if scoped.ExpressionAttributeValues, err = s.scopeValues(table, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.KeyConditionExpression, in.FilterExpression); err != nil {
+			return nil, "", err
+		}
+		return &scoped, table, nil
+
+	case *dynamodb.ScanInput:
+		scoped := *in
+		table, err := s.table(&scoped.TableName)
+		if err != nil {
+			return nil, "", err
+		}
+		if in.IndexName != nil {
+			return nil, "", fmt.Errorf("scan of index %s of %s cannot be limited to a tenant", aws.ToString(in.IndexName), table)
+		}
+		scoped.ExclusiveStartKey = s.scopeItem(table, in.ExclusiveStartKey)
+		values, err := s.scopeValues(table, in.ExpressionAttributeNames, in.ExpressionAttributeValues, in.FilterExpression)
+		if err != nil {
+			return nil, "", err
+		}
+		s.filterScan(&scoped, tenantKeyAttributes[table][0], values)
+		return &scoped, table, nil
+
+	case *dynamodb.TransactWriteItemsInput:
+		scoped := *in
+		scoped.TransactItems = make([]types.TransactWriteItem, len(in.TransactItems))
+		for i, item := range in.TransactItems {
+			var err error
+			if scoped.TransactItems[i], err = s.scopeTransactItem(item); err != nil {
+				return nil, "", err
+			}
+		}
+		return &scoped, "", nil
+
+	case *dynamodb.BatchGetItemInput:
+		scoped := *in
+		scoped.RequestItems = make(map[string]types.KeysAndAttributes, len(in.RequestItems))
+		for table, request := range in.RequestItems {
+			name := aws.String(table)
+			if _, err := s.table(&name); err != nil {
+				return nil, "", err
+			}
+			keys := make([]map[string]types.AttributeValue, len(request.Keys))
+			for i, key := range request.Keys {
+				keys[i] = s.scopeItem(table, key)
+			}
+			request.Keys = keys
+			scoped.RequestItems[*name] = request
+		}
+		return &scoped, "", nil
+
+	case *dynamodb.BatchWriteItemInput:
+		scoped := *in
+		scoped.RequestItems = make(map[string][]types.WriteRequest, len(in.RequestItems))
+		for table, requests := range in.RequestItems {
+			name := aws.String(table)
+			if _, err := s.table(&name); err != nil {
+				return nil, "", err
+			}
+			scopedRequests := make([]types.WriteRequest, len(requests))
+			for i, request := range requests {
+				if request.PutRequest != nil {
+					scopedRequests[i].PutRequest = &types.PutRequest{Item: s.scopeItem(table, request.PutRequest.Item)}
+				}
+				if request.DeleteRequest != nil {
+					scopedRequests[i].DeleteRequest = &types.DeleteRequest{Key: s.scopeItem(table, request.DeleteRequest.Key)}
+				}
+			}
+			scoped.RequestItems[*name] = scopedRequests
+		}
+		return &scoped, "", nil
+	}
+	return nil, "", fmt.Errorf("%T cannot be limited to a tenant", params)
+}
+
+func (s *tenantScope) scopeTransactItem(item types.TransactWriteItem) (types.TransactWriteItem, error) {
+	var scoped types.TransactWriteItem
+	switch {
+	case item.Put != nil:
+		put := *item.Put
+		table, err := s.table(&put.TableName)
+		if err != nil {
+			return scoped, err
+		}
+		put.Item = s.scopeItem(table, item.Put.Item)
+		if put.ExpressionAttributeValues, err = s.scopeValues(table, put.ExpressionAttributeNames, put.ExpressionAttributeValues, put.ConditionExpression); err != nil {
+			return scoped, err
+		}
+		scoped.Put = &put
+	case item.Delete != nil:
+		del := *item.Delete
+		table, err := s.table(&del.TableName)
+		if err != nil {
+			return scoped, err
+		}
+		del.Key = s.scopeItem(table, item.Delete.Key)
+		if del.ExpressionAttributeValues, err = s.scopeValues(table, del.ExpressionAttributeNames, del.ExpressionAttributeValues, del.ConditionExpression); err != nil {
+			return scoped, err
+		}
+		scoped.Delete = &del
+	case item.Update != nil:
+		update := *item.Update
+		table, err := s.table(&update.TableName)
+		if err != nil {
+			return scoped, err
+		}
+		update.Key = s.scopeItem(table, item.Update.Key)
+		if update.ExpressionAttributeValues, err = s.scopeValues(table, update.ExpressionAttributeNames, update.ExpressionAttributeValues, update.UpdateExpression, update.ConditionExpression); err != nil {
+			return scoped, err
+		}
+		scoped.Update = &update
+	case item.ConditionCheck != nil:
+		check := *item.ConditionCheck
+		table, err := s.table(&check.TableName)
+		if err != nil {
+			return scoped, err
+		}
+		check.Key = s.scopeItem(table, item.ConditionCheck.Key)
+		if check.ExpressionAttributeValues, err = s.scopeValues(table, check.ExpressionAttributeNames, check.ExpressionAttributeValues, check.ConditionExpression); err != nil {
+			return scoped, err
+		}
+		scoped.ConditionCheck = &check
+	}
+	return scoped, nil
+}
+
+// table prefixes the table name at name and returns the unprefixed name,
+// refusing tables without tenant key attributes
+func (s *tenantScope) table(name **string) (string, error) {
+	table := aws.ToString(*name)
+	if _, ok := tenantKeyAttributes[table]; !ok {
+		return "", fmt.Errorf("table %q cannot be limited to a tenant", table)
+	}
+	*name = aws.String(s.tablePrefix + table)
+	return table, nil
+}
+
+// scopeItem returns a copy of an item or key with the tenant's prefix on its
+// key attributes
+func (s *tenantScope) scopeItem(table string, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	scoped := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		scoped[name] = value
+	}
+	for _, name := range tenantKeyAttributes[table] {
+		if value, ok := scoped[name].(*types.AttributeValueMemberS); ok {
+			scoped[name] = &types.AttributeValueMemberS{Value: s.keyPrefix + value.Value}
+		}
+	}
+	return scoped
+}
+
+// scopeValues returns a copy of the expression attribute values with the
+// tenant's prefix on every value the expressions compare with or assign to a
+// key attribute
+func (s *tenantScope) scopeValues(table string, names map[string]string, values map[string]types.AttributeValue, expressions ...*string) (map[string]types.AttributeValue, error) {
+	placeholders, err := s.keyPlaceholders(table, names, expressions...)
+	if err != nil || values == nil {
+		return nil, err
+	}
+	scoped := make(map[string]types.AttributeValue, len(values))
+	for placeholder, value := range values {
+		scoped[placeholder] = value
+	}
+	for _, placeholder := range placeholders {
+		if value, ok := values[placeholder].(*types.AttributeValueMemberS); ok {
+			scoped[placeholder] = &types.AttributeValueMemberS{Value: s.keyPrefix + value.Value}
+		}
+	}
+	return scoped, nil
+}
+
+// keyPlaceholders lists the value placeholders the expressions compare with
+// or assign to a key attribute of table. A placeholder also used with another
+// attribute cannot carry the prefix in one place and not the other, so it is
+// refused.
+func (s *tenantScope) keyPlaceholders(table string, names map[string]string, expressions ...*string) ([]string, error) {
+	keyValues := make(map[string]bool)
+	otherValues := make(map[string]bool)
+	for _, expression := range expressions {
+		tokens, err := tokenizeExpression(aws.ToString(expression))
+		if err != nil {
+			return nil, err
+		}
+		bound, err := keyOperands(table, names, tokens)
+		if err != nil {
+			return nil, fmt.Errorf("expression %q: %w", aws.ToString(expression), err)
+		}
+		for i, token := range tokens {
+			switch {
+			case !strings.HasPrefix(token, ":"):
+			case bound[i]:
+				keyValues[token] = true
+			default:
+				otherValues[token] = true
+			}
+		}
+	}
+
+	var placeholders []string
+	for placeholder := range keyValues {
+		if otherValues[placeholder] {
+			return nil, fmt.Errorf("value %s is used with both key and other attributes of %s", placeholder, table)
+		}
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Strings(placeholders)
+	return placeholders, nil
+}
+
+// keyOperands returns the positions of the value placeholders that tokens
+// compare with or assign to a key attribute of table. Key attributes may be
+// compared, matched with BETWEEN, IN and begins_with, defaulted with
+// if_not_exists, tested for existence and removed; any other use is refused,
+// as the tenant's prefix could not be put on the values it involves.
+func keyOperands(table string, names map[string]string, tokens []string) (map[int]bool, error) {
+	at := func(i int) string {
+		if i < 0 || i >= len(tokens) {
+			return ""
+		}
+		return tokens[i]
+	}
+	isValue := func(i int) bool { return strings.HasPrefix(at(i), ":") }
+	isKey := func(i int) bool {
+		return at(i+1) != "(" && isKeyAttribute(table, resolveName(at(i), names))
+	}
+	isArithmetic := func(i int) bool { return at(i) == "+" || at(i) == "-" }
+	function := func(i int) string {
+		if at(i-1) != "(" {
+			return ""
+		}
+		return strings.ToLower(at(i - 2))
+	}
+
+	bound := make(map[int]bool)
+	clause := ""
+	for i, token := range tokens {
+		switch strings.ToUpper(token) {
+		case "SET", "REMOVE", "ADD", "DELETE":
+			clause = strings.ToUpper(token)
+			continue
+		}
+		if !isKey(i) {
+			continue
+		}
+
+		switch fn := function(i); {
+		case clause == "REMOVE" && fn == "":
+			// Removing an attribute involves no value
+		case (fn == "attribute_exists" || fn == "attribute_not_exists") && at(i+1) == ")":
+		case (fn == "begins_with" || fn == "if_not_exists") && at(i+1) == "," && isValue(i+2) && at(i+3) == ")" && !isArithmetic(i-3) && !isArithmetic(i+4):
+			bound[i+2] = true
+		case at(i+1) == "=" && strings.EqualFold(at(i+2), "if_not_exists") && at(i+3) == "(" && isKey(i+4) && !isArithmetic(i+8):
+			// Assigned the key attribute in if_not_exists, which binds its default
+		case comparators[at(i+1)] && !isArithmetic(i-1) && !isArithmetic(i+3) && (isValue(i+2) || isKey(i+2)):
+			bound[i+2] = isValue(i + 2)
+		case comparators[at(i-1)] && !isArithmetic(i-3) && !isArithmetic(i+1) && (isValue(i-2) || isKey(i-2)):
+			bound[i-2] = isValue(i - 2)
+		case strings.EqualFold(at(i+1), "BETWEEN") && isValue(i+2) && strings.EqualFold(at(i+3), "AND") && isValue(i+4):
+			bound[i+2], bound[i+4] = true, true
+		case strings.EqualFold(at(i+1), "IN") && at(i+2) == "(":
+			j := i + 3
+			for ; isValue(j) && at(j+1) == ","; j += 2 {
+				bound[j] = true
+			}
+			if !isValue(j) || at(j+1) != ")" {
+				return nil, fmt.Errorf("key attribute %s must be matched IN a list of values", token)
+			}
+			bound[j] = true
+		default:
+			return nil, fmt.Errorf("key attribute %s is used in a way that cannot be limited to a tenant", token)
+		}
+	}
+	return bound, nil
+}
+
+// comparators are the operators comparing two operands, of which = also
+// assigns in update expressions
+var comparators = map[string]bool{"=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// tokenizeExpression splits an expression into its tokens
+func tokenizeExpression(expression string) ([]string, error) {
+	var tokens []string
+	for rest := expression; strings.TrimSpace(rest) != ""; {
+		match := expressionToken.FindStringSubmatchIndex(rest)
+		if match == nil {
+			return nil, fmt.Errorf("expression %q cannot be read at %q", expression, strings.TrimSpace(rest))
+		}
+		tokens = append(tokens, rest[match[2]:match[3]])
+		rest = rest[match[1]:]
+	}
+	return tokens, nil
+}
+
+// filterScan narrows a scan to the items whose partition key carries the
+// tenant's prefix
+func (s *tenantScope) filterScan(input *dynamodb.ScanInput, partitionKey string, values map[string]types.AttributeValue) {
+	names := map[string]string{"#tenantKey": partitionKey}
+	for placeholder, name := range input.ExpressionAttributeNames {
+		names[placeholder] = name
+	}
+	if values == nil {
+		values = make(map[string]types.AttributeValue)
+	}
+	values[":tenantKey"] = &types.AttributeValueMemberS{Value: s.keyPrefix}
+
+	filter := "begins_with(#tenantKey, :tenantKey)"
+	if input.FilterExpression != nil {
+		filter = "(" + *input.FilterExpression + ") AND " + filter
+	}
+	input.FilterExpression = aws.String(filter)
+	input.ExpressionAttributeNames = names
+	input.ExpressionAttributeValues = values
+}
+
+// unscopeOutput strips the prefixes from the items and keys of a result from
+// table
+func (s *tenantScope) unscopeOutput(table string, result interface{}) {
+	switch out := result.(type) {
+	case *dynamodb.GetItemOutput:
+		s.unscopeItem(table, out.Item)
+	case *dynamodb.PutItemOutput:
+		s.unscopeItem(table, out.Attributes)
+	case *dynamodb.DeleteItemOutput:
+		s.unscopeItem(table, out.Attributes)
+	case *dynamodb.UpdateItemOutput:
+		s.unscopeItem(table, out.Attributes)
+	case *dynamodb.QueryOutput:
+		for _, item := range out.Items {
+			s.unscopeItem(table, item)
+		}
+		s.unscopeItem(table, out.LastEvaluatedKey)
+	case *dynamodb.ScanOutput:
+		for _, item := range out.Items {
+			s.unscopeItem(table, item)
+		}
+		s.unscopeItem(table, out.LastEvaluatedKey)
+	case *dynamodb.BatchGetItemOutput:
+		responses := make(map[string][]map[string]types.AttributeValue, len(out.Responses))
+		for name, items := range out.Responses {
+			table := strings.TrimPrefix(name, s.tablePrefix)
+			for _, item := range items {
+				s.unscopeItem(table, item)
+			}
+			responses[table] = items
+		}
+		out.Responses = responses
+		// Unprocessed keys are sent again by the caller, like unprocessed writes
+		unprocessed := make(map[string]types.KeysAndAttributes, len(out.UnprocessedKeys))
+		for name, request := range out.UnprocessedKeys {
+			table := strings.TrimPrefix(name, s.tablePrefix)
+			for _, key := range request.Keys {
+				s.unscopeItem(table, key)
+			}
+			unprocessed[table] = request
+		}
+		out.UnprocessedKeys = unprocessed
+	case *dynamodb.BatchWriteItemOutput:
+		// Unprocessed requests are sent again by the caller, which names
+		// tables and keys without prefixes
+		unprocessed := make(map[string][]types.WriteRequest, len(out.UnprocessedItems))
+		for name, requests := range out.UnprocessedItems {
+			table := strings.TrimPrefix(name, s.tablePrefix)
+			for _, request := range requests {
+				if request.PutRequest != nil {
+					s.unscopeItem(table, request.PutRequest.Item)
+				}
+				if request.DeleteRequest != nil {
+					s.unscopeItem(table, request.DeleteRequest.Key)
+				}
+			}
+			unprocessed[table] = requests
+		}
+		out.UnprocessedItems = unprocessed
+	}
+}
+
+// unscopeItem strips the tenant's prefix from the key attributes of an item
+// of table. Results belong to the call that made them, so they are changed in
+// place.
+func (s *tenantScope) unscopeItem(table string, item map[string]types.AttributeValue) {
+	for _, name := range tenantKeyAttributes[table] {
+		if value, ok := item[name].(*types.AttributeValueMemberS); ok {
+			item[name] = &types.AttributeValueMemberS{Value: strings.TrimPrefix(value.Value, s.keyPrefix)}
+		}
+	}
+}
+
+func isKeyAttribute(table, name string) bool {
+	for _, attribute := range tenantKeyAttributes[table] {
+		if attribute == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveName returns the attribute an operand names, looking up #name
+// placeholders. Paths into maps and lists name no key attribute and are
+// returned empty.
+func resolveName(operand string, names map[string]string) string {
+	if strings.ContainsAny(operand, ".[") {
+		return ""
+	}
+	if strings.HasPrefix(operand, "#") {
+		return names[operand]
+	}
+	return operand
+}
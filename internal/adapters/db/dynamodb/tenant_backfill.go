@@ -0,0 +1,154 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"travel-backend/customConfig"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// tenantSortKeys names the sort key of the tables that have one. Together
+// with the first of tenantKeyAttributes it makes up an item's primary key.
+var tenantSortKeys = map[string]string{
+	roomRatesTable:     "date",
+	roomInventoryTable: "date",
+}
+
+// TenantBackfillResult summarises a BackfillTenant run
+type TenantBackfillResult struct {
+	Scanned int
+	Copied  int
+	Skipped int
+	Failed  int
+}
+
+// BackfillTenant copies the items stored before tenants existed to the tenant
+// of target, a client returned by ForTenant. Legacy items are read through
+// source, a client that is not limited to a tenant, from the tables without
+// the configured TablePrefix. Items whose partition key already carries the
+// prefix of one of tenantIDs are skipped, as are legacy Hotels rows, which
+// are split by MigrateLegacyHotels instead. When the legacy and the tenant's
+// table are the same, copied items replace the legacy ones; otherwise the
+// legacy tables are left as they are.
+//
+// Items are only written where the tenant has none with the same key, so the
+// backfill can safely be re-run. With dryRun set the items are counted but
+// nothing is written.
+func BackfillTenant(source, target *dynamodb.Client, tenantIDs []string, dryRun bool) (TenantBackfillResult, error) {
+	var result TenantBackfillResult
+	sameTables := customConfig.AppConfig.AWS.DynamoDB.TablePrefix == ""
+
+	tables := make([]string, 0, len(tenantKeyAttributes))
+	for table := range tenantKeyAttributes {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		partitionKey := tenantKeyAttributes[table][0]
+		paginator := dynamodb.NewScanPaginator(source, &dynamodb.ScanInput{
+			TableName: aws.String(table),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(context.Background())
+			var missing *types.ResourceNotFoundException
+			if errors.As(err, &missing) {
+				log.Printf("Table %s does not exist, nothing to backfill", table)
+				break
+			}
+			if err != nil {
+				log.Printf("Error scanning %s for backfill: %v", table, err)
+				return result, err
+			}
+
+			for _, item := range page.Items {
+				result.Scanned++
+				key, ok := item[partitionKey].(*types.AttributeValueMemberS)
+				if !ok || hasTenantPrefix(key.Value, tenantIDs) || (table == hotelsTable && isLegacyHotel(item)) {
+					result.Skipped++
+					continue
+				}
+				if dryRun {
+					result.Copied++
+					continue
+				}
+
+				err := copyToTenant(target, table, item)
+				if errors.Is(err, errItemExists) {
+					result.Skipped++
+					continue
+				}
+				if err == nil && sameTables {
+					err = deleteLegacyItem(source, table, item)
+				}
+				if err != nil {
+					log.Printf("Error backfilling %s item %s: %v", table, key.Value, err)
+					result.Failed++
+					continue
+				}
+				result.Copied++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// errItemExists is returned by copyToTenant when the tenant already has an
+// item with the legacy item's key
+var errItemExists = errors.New("item already exists")
+
+func hasTenantPrefix(value string, tenantIDs []string) bool {
+	for _, id := range tenantIDs {
+		if strings.HasPrefix(value, id+"#") {
+			return true
+		}
+	}
+	return false
+}
+
+// copyToTenant writes a legacy item for the tenant of client, unless the
+// tenant already has one with the same key
+func copyToTenant(client *dynamodb.Client, table string, item map[string]types.AttributeValue) error {
+	if table == bookingsTable {
+		// The UserID index is keyed by a copy of userID that legacy items lack
+		if userID, ok := item["userID"]; ok {
+			if _, indexed := item["UserID"]; !indexed {
+				item["UserID"] = userID
+			}
+		}
+	}
+
+	_, err := client.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:                aws.String(table),
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#key)"),
+		ExpressionAttributeNames: map[string]string{"#key": tenantKeyAttributes[table][0]},
+	})
+	var failed *types.ConditionalCheckFailedException
+	if errors.As(err, &failed) {
+		return errItemExists
+	}
+	return err
+}
+
+// deleteLegacyItem removes a legacy item once it has been copied
+func deleteLegacyItem(client *dynamodb.Client, table string, item map[string]types.AttributeValue) error {
+	key := map[string]types.AttributeValue{
+		tenantKeyAttributes[table][0]: item[tenantKeyAttributes[table][0]],
+	}
+	if sortKey, ok := tenantSortKeys[table]; ok {
+		key[sortKey] = item[sortKey]
+	}
+	_, err := client.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key:       key,
+	})
+	return err
+}
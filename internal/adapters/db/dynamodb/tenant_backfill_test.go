@@ -0,0 +1,145 @@
+package dynamodb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"travel-backend/customConfig"
+	"travel-backend/internal/adapters/db/dynamodb/testutil"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// newTestServer serves every table, named with tablePrefix, from memory
+func newTestServer(t *testing.T, tablePrefix string) *testutil.Server {
+	t.Helper()
+	previous := customConfig.AppConfig
+	t.Cleanup(func() { customConfig.AppConfig = previous })
+	customConfig.AppConfig = &customConfig.Config{}
+	customConfig.AppConfig.AWS.DynamoDB.TablePrefix = tablePrefix
+
+	server := testutil.NewServer()
+	t.Cleanup(server.Close)
+	for table, attributes := range tenantKeyAttributes {
+		server.CreateTable(tablePrefix+table, attributes[0], tenantSortKeys[table])
+	}
+	return server
+}
+
+func putItem(t *testing.T, client *dynamodb.Client, table string, values item) {
+	t.Helper()
+	_, err := client.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String(table), Item: values})
+	if err != nil {
+		t.Fatalf("putting %s item: %v", table, err)
+	}
+}
+
+// stringAttribute returns the string value of an attribute of an item listed
+// by the test server
+func stringAttribute(values map[string]interface{}, name string) string {
+	value, _ := values[name].(map[string]interface{})["S"].(string)
+	return value
+}
+
+func TestForTenantIsolatesRepositories(t *testing.T) {
+	server := newTestServer(t, "test_")
+	acme := NewFlightRepo(ForTenant(server.Client(), "acme"))
+	globex := NewFlightRepo(ForTenant(server.Client(), "globex"))
+
+	departure := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	err := acme.CreateFlight(&models.Flight{
+		FlightID: "f1", Airline: "AI", Origin: "DEL", Destination: "BOM",
+		DepartureTime: departure, ArrivalTime: departure.Add(2 * time.Hour), AircraftType: "A320",
+	})
+	if err != nil {
+		t.Fatalf("CreateFlight() error = %v", err)
+	}
+
+	items := server.Items("test_Flights")
+	if len(items) != 1 {
+		t.Fatalf("test_Flights holds %d items, want 1", len(items))
+	}
+	for _, attribute := range tenantKeyAttributes[flightsTable] {
+		if value := stringAttribute(items[0], attribute); !strings.HasPrefix(value, "acme#") {
+			t.Errorf("stored %s = %q, want the acme# prefix", attribute, value)
+		}
+	}
+
+	got, err := acme.GetFlightByID("f1")
+	if err != nil || got == nil || got.FlightID != "f1" {
+		t.Fatalf("acme GetFlightByID() = %+v, %v, want flight f1 without the prefix", got, err)
+	}
+	got, err = globex.GetFlightByID("f1")
+	if err != nil || got != nil {
+		t.Fatalf("globex GetFlightByID() = %+v, %v, want no flight", got, err)
+	}
+	page, err := globex.ListFlights(models.FlightQuery{Page: models.PageRequest{Limit: 10}})
+	if err != nil || len(page.Items) != 0 {
+		t.Fatalf("globex ListFlights() = %+v, %v, want no flights", page, err)
+	}
+}
+
+func TestBackfillTenant(t *testing.T) {
+	server := newTestServer(t, "")
+	source := server.Client()
+	target := ForTenant(source, "acme")
+
+	putItem(t, source, bookingsTable, item{"bookingID": str("b1"), "userID": str("u1")})
+	putItem(t, source, bookingsTable, item{"bookingID": str("globex#b2"), "userID": str("globex#u2")})
+	putItem(t, source, roomRatesTable, item{"rateKey": str("p1#r1"), "date": str("2026-11-01"), "ratePlanID": str("p1"), "amount": num("400000")})
+	putItem(t, source, hotelsTable, item{"hotelID": str("h1"), "bookingID": str("b1"), "checkInDate": str("2026-11-01")})
+
+	dryRun, err := BackfillTenant(source, target, []string{"acme", "globex"}, true)
+	if err != nil {
+		t.Fatalf("BackfillTenant() dry run error = %v", err)
+	}
+	if want := (TenantBackfillResult{Scanned: 4, Copied: 2, Skipped: 2}); dryRun != want {
+		t.Fatalf("BackfillTenant() dry run = %+v, want %+v", dryRun, want)
+	}
+	if value := stringAttribute(server.Items(bookingsTable)[0], "bookingID"); value != "b1" {
+		t.Fatalf("dry run changed booking b1 to %q", value)
+	}
+
+	result, err := BackfillTenant(source, target, []string{"acme", "globex"}, false)
+	if err != nil {
+		t.Fatalf("BackfillTenant() error = %v", err)
+	}
+	if want := (TenantBackfillResult{Scanned: 4, Copied: 2, Skipped: 2}); result != want {
+		t.Fatalf("BackfillTenant() = %+v, want %+v", result, want)
+	}
+
+	// The legacy booking now belongs to acme, indexed by user, and is gone
+	booking, err := NewBookingRepo(target).GetBookingByID("b1")
+	if err != nil || booking == nil || booking.UserID != "u1" {
+		t.Fatalf("acme GetBookingByID(b1) = %+v, %v, want the backfilled booking", booking, err)
+	}
+	var keys []string
+	for _, stored := range server.Items(bookingsTable) {
+		keys = append(keys, stringAttribute(stored, "bookingID"))
+		if stringAttribute(stored, "bookingID") == "acme#b1" && stringAttribute(stored, "UserID") != "acme#u1" {
+			t.Errorf("backfilled booking UserID = %q, want acme#u1", stringAttribute(stored, "UserID"))
+		}
+	}
+	if len(keys) != 2 || keys[0] != "acme#b1" || keys[1] != "globex#b2" {
+		t.Errorf("Bookings keys = %v, want [acme#b1 globex#b2]", keys)
+	}
+	rates := server.Items(roomRatesTable)
+	if len(rates) != 1 || stringAttribute(rates[0], "rateKey") != "acme#p1#r1" || stringAttribute(rates[0], "ratePlanID") != "acme#p1" {
+		t.Errorf("RoomRates = %v, want the rate keyed for acme", rates)
+	}
+	if hotels := server.Items(hotelsTable); len(hotels) != 1 || stringAttribute(hotels[0], "hotelID") != "h1" {
+		t.Errorf("Hotels = %v, want the legacy row left for migrate-hotels", hotels)
+	}
+
+	// Running again finds nothing left to move
+	again, err := BackfillTenant(source, target, []string{"acme", "globex"}, false)
+	if err != nil {
+		t.Fatalf("BackfillTenant() rerun error = %v", err)
+	}
+	if want := (TenantBackfillResult{Scanned: 4, Skipped: 4}); again != want {
+		t.Fatalf("BackfillTenant() rerun = %+v, want %+v", again, want)
+	}
+}
@@ -0,0 +1,446 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func str(value string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: value}
+}
+
+func num(value string) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: value}
+}
+
+type item = map[string]types.AttributeValue
+
+func testScope() *tenantScope {
+	return &tenantScope{tablePrefix: "test_", keyPrefix: "acme#"}
+}
+
+func TestScopeInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  interface{}
+		table string
+	}{
+		{
+			name:  "GetItem",
+			input: &dynamodb.GetItemInput{TableName: aws.String(bookingsTable), Key: item{"bookingID": str("b1")}},
+			want:  &dynamodb.GetItemInput{TableName: aws.String("test_Bookings"), Key: item{"bookingID": str("acme#b1")}},
+			table: bookingsTable,
+		},
+		{
+			name: "PutItem prefixes the keys of the item and its indexes",
+			input: &dynamodb.PutItemInput{
+				TableName:                 aws.String(bookingsTable),
+				Item:                      item{"bookingID": str("b1"), "userID": str("u1"), "UserID": str("u1"), "locator": str("ABC123"), "version": num("1")},
+				ConditionExpression:       aws.String("attribute_not_exists(bookingID) OR (#userID = :user AND version = :version)"),
+				ExpressionAttributeNames:  map[string]string{"#userID": "userID"},
+				ExpressionAttributeValues: item{":user": str("u1"), ":version": num("1")},
+			},
+			want: &dynamodb.PutItemInput{
+				TableName:                 aws.String("test_Bookings"),
+				Item:                      item{"bookingID": str("acme#b1"), "userID": str("acme#u1"), "UserID": str("acme#u1"), "locator": str("ABC123"), "version": num("1")},
+				ConditionExpression:       aws.String("attribute_not_exists(bookingID) OR (#userID = :user AND version = :version)"),
+				ExpressionAttributeNames:  map[string]string{"#userID": "userID"},
+				ExpressionAttributeValues: item{":user": str("acme#u1"), ":version": num("1")},
+			},
+			table: bookingsTable,
+		},
+		{
+			name: "DeleteItem",
+			input: &dynamodb.DeleteItemInput{
+				TableName:                 aws.String(seatHoldsTable),
+				Key:                       item{"seatID": str("f1-1A")},
+				ConditionExpression:       aws.String("bookingID = :booking"),
+				ExpressionAttributeValues: item{":booking": str("b1")},
+			},
+			want: &dynamodb.DeleteItemInput{
+				TableName:                 aws.String("test_SeatHolds"),
+				Key:                       item{"seatID": str("acme#f1-1A")},
+				ConditionExpression:       aws.String("bookingID = :booking"),
+				ExpressionAttributeValues: item{":booking": str("b1")},
+			},
+			table: seatHoldsTable,
+		},
+		{
+			name: "UpdateItem prefixes values assigned to keys",
+			input: &dynamodb.UpdateItemInput{
+				TableName:                 aws.String(seatsTable),
+				Key:                       item{"seatID": str("f1-1A")},
+				UpdateExpression:          aws.String("SET bookingID = :booking, passengerID = :passenger"),
+				ConditionExpression:       aws.String("attribute_not_exists(bookingID)"),
+				ExpressionAttributeValues: item{":booking": str("b1"), ":passenger": str("p1")},
+			},
+			want: &dynamodb.UpdateItemInput{
+				TableName:                 aws.String("test_Seats"),
+				Key:                       item{"seatID": str("acme#f1-1A")},
+				UpdateExpression:          aws.String("SET bookingID = :booking, passengerID = :passenger"),
+				ConditionExpression:       aws.String("attribute_not_exists(bookingID)"),
+				ExpressionAttributeValues: item{":booking": str("acme#b1"), ":passenger": str("p1")},
+			},
+			table: seatsTable,
+		},
+		{
+			name: "Query of an index",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String(bookingsTable),
+				IndexName:                 aws.String(bookingsUserIDIndex),
+				KeyConditionExpression:    aws.String("UserID = :userID"),
+				FilterExpression:          aws.String("bookingStatus = :status"),
+				ExpressionAttributeValues: item{":userID": str("u1"), ":status": str("PENDING")},
+				ExclusiveStartKey:         item{"bookingID": str("b1"), "UserID": str("u1")},
+			},
+			want: &dynamodb.QueryInput{
+				TableName:                 aws.String("test_Bookings"),
+				IndexName:                 aws.String(bookingsUserIDIndex),
+				KeyConditionExpression:    aws.String("UserID = :userID"),
+				FilterExpression:          aws.String("bookingStatus = :status"),
+				ExpressionAttributeValues: item{":userID": str("acme#u1"), ":status": str("PENDING")},
+				ExclusiveStartKey:         item{"bookingID": str("acme#b1"), "UserID": str("acme#u1")},
+			},
+			table: bookingsTable,
+		},
+		{
+			name: "Query with a sort key condition",
+			input: &dynamodb.QueryInput{
+				TableName:                 aws.String(roomRatesTable),
+				KeyConditionExpression:    aws.String("#key = :key AND #date BETWEEN :from AND :to"),
+				ExpressionAttributeNames:  map[string]string{"#key": "rateKey", "#date": "date"},
+				ExpressionAttributeValues: item{":key": str("p1#r1"), ":from": str("2026-11-01"), ":to": str("2026-11-03")},
+			},
+			want: &dynamodb.QueryInput{
+				TableName:                 aws.String("test_RoomRates"),
+				KeyConditionExpression:    aws.String("#key = :key AND #date BETWEEN :from AND :to"),
+				ExpressionAttributeNames:  map[string]string{"#key": "rateKey", "#date": "date"},
+				ExpressionAttributeValues: item{":key": str("acme#p1#r1"), ":from": str("2026-11-01"), ":to": str("2026-11-03")},
+			},
+			table: roomRatesTable,
+		},
+		{
+			name: "Scan is limited to the tenant's items",
+			input: &dynamodb.ScanInput{
+				TableName: aws.String(flightsTable),
+			},
+			want: &dynamodb.ScanInput{
+				TableName:                 aws.String("test_Flights"),
+				FilterExpression:          aws.String("begins_with(#tenantKey, :tenantKey)"),
+				ExpressionAttributeNames:  map[string]string{"#tenantKey": "flightID"},
+				ExpressionAttributeValues: item{":tenantKey": str("acme#")},
+			},
+			table: flightsTable,
+		},
+		{
+			name: "Scan keeps its own filter",
+			input: &dynamodb.ScanInput{
+				TableName:                 aws.String(hotelsTable),
+				FilterExpression:          aws.String("#city = :city OR hotelID = :hotel"),
+				ExpressionAttributeNames:  map[string]string{"#city": "cityKey"},
+				ExpressionAttributeValues: item{":city": str("mumbai"), ":hotel": str("h1")},
+				ExclusiveStartKey:         item{"hotelID": str("h0")},
+			},
+			want: &dynamodb.ScanInput{
+				TableName:                 aws.String("test_Hotels"),
+				FilterExpression:          aws.String("(#city = :city OR hotelID = :hotel) AND begins_with(#tenantKey, :tenantKey)"),
+				ExpressionAttributeNames:  map[string]string{"#city": "cityKey", "#tenantKey": "hotelID"},
+				ExpressionAttributeValues: item{":city": str("acme#mumbai"), ":hotel": str("acme#h1"), ":tenantKey": str("acme#")},
+				ExclusiveStartKey:         item{"hotelID": str("acme#h0")},
+			},
+			table: hotelsTable,
+		},
+		{
+			name: "Scan matches keys with begins_with, BETWEEN and IN",
+			input: &dynamodb.ScanInput{
+				TableName:                 aws.String(bookingsTable),
+				FilterExpression:          aws.String("(begins_with(userID, :prefix) OR bookingID BETWEEN :first AND :last OR #user IN (:u1, :u2)) AND bookingStatus IN (:status)"),
+				ExpressionAttributeNames:  map[string]string{"#user": "UserID"},
+				ExpressionAttributeValues: item{":prefix": str("u"), ":first": str("b1"), ":last": str("b9"), ":u1": str("u1"), ":u2": str("u2"), ":status": str("PENDING")},
+			},
+			want: &dynamodb.ScanInput{
+				TableName:                aws.String("test_Bookings"),
+				FilterExpression:         aws.String("((begins_with(userID, :prefix) OR bookingID BETWEEN :first AND :last OR #user IN (:u1, :u2)) AND bookingStatus IN (:status)) AND begins_with(#tenantKey, :tenantKey)"),
+				ExpressionAttributeNames: map[string]string{"#user": "UserID", "#tenantKey": "bookingID"},
+				ExpressionAttributeValues: item{
+					":prefix": str("acme#u"), ":first": str("acme#b1"), ":last": str("acme#b9"), ":u1": str("acme#u1"), ":u2": str("acme#u2"),
+					":status": str("PENDING"), ":tenantKey": str("acme#"),
+				},
+			},
+			table: bookingsTable,
+		},
+		{
+			name: "UpdateItem defaults keys with if_not_exists and removes them",
+			input: &dynamodb.UpdateItemInput{
+				TableName:                 aws.String(seatsTable),
+				Key:                       item{"seatID": str("f1-1A")},
+				UpdateExpression:          aws.String("SET #booking = if_not_exists(#booking, :booking), #v = if_not_exists(#v, :zero) + :one REMOVE passengerID, flightID"),
+				ExpressionAttributeNames:  map[string]string{"#booking": "bookingID", "#v": "version"},
+				ExpressionAttributeValues: item{":booking": str("b1"), ":zero": num("0"), ":one": num("1")},
+			},
+			want: &dynamodb.UpdateItemInput{
+				TableName:                 aws.String("test_Seats"),
+				Key:                       item{"seatID": str("acme#f1-1A")},
+				UpdateExpression:          aws.String("SET #booking = if_not_exists(#booking, :booking), #v = if_not_exists(#v, :zero) + :one REMOVE passengerID, flightID"),
+				ExpressionAttributeNames:  map[string]string{"#booking": "bookingID", "#v": "version"},
+				ExpressionAttributeValues: item{":booking": str("acme#b1"), ":zero": num("0"), ":one": num("1")},
+			},
+			table: seatsTable,
+		},
+		{
+			name: "BatchGetItem",
+			input: &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+				flightInventoryTable: {Keys: []map[string]types.AttributeValue{{"flightID": str("f1")}, {"flightID": str("f2")}}, ConsistentRead: aws.Bool(true)},
+			}},
+			want: &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+				"test_FlightInventory": {Keys: []map[string]types.AttributeValue{{"flightID": str("acme#f1")}, {"flightID": str("acme#f2")}}, ConsistentRead: aws.Bool(true)},
+			}},
+		},
+		{
+			name: "TransactWriteItems",
+			input: &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+				{Put: &types.Put{TableName: aws.String(bookingLocatorsTable), Item: item{"locator": str("ABC123"), "bookingID": str("b1")}}},
+				{Delete: &types.Delete{TableName: aws.String(seatHoldsTable), Key: item{"seatID": str("f1-1A")}}},
+				{Update: &types.Update{
+					TableName:                 aws.String(roomInventoryTable),
+					Key:                       item{"inventoryKey": str("h1#r1"), "date": str("2026-11-01")},
+					UpdateExpression:          aws.String("ADD sold :one"),
+					ExpressionAttributeValues: item{":one": num("1")},
+				}},
+				{ConditionCheck: &types.ConditionCheck{
+					TableName:                 aws.String(bookingsTable),
+					Key:                       item{"bookingID": str("b1")},
+					ConditionExpression:       aws.String("userID = :user"),
+					ExpressionAttributeValues: item{":user": str("u1")},
+				}},
+			}},
+			want: &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+				{Put: &types.Put{TableName: aws.String("test_BookingLocators"), Item: item{"locator": str("acme#ABC123"), "bookingID": str("b1")}}},
+				{Delete: &types.Delete{TableName: aws.String("test_SeatHolds"), Key: item{"seatID": str("acme#f1-1A")}}},
+				{Update: &types.Update{
+					TableName:                 aws.String("test_RoomInventory"),
+					Key:                       item{"inventoryKey": str("acme#h1#r1"), "date": str("2026-11-01")},
+					UpdateExpression:          aws.String("ADD sold :one"),
+					ExpressionAttributeValues: item{":one": num("1")},
+				}},
+				{ConditionCheck: &types.ConditionCheck{
+					TableName:                 aws.String("test_Bookings"),
+					Key:                       item{"bookingID": str("acme#b1")},
+					ConditionExpression:       aws.String("userID = :user"),
+					ExpressionAttributeValues: item{":user": str("acme#u1")},
+				}},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Scoping the same input twice must give the same result, as
+			// paginators send their input again for every page
+			for i := 0; i < 2; i++ {
+				got, table, err := testScope().scopeInput(test.input)
+				if err != nil {
+					t.Fatalf("scopeInput() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, test.want) {
+					t.Fatalf("scopeInput() call %d =\n%#v\nwant\n%#v", i+1, got, test.want)
+				}
+				if table != test.table {
+					t.Errorf("scopeInput() table = %q, want %q", table, test.table)
+				}
+			}
+		})
+	}
+}
+
+func TestScopeInputRefusesUnscopedCalls(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{name: "unknown table", input: &dynamodb.GetItemInput{TableName: aws.String("Users"), Key: item{"userID": str("u1")}}},
+		{name: "query without a partition", input: &dynamodb.QueryInput{
+			TableName:                 aws.String(bookingsTable),
+			KeyConditionExpression:    aws.String("begins_with(bookingStatus, :status)"),
+			ExpressionAttributeValues: item{":status": str("P")},
+		}},
+		{name: "scan of an index", input: &dynamodb.ScanInput{TableName: aws.String(bookingsTable), IndexName: aws.String(bookingsUserIDIndex)}},
+		{name: "transaction naming an unknown table", input: &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{TableName: aws.String(bookingsTable), Item: item{"bookingID": str("b1")}}},
+			{Put: &types.Put{TableName: aws.String("Users"), Item: item{"userID": str("u1")}}},
+		}}},
+		{name: "value shared by a key and another attribute", input: &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(seatsTable),
+			Key:                       item{"seatID": str("f1-1A")},
+			UpdateExpression:          aws.String("SET passengerID = :id"),
+			ConditionExpression:       aws.String("bookingID = :id"),
+			ExpressionAttributeValues: item{":id": str("b1")},
+		}},
+		{name: "key searched with contains", input: &dynamodb.ScanInput{
+			TableName:                 aws.String(bookingsTable),
+			FilterExpression:          aws.String("contains(userID, :user)"),
+			ExpressionAttributeValues: item{":user": str("u1")},
+		}},
+		{name: "key compared with another attribute", input: &dynamodb.ScanInput{
+			TableName:        aws.String(bookingsTable),
+			FilterExpression: aws.String("userID = createdBy"),
+		}},
+		{name: "key assigned from arithmetic", input: &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(seatsTable),
+			Key:                       item{"seatID": str("f1-1A")},
+			UpdateExpression:          aws.String("SET bookingID = :prefix + :id"),
+			ExpressionAttributeValues: item{":prefix": str("b"), ":id": str("1")},
+		}},
+		{name: "key assigned from another attribute with if_not_exists", input: &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(seatsTable),
+			Key:                       item{"seatID": str("f1-1A")},
+			UpdateExpression:          aws.String("SET bookingID = if_not_exists(passengerID, :booking)"),
+			ExpressionAttributeValues: item{":booking": str("b1")},
+		}},
+		{name: "key added to", input: &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(seatsTable),
+			Key:                       item{"seatID": str("f1-1A")},
+			UpdateExpression:          aws.String("ADD bookingID :booking"),
+			ExpressionAttributeValues: item{":booking": str("b1")},
+		}},
+		{name: "key matched IN another attribute", input: &dynamodb.ScanInput{
+			TableName:                 aws.String(bookingsTable),
+			FilterExpression:          aws.String("userID IN (:user, createdBy)"),
+			ExpressionAttributeValues: item{":user": str("u1")},
+		}},
+		{name: "unreadable expression", input: &dynamodb.ScanInput{
+			TableName:        aws.String(bookingsTable),
+			FilterExpression: aws.String("userID == :user"),
+		}},
+		{name: "batch naming an unknown table", input: &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{
+			"Users": {Keys: []map[string]types.AttributeValue{{"userID": str("u1")}}},
+		}}},
+		{name: "unknown operation", input: &dynamodb.ExecuteStatementInput{Statement: aws.String("SELECT * FROM Bookings")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := testScope().scopeInput(test.input); err == nil {
+				t.Fatal("scopeInput() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestUnscopeOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		output interface{}
+		want   interface{}
+	}{
+		{
+			name:   "GetItem",
+			table:  bookingsTable,
+			output: &dynamodb.GetItemOutput{Item: item{"bookingID": str("acme#b1"), "userID": str("acme#u1"), "locator": str("ABC123")}},
+			want:   &dynamodb.GetItemOutput{Item: item{"bookingID": str("b1"), "userID": str("u1"), "locator": str("ABC123")}},
+		},
+		{
+			name:   "PutItem",
+			table:  seatHoldsTable,
+			output: &dynamodb.PutItemOutput{Attributes: item{"seatID": str("acme#f1-1A"), "flightID": str("acme#f1")}},
+			want:   &dynamodb.PutItemOutput{Attributes: item{"seatID": str("f1-1A"), "flightID": str("f1")}},
+		},
+		{
+			name:   "UpdateItem",
+			table:  flightInventoryTable,
+			output: &dynamodb.UpdateItemOutput{Attributes: item{"flightID": str("acme#f1"), "seatsSold": num("3")}},
+			want:   &dynamodb.UpdateItemOutput{Attributes: item{"flightID": str("f1"), "seatsSold": num("3")}},
+		},
+		{
+			name:  "Query",
+			table: passengersTable,
+			output: &dynamodb.QueryOutput{
+				Items:            []map[string]types.AttributeValue{{"passengerID": str("acme#p1"), "bookingID": str("acme#b1"), "name": str("Ann Lee")}},
+				LastEvaluatedKey: item{"passengerID": str("acme#p1"), "bookingID": str("acme#b1")},
+			},
+			want: &dynamodb.QueryOutput{
+				Items:            []map[string]types.AttributeValue{{"passengerID": str("p1"), "bookingID": str("b1"), "name": str("Ann Lee")}},
+				LastEvaluatedKey: item{"passengerID": str("p1"), "bookingID": str("b1")},
+			},
+		},
+		{
+			name:  "Scan",
+			table: hotelsTable,
+			output: &dynamodb.ScanOutput{
+				Items:            []map[string]types.AttributeValue{{"hotelID": str("acme#h1"), "cityKey": str("acme#mumbai"), "name": str("acme#1")}},
+				LastEvaluatedKey: item{"hotelID": str("acme#h1")},
+			},
+			want: &dynamodb.ScanOutput{
+				Items:            []map[string]types.AttributeValue{{"hotelID": str("h1"), "cityKey": str("mumbai"), "name": str("acme#1")}},
+				LastEvaluatedKey: item{"hotelID": str("h1")},
+			},
+		},
+		{
+			name: "BatchGetItem",
+			output: &dynamodb.BatchGetItemOutput{
+				Responses:       map[string][]map[string]types.AttributeValue{"test_FlightInventory": {{"flightID": str("acme#f1"), "capacity": num("180")}}},
+				UnprocessedKeys: map[string]types.KeysAndAttributes{"test_FlightInventory": {Keys: []map[string]types.AttributeValue{{"flightID": str("acme#f2")}}}},
+			},
+			want: &dynamodb.BatchGetItemOutput{
+				Responses:       map[string][]map[string]types.AttributeValue{flightInventoryTable: {{"flightID": str("f1"), "capacity": num("180")}}},
+				UnprocessedKeys: map[string]types.KeysAndAttributes{flightInventoryTable: {Keys: []map[string]types.AttributeValue{{"flightID": str("f2")}}}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testScope().unscopeOutput(test.table, test.output)
+			if !reflect.DeepEqual(test.output, test.want) {
+				t.Errorf("unscopeOutput() =\n%#v\nwant\n%#v", test.output, test.want)
+			}
+		})
+	}
+}
+
+func TestFilterScan(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  dynamodb.ScanInput
+		values map[string]types.AttributeValue
+		want   dynamodb.ScanInput
+	}{
+		{
+			name:  "without a filter",
+			input: dynamodb.ScanInput{TableName: aws.String("test_Meals")},
+			want: dynamodb.ScanInput{
+				TableName:                 aws.String("test_Meals"),
+				FilterExpression:          aws.String("begins_with(#tenantKey, :tenantKey)"),
+				ExpressionAttributeNames:  map[string]string{"#tenantKey": "mealID"},
+				ExpressionAttributeValues: item{":tenantKey": str("acme#")},
+			},
+		},
+		{
+			name: "with a filter",
+			input: dynamodb.ScanInput{
+				TableName:                aws.String("test_Meals"),
+				FilterExpression:         aws.String("#type = :type OR price < :price"),
+				ExpressionAttributeNames: map[string]string{"#type": "type"},
+			},
+			values: item{":type": str("VEG"), ":price": num("500")},
+			want: dynamodb.ScanInput{
+				TableName:                 aws.String("test_Meals"),
+				FilterExpression:          aws.String("(#type = :type OR price < :price) AND begins_with(#tenantKey, :tenantKey)"),
+				ExpressionAttributeNames:  map[string]string{"#type": "type", "#tenantKey": "mealID"},
+				ExpressionAttributeValues: item{":type": str("VEG"), ":price": num("500"), ":tenantKey": str("acme#")},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testScope().filterScan(&test.input, "mealID", test.values)
+			if !reflect.DeepEqual(test.input, test.want) {
+				t.Errorf("filterScan() =\n%#v\nwant\n%#v", test.input, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,734 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expression is a condition, key condition, filter or update expression
+// together with the placeholders of its request
+type expression struct {
+	text   string
+	names  map[string]string
+	values map[string]interface{}
+}
+
+// conditionOf returns the expression held by field of the request, or nil
+// when the request has none
+func conditionOf(request map[string]interface{}, field string) *expression {
+	text, _ := request[field].(string)
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	e := &expression{text: text, names: map[string]string{}, values: map[string]interface{}{}}
+	for name, value := range asItem(request["ExpressionAttributeNames"]) {
+		e.names[name], _ = value.(string)
+	}
+	for name, value := range asItem(request["ExpressionAttributeValues"]) {
+		e.values[name] = value
+	}
+	return e
+}
+
+// holds reports whether the condition is true of the item, which is nil when
+// there is no such item
+func (e *expression) holds(subject item) (bool, error) {
+	p, err := e.parser()
+	if err != nil {
+		return false, err
+	}
+	condition, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if !p.done() {
+		return false, validation("unexpected %q in %q", p.peek(), e.text)
+	}
+	return condition(subject)
+}
+
+// update applies the SET, REMOVE and ADD clauses of an update expression
+func (e *expression) update(subject item) error {
+	p, err := e.parser()
+	if err != nil {
+		return err
+	}
+	// Every value is worked out from the item as it was before the update
+	before := copyItem(subject)
+	for !p.done() {
+		clause := strings.ToUpper(p.next())
+		for {
+			name, err := p.topLevelName()
+			if err != nil {
+				return err
+			}
+			switch clause {
+			case "SET":
+				if err := p.expect("="); err != nil {
+					return err
+				}
+				value, err := p.setValue()
+				if err != nil {
+					return err
+				}
+				v, err := value(before)
+				if err != nil {
+					return err
+				}
+				subject[name] = v
+			case "REMOVE":
+				delete(subject, name)
+			case "ADD":
+				operand, err := p.operand()
+				if err != nil {
+					return err
+				}
+				added, err := add(subject[name], operand(before))
+				if err != nil {
+					return err
+				}
+				subject[name] = added
+			default:
+				return validation("update clause %s is not supported", clause)
+			}
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+	}
+	return nil
+}
+
+type parser struct {
+	e      *expression
+	tokens []string
+	pos    int
+}
+
+func (e *expression) parser() (*parser, error) {
+	tokens, err := tokenize(e.text)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{e: e, tokens: tokens}, nil
+}
+
+func tokenize(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := rune(text[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("(),.[]=+-", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '<' || c == '>':
+			if i+1 < len(text) && (text[i+1] == '=' || (c == '<' && text[i+1] == '>')) {
+				tokens = append(tokens, text[i:i+2])
+				i += 2
+			} else {
+				tokens = append(tokens, string(c))
+				i++
+			}
+		case c == '#' || c == ':' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(text) && (text[j] == '_' || unicode.IsLetter(rune(text[j])) || unicode.IsDigit(rune(text[j]))) {
+				j++
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		default:
+			return nil, validation("unexpected %q in expression", c)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *parser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *parser) keyword(word string) bool {
+	if strings.EqualFold(p.peek(), word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(token string) error {
+	if got := p.next(); got != token {
+		return validation("expected %q but found %q in %q", token, got, p.e.text)
+	}
+	return nil
+}
+
+type condition func(item) (bool, error)
+
+// operand gives an attribute value, or nil when the path names no attribute
+type operand func(item) interface{}
+
+func (p *parser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = either(left, right)
+	}
+	return left, nil
+}
+
+func either(left, right condition) condition {
+	return func(subject item) (bool, error) {
+		ok, err := left(subject)
+		if err != nil || ok {
+			return ok, err
+		}
+		return right(subject)
+	}
+}
+
+func (p *parser) and() (condition, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = both(left, right)
+	}
+	return left, nil
+}
+
+func both(left, right condition) condition {
+	return func(subject item) (bool, error) {
+		ok, err := left(subject)
+		if err != nil || !ok {
+			return ok, err
+		}
+		return right(subject)
+	}
+}
+
+func (p *parser) not() (condition, error) {
+	if !p.keyword("NOT") {
+		return p.primary()
+	}
+	negated, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	return func(subject item) (bool, error) {
+		ok, err := negated(subject)
+		return !ok, err
+	}, nil
+}
+
+func (p *parser) primary() (condition, error) {
+	if p.peek() == "(" {
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	}
+
+	switch function := strings.ToLower(p.peek()); function {
+	case "attribute_exists", "attribute_not_exists", "begins_with", "contains":
+		p.next()
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		return p.function(function, args)
+	}
+
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.keyword("BETWEEN") {
+		low, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("AND") {
+			return nil, validation("BETWEEN without AND in %q", p.e.text)
+		}
+		high, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		return func(subject item) (bool, error) {
+			value := left(subject)
+			return compare(value, ">=", low(subject)) && compare(value, "<=", high(subject)), nil
+		}, nil
+	}
+	if p.keyword("IN") {
+		candidates, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		return func(subject item) (bool, error) {
+			value := left(subject)
+			for _, candidate := range candidates {
+				if compare(value, "=", candidate(subject)) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, nil
+	}
+
+	comparator := p.next()
+	switch comparator {
+	case "=", "<>", "<", "<=", ">", ">=":
+	default:
+		return nil, validation("expected a comparison but found %q in %q", comparator, p.e.text)
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(subject item) (bool, error) {
+		return compare(left(subject), comparator, right(subject)), nil
+	}, nil
+}
+
+func (p *parser) arguments() ([]operand, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []operand
+	for {
+		arg, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	return args, p.expect(")")
+}
+
+func (p *parser) function(name string, args []operand) (condition, error) {
+	want := 2
+	if name == "attribute_exists" || name == "attribute_not_exists" {
+		want = 1
+	}
+	if len(args) != want {
+		return nil, validation("%s takes %d arguments", name, want)
+	}
+	return func(subject item) (bool, error) {
+		switch name {
+		case "attribute_exists":
+			return args[0](subject) != nil, nil
+		case "attribute_not_exists":
+			return args[0](subject) == nil, nil
+		case "begins_with":
+			value, _ := asItem(args[0](subject))["S"].(string)
+			prefix, _ := asItem(args[1](subject))["S"].(string)
+			return args[0](subject) != nil && strings.HasPrefix(value, prefix), nil
+		default:
+			return contains(args[0](subject), args[1](subject)), nil
+		}
+	}, nil
+}
+
+// operand parses a value placeholder, a size() call or an attribute path
+func (p *parser) operand() (operand, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, validation("expression %q ends early", p.e.text)
+	case strings.HasPrefix(token, ":"):
+		value, ok := p.e.values[token]
+		if !ok {
+			return nil, validation("value %s is not defined", token)
+		}
+		return func(item) interface{} { return value }, nil
+	case strings.EqualFold(token, "size") && p.peek() == "(":
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) != 1 {
+			return nil, validation("size takes 1 argument")
+		}
+		return func(subject item) interface{} {
+			n, ok := size(args[0](subject))
+			if !ok {
+				return nil
+			}
+			return map[string]interface{}{"N": strconv.Itoa(n)}
+		}, nil
+	}
+
+	first, err := p.name(token)
+	if err != nil {
+		return nil, err
+	}
+	steps := []interface{}{first}
+	for {
+		switch p.peek() {
+		case ".":
+			p.next()
+			name, err := p.name(p.next())
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, name)
+			continue
+		case "[":
+			p.next()
+			index, err := strconv.Atoi(p.next())
+			if err != nil {
+				return nil, validation("bad list index in %q", p.e.text)
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			steps = append(steps, index)
+			continue
+		}
+		break
+	}
+	return func(subject item) interface{} {
+		var value interface{} = map[string]interface{}{"M": map[string]interface{}(subject)}
+		for _, step := range steps {
+			switch step := step.(type) {
+			case string:
+				members := asItem(asItem(value)["M"])
+				if members == nil {
+					return nil
+				}
+				value = members[step]
+			case int:
+				list, _ := asItem(value)["L"].([]interface{})
+				if step >= len(list) {
+					return nil
+				}
+				value = list[step]
+			}
+			if value == nil {
+				return nil
+			}
+		}
+		return value
+	}, nil
+}
+
+// name resolves a #placeholder or returns a plain attribute name
+func (p *parser) name(token string) (string, error) {
+	if strings.HasPrefix(token, "#") {
+		name, ok := p.e.names[token]
+		if !ok {
+			return "", validation("name %s is not defined", token)
+		}
+		return name, nil
+	}
+	if token == "" || strings.HasPrefix(token, ":") || strings.ContainsAny(token, "()[],.=<>+-") {
+		return "", validation("expected an attribute name but found %q in %q", token, p.e.text)
+	}
+	return token, nil
+}
+
+// topLevelName parses the target of an update action, which must be an
+// attribute of the item itself
+func (p *parser) topLevelName() (string, error) {
+	name, err := p.name(p.next())
+	if err != nil {
+		return "", err
+	}
+	if p.peek() == "." || p.peek() == "[" {
+		return "", validation("updating nested attributes is not supported")
+	}
+	return name, nil
+}
+
+// setValue parses the value of a SET action: an operand, if_not_exists or
+// list_append, optionally followed by + or - and another
+func (p *parser) setValue() (func(item) (interface{}, error), error) {
+	left, err := p.setOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != "+" && p.peek() != "-" {
+		return left, nil
+	}
+	sign := p.next()
+	right, err := p.setOperand()
+	if err != nil {
+		return nil, err
+	}
+	return func(subject item) (interface{}, error) {
+		a, err := left(subject)
+		if err != nil {
+			return nil, err
+		}
+		b, err := right(subject)
+		if err != nil {
+			return nil, err
+		}
+		x, okA := number(a)
+		y, okB := number(b)
+		if !okA || !okB {
+			return nil, validation("%s needs two numbers", sign)
+		}
+		if sign == "+" {
+			return numberValue(new(big.Float).Add(x, y)), nil
+		}
+		return numberValue(new(big.Float).Sub(x, y)), nil
+	}, nil
+}
+
+func (p *parser) setOperand() (func(item) (interface{}, error), error) {
+	switch function := strings.ToLower(p.peek()); function {
+	case "if_not_exists", "list_append":
+		p.next()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		// The path if_not_exists looks at may well be missing
+		var first func(item) (interface{}, error)
+		if function == "if_not_exists" {
+			path, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			first = func(subject item) (interface{}, error) { return path(subject), nil }
+		} else {
+			var err error
+			if first, err = p.setOperand(); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		second, err := p.setOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(subject item) (interface{}, error) {
+			a, err := first(subject)
+			if err != nil {
+				return nil, err
+			}
+			if function == "if_not_exists" && a != nil {
+				return a, nil
+			}
+			b, err := second(subject)
+			if err != nil || function == "if_not_exists" {
+				return b, err
+			}
+			listA, okA := asItem(a)["L"].([]interface{})
+			listB, okB := asItem(b)["L"].([]interface{})
+			if !okA || !okB {
+				return nil, validation("list_append needs two lists")
+			}
+			joined := append(append([]interface{}{}, listA...), listB...)
+			return map[string]interface{}{"L": joined}, nil
+		}, nil
+	}
+	value, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(subject item) (interface{}, error) {
+		v := value(subject)
+		if v == nil {
+			return nil, validation("the expression refers to an attribute that does not exist")
+		}
+		return copyValue(v), nil
+	}, nil
+}
+
+// compare applies a comparison to two attribute values. A missing value is
+// only unequal to anything.
+func compare(left interface{}, comparator string, right interface{}) bool {
+	if left == nil || right == nil {
+		return comparator == "<>" && (left != nil || right != nil)
+	}
+	var order int
+	if x, ok := number(left); ok {
+		y, ok := number(right)
+		if !ok {
+			return comparator == "<>"
+		}
+		order = x.Cmp(y)
+	} else if x, ok := asItem(left)["S"].(string); ok {
+		y, ok := asItem(right)["S"].(string)
+		if !ok {
+			return comparator == "<>"
+		}
+		order = strings.Compare(x, y)
+	} else {
+		equal := canonical(left) == canonical(right)
+		switch comparator {
+		case "=":
+			return equal
+		case "<>":
+			return !equal
+		}
+		return false
+	}
+	switch comparator {
+	case "=":
+		return order == 0
+	case "<>":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	}
+	return order >= 0
+}
+
+// contains reports whether a string holds a substring, or a list or set holds
+// a member
+func contains(container, member interface{}) bool {
+	c, m := asItem(container), asItem(member)
+	if c == nil || m == nil {
+		return false
+	}
+	if text, ok := c["S"].(string); ok {
+		sub, _ := m["S"].(string)
+		return m["S"] != nil && strings.Contains(text, sub)
+	}
+	if list, ok := c["L"].([]interface{}); ok {
+		for _, element := range list {
+			if canonical(element) == canonical(member) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, set := range []string{"SS", "NS", "BS"} {
+		elements, ok := c[set].([]interface{})
+		if !ok {
+			continue
+		}
+		kind := set[:1]
+		for _, element := range elements {
+			if compare(map[string]interface{}{kind: element}, "=", member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func size(value interface{}) (int, bool) {
+	v := asItem(value)
+	switch {
+	case v["S"] != nil:
+		s, _ := v["S"].(string)
+		return len(s), true
+	case v["L"] != nil:
+		l, _ := v["L"].([]interface{})
+		return len(l), true
+	case v["M"] != nil:
+		return len(asItem(v["M"])), true
+	}
+	for _, set := range []string{"SS", "NS", "BS"} {
+		if elements, ok := v[set].([]interface{}); ok {
+			return len(elements), true
+		}
+	}
+	return 0, false
+}
+
+// add implements ADD: numbers are summed and sets joined
+func add(current, operand interface{}) (interface{}, error) {
+	if y, ok := number(operand); ok {
+		x := new(big.Float)
+		if current != nil {
+			if x, ok = number(current); !ok {
+				return nil, validation("ADD of a number to a non-number")
+			}
+		}
+		return numberValue(new(big.Float).Add(x, y)), nil
+	}
+	for _, set := range []string{"SS", "NS", "BS"} {
+		added, ok := asItem(operand)[set].([]interface{})
+		if !ok {
+			continue
+		}
+		var members []interface{}
+		if current != nil {
+			if members, ok = asItem(current)[set].([]interface{}); !ok {
+				return nil, validation("ADD of a set to an attribute of another type")
+			}
+		}
+		joined := append([]interface{}{}, members...)
+		for _, member := range added {
+			seen := false
+			for _, existing := range joined {
+				seen = seen || existing == member
+			}
+			if !seen {
+				joined = append(joined, member)
+			}
+		}
+		return map[string]interface{}{set: joined}, nil
+	}
+	return nil, validation("ADD needs a number or a set")
+}
+
+func number(value interface{}) (*big.Float, bool) {
+	text, ok := asItem(value)["N"].(string)
+	if !ok {
+		return nil, false
+	}
+	n, ok := new(big.Float).SetString(text)
+	return n, ok
+}
+
+func numberValue(n *big.Float) map[string]interface{} {
+	return map[string]interface{}{"N": n.Text('f', -1)}
+}
+
+// canonical renders a value so that equal values render the same
+func canonical(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
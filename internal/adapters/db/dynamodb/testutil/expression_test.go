@@ -0,0 +1,93 @@
+package testutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func s(value string) map[string]interface{} { return map[string]interface{}{"S": value} }
+
+func n(value string) map[string]interface{} { return map[string]interface{}{"N": value} }
+
+func TestExpressionHolds(t *testing.T) {
+	subject := item{
+		"flightID": s("acme#f1"),
+		"seats":    n("12"),
+		"address":  map[string]interface{}{"M": map[string]interface{}{"country": s("IN")}},
+		"classes":  map[string]interface{}{"SS": []interface{}{"economy", "business"}},
+		"history":  map[string]interface{}{"L": []interface{}{s("PENDING"), s("CONFIRMED")}},
+	}
+	values := map[string]interface{}{
+		":id": s("acme#f1"), ":prefix": s("acme#"), ":other": s("globex#"),
+		":ten": n("10"), ":twenty": n("20"), ":nine": n("9"),
+		":class": s("economy"), ":country": s("IN"), ":status": s("CONFIRMED"),
+	}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"flightID = :id", true},
+		{"flightID <> :id", false},
+		{"seats > :ten AND seats <= :twenty", true},
+		{"seats < :nine OR seats >= :twenty", false},
+		{"seats BETWEEN :ten AND :twenty", true},
+		{"seats IN (:nine, :ten)", false},
+		{"begins_with(flightID, :prefix)", true},
+		{"begins_with(flightID, :other)", false},
+		{"contains(classes, :class)", true},
+		{"#address.#country = :country", true},
+		{"history[1] = :status", true},
+		{"size(history) = :ten", false},
+		{"attribute_exists(seats) AND attribute_not_exists(bookingID)", true},
+		{"NOT (attribute_exists(bookingID) OR seats < :ten)", true},
+		{"missing <> :ten", true},
+		{"missing = :ten", false},
+	}
+	for _, test := range tests {
+		e := &expression{text: test.text, names: map[string]string{"#address": "address", "#country": "country"}, values: values}
+		got, err := e.holds(subject)
+		if err != nil {
+			t.Errorf("holds(%q) error = %v", test.text, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("holds(%q) = %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestExpressionUpdate(t *testing.T) {
+	subject := item{"id": s("b1"), "version": n("2"), "sold": n("5"), "passengerID": s("p1")}
+	e := &expression{
+		text:  "SET #v = if_not_exists(#v, :zero) + :one, history = list_append(if_not_exists(history, :empty), :change), #sold = #sold - :one ADD seatsSold :one REMOVE passengerID",
+		names: map[string]string{"#v": "version", "#sold": "sold"},
+		values: map[string]interface{}{
+			":zero": n("0"), ":one": n("1"),
+			":empty":  map[string]interface{}{"L": []interface{}{}},
+			":change": map[string]interface{}{"L": []interface{}{s("CONFIRMED")}},
+		},
+	}
+	if err := e.update(subject); err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	want := item{
+		"id":        s("b1"),
+		"version":   n("3"),
+		"sold":      n("4"),
+		"seatsSold": n("1"),
+		"history":   map[string]interface{}{"L": []interface{}{s("CONFIRMED")}},
+	}
+	if !reflect.DeepEqual(subject, want) {
+		t.Errorf("update() = %v, want %v", subject, want)
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	for _, text := range []string{"flightID = :undefined", "#undefined = :id", "flightID =", "flightID = :id extra"} {
+		e := &expression{text: text, names: map[string]string{}, values: map[string]interface{}{":id": s("f1")}}
+		if _, err := e.holds(item{}); err == nil {
+			t.Errorf("holds(%q) error = nil, want a validation error", text)
+		}
+	}
+}
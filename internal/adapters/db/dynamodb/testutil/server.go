@@ -0,0 +1,593 @@
+// Package testutil is only for tests. It runs an in-memory stand-in for
+// DynamoDB that speaks its JSON protocol, so that tests can drive the DynamoDB
+// repositories through a real *dynamodb.Client without AWS or DynamoDB Local.
+// It supports the operations and the parts of the expression language the
+// repositories use, and no more. Queries of an index read every item of the
+// table that matches the key condition, in primary key order.
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Server is a DynamoDB endpoint serving tables kept in memory
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+// item is an item or key as written in the JSON protocol: attribute names
+// mapped to attribute values such as {"S": "abc"}
+type item map[string]interface{}
+
+type table struct {
+	partitionKey string
+	sortKey      string
+	items        map[string]item
+}
+
+// NewServer starts a server without tables. Close it when done.
+func NewServer() *Server {
+	s := &Server{tables: make(map[string]*table)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// CreateTable adds an empty table keyed by partitionKey and, unless empty,
+// sortKey
+func (s *Server) CreateTable(name, partitionKey, sortKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[name] = &table{partitionKey: partitionKey, sortKey: sortKey, items: make(map[string]item)}
+}
+
+// Client returns a client that sends its calls to the server
+func (s *Server) Client() *dynamodb.Client {
+	return dynamodb.New(dynamodb.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(s.URL),
+		Credentials:      credentials.NewStaticCredentialsProvider("test", "test", ""),
+		RetryMaxAttempts: 1,
+	})
+}
+
+// Items returns a copy of the items of a table, in primary key order
+func (s *Server) Items(tableName string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tables[tableName]
+	if t == nil {
+		return nil
+	}
+	var items []map[string]interface{}
+	for _, stored := range t.sorted() {
+		items = append(items, copyItem(stored))
+	}
+	return items
+}
+
+// apiError is an error returned to the client as the DynamoDB exception named
+// by kind
+type apiError struct {
+	kind    string
+	message string
+	extra   map[string]interface{}
+}
+
+func (e *apiError) Error() string { return e.kind + ": " + e.message }
+
+func validation(format string, args ...interface{}) error {
+	return &apiError{kind: "ValidationException", message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	var request map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.respond(w, nil, validation("malformed request: %v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var response interface{}
+	var err error
+	switch operation {
+	case "GetItem":
+		response, err = s.getItem(request)
+	case "PutItem":
+		response, err = s.putItem(request)
+	case "DeleteItem":
+		response, err = s.deleteItem(request)
+	case "UpdateItem":
+		response, err = s.updateItem(request)
+	case "Query":
+		response, err = s.query(request)
+	case "Scan":
+		response, err = s.scan(request)
+	case "TransactWriteItems":
+		response, err = s.transactWriteItems(request)
+	case "BatchWriteItem":
+		response, err = s.batchWriteItem(request)
+	case "BatchGetItem":
+		response, err = s.batchGetItem(request)
+	case "ListTables":
+		names := make([]string, 0, len(s.tables))
+		for name := range s.tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		response = map[string]interface{}{"TableNames": names}
+	default:
+		err = validation("operation %q is not supported", operation)
+	}
+	s.respond(w, response, err)
+}
+
+func (s *Server) respond(w http.ResponseWriter, response interface{}, err error) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if err != nil {
+		apiErr, ok := err.(*apiError)
+		if !ok {
+			apiErr = &apiError{kind: "ValidationException", message: err.Error()}
+		}
+		body := map[string]interface{}{
+			"__type":  "com.amazonaws.dynamodb.v20120810#" + apiErr.kind,
+			"message": apiErr.message,
+		}
+		for name, value := range apiErr.extra {
+			body[name] = value
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(body)
+		return
+	}
+	if response == nil {
+		response = map[string]interface{}{}
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) table(request map[string]interface{}) (*table, error) {
+	name, _ := request["TableName"].(string)
+	t := s.tables[name]
+	if t == nil {
+		return nil, &apiError{kind: "ResourceNotFoundException", message: fmt.Sprintf("table %q does not exist", name)}
+	}
+	return t, nil
+}
+
+func (s *Server) getItem(request map[string]interface{}) (interface{}, error) {
+	t, err := s.table(request)
+	if err != nil {
+		return nil, err
+	}
+	id, err := t.id(asItem(request["Key"]))
+	if err != nil {
+		return nil, err
+	}
+	if stored, ok := t.items[id]; ok {
+		return map[string]interface{}{"Item": copyItem(stored)}, nil
+	}
+	return nil, nil
+}
+
+// write is one change to an item: its condition is checked against the
+// stored item, then apply gives the new item, or nil to delete it
+type write struct {
+	table     *table
+	id        string
+	condition *expression
+	apply     func(old item) (item, error)
+}
+
+func (s *Server) putWrite(request map[string]interface{}) (*write, error) {
+	t, err := s.table(request)
+	if err != nil {
+		return nil, err
+	}
+	newItem := asItem(request["Item"])
+	id, err := t.id(newItem)
+	if err != nil {
+		return nil, err
+	}
+	return &write{table: t, id: id, condition: conditionOf(request, "ConditionExpression"), apply: func(item) (item, error) {
+		return copyItem(newItem), nil
+	}}, nil
+}
+
+func (s *Server) deleteWrite(request map[string]interface{}) (*write, error) {
+	t, err := s.table(request)
+	if err != nil {
+		return nil, err
+	}
+	id, err := t.id(asItem(request["Key"]))
+	if err != nil {
+		return nil, err
+	}
+	return &write{table: t, id: id, condition: conditionOf(request, "ConditionExpression"), apply: func(item) (item, error) {
+		return nil, nil
+	}}, nil
+}
+
+func (s *Server) updateWrite(request map[string]interface{}) (*write, error) {
+	t, err := s.table(request)
+	if err != nil {
+		return nil, err
+	}
+	key := asItem(request["Key"])
+	id, err := t.id(key)
+	if err != nil {
+		return nil, err
+	}
+	update := conditionOf(request, "UpdateExpression")
+	return &write{table: t, id: id, condition: conditionOf(request, "ConditionExpression"), apply: func(old item) (item, error) {
+		updated := copyItem(old)
+		if updated == nil {
+			updated = copyItem(key)
+		}
+		if update != nil {
+			if err := update.update(updated); err != nil {
+				return nil, err
+			}
+		}
+		return updated, nil
+	}}, nil
+}
+
+// run checks the condition of w and applies it, returning the item as it was
+// before
+func (w *write) run(returnOldOnFailure bool) (old item, err error) {
+	old = w.table.items[w.id]
+	if w.condition != nil {
+		ok, err := w.condition.holds(old)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			failure := &apiError{kind: "ConditionalCheckFailedException", message: "The conditional request failed"}
+			if returnOldOnFailure && old != nil {
+				failure.extra = map[string]interface{}{"Item": copyItem(old)}
+			}
+			return nil, failure
+		}
+	}
+	updated, err := w.apply(old)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		delete(w.table.items, w.id)
+	} else {
+		w.table.items[w.id] = updated
+	}
+	return old, nil
+}
+
+func (s *Server) putItem(request map[string]interface{}) (interface{}, error) {
+	return s.runWrite(request, s.putWrite)
+}
+
+func (s *Server) deleteItem(request map[string]interface{}) (interface{}, error) {
+	return s.runWrite(request, s.deleteWrite)
+}
+
+func (s *Server) updateItem(request map[string]interface{}) (interface{}, error) {
+	return s.runWrite(request, s.updateWrite)
+}
+
+func (s *Server) runWrite(request map[string]interface{}, build func(map[string]interface{}) (*write, error)) (interface{}, error) {
+	w, err := build(request)
+	if err != nil {
+		return nil, err
+	}
+	old, err := w.run(request["ReturnValuesOnConditionCheckFailure"] == "ALL_OLD")
+	if err != nil {
+		return nil, err
+	}
+	switch request["ReturnValues"] {
+	case "ALL_OLD":
+		if old != nil {
+			return map[string]interface{}{"Attributes": copyItem(old)}, nil
+		}
+	case "ALL_NEW", "UPDATED_NEW":
+		if updated := w.table.items[w.id]; updated != nil {
+			return map[string]interface{}{"Attributes": copyItem(updated)}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) transactWriteItems(request map[string]interface{}) (interface{}, error) {
+	entries, _ := request["TransactItems"].([]interface{})
+	writes := make([]*write, len(entries))
+	for i, entry := range entries {
+		actions := asItem(entry)
+		var err error
+		switch {
+		case actions["Put"] != nil:
+			writes[i], err = s.putWrite(asItem(actions["Put"]))
+		case actions["Delete"] != nil:
+			writes[i], err = s.deleteWrite(asItem(actions["Delete"]))
+		case actions["Update"] != nil:
+			writes[i], err = s.updateWrite(asItem(actions["Update"]))
+		case actions["ConditionCheck"] != nil:
+			check := asItem(actions["ConditionCheck"])
+			writes[i], err = s.deleteWrite(check)
+			if err == nil {
+				writes[i].apply = func(old item) (item, error) { return old, nil }
+			}
+		default:
+			err = validation("transaction item %d has no action", i)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Every condition is checked before anything is written
+	reasons := make([]interface{}, len(writes))
+	canceled := false
+	for i, w := range writes {
+		reasons[i] = map[string]interface{}{"Code": "None"}
+		if w.condition == nil {
+			continue
+		}
+		ok, err := w.condition.holds(w.table.items[w.id])
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			reasons[i] = map[string]interface{}{"Code": "ConditionalCheckFailed", "Message": "The conditional request failed"}
+			canceled = true
+		}
+	}
+	if canceled {
+		return nil, &apiError{
+			kind:    "TransactionCanceledException",
+			message: "Transaction cancelled, please refer cancellation reasons for specific reasons",
+			extra:   map[string]interface{}{"CancellationReasons": reasons},
+		}
+	}
+	for _, w := range writes {
+		w.condition = nil
+		if _, err := w.run(false); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) batchWriteItem(request map[string]interface{}) (interface{}, error) {
+	for name, requests := range asItem(request["RequestItems"]) {
+		list, _ := requests.([]interface{})
+		for _, entry := range list {
+			request := asItem(entry)
+			var w *write
+			var err error
+			switch {
+			case request["PutRequest"] != nil:
+				put := asItem(request["PutRequest"])
+				w, err = s.putWrite(map[string]interface{}{"TableName": name, "Item": put["Item"]})
+			case request["DeleteRequest"] != nil:
+				del := asItem(request["DeleteRequest"])
+				w, err = s.deleteWrite(map[string]interface{}{"TableName": name, "Key": del["Key"]})
+			}
+			if err != nil {
+				return nil, err
+			}
+			if w != nil {
+				if _, err := w.run(false); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return map[string]interface{}{"UnprocessedItems": map[string]interface{}{}}, nil
+}
+
+func (s *Server) batchGetItem(request map[string]interface{}) (interface{}, error) {
+	responses := make(map[string]interface{})
+	count := 0
+	for name, requested := range asItem(request["RequestItems"]) {
+		keys, _ := asItem(requested)["Keys"].([]interface{})
+		found := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			if count++; count > 100 {
+				return nil, validation("too many items requested for the BatchGetItem call")
+			}
+			response, err := s.getItem(map[string]interface{}{"TableName": name, "Key": key})
+			if err != nil {
+				return nil, err
+			}
+			if response != nil {
+				found = append(found, response.(map[string]interface{})["Item"])
+			}
+		}
+		responses[name] = found
+	}
+	return map[string]interface{}{"Responses": responses, "UnprocessedKeys": map[string]interface{}{}}, nil
+}
+
+func (s *Server) query(request map[string]interface{}) (interface{}, error) {
+	keyCondition := conditionOf(request, "KeyConditionExpression")
+	if keyCondition == nil {
+		return nil, validation("query needs a key condition")
+	}
+	return s.read(request, keyCondition)
+}
+
+func (s *Server) scan(request map[string]interface{}) (interface{}, error) {
+	return s.read(request, nil)
+}
+
+// read serves a query, when keyCondition is set, or a scan, paging through the
+// matching items by Limit and ExclusiveStartKey before applying the filter
+func (s *Server) read(request map[string]interface{}, keyCondition *expression) (interface{}, error) {
+	t, err := s.table(request)
+	if err != nil {
+		return nil, err
+	}
+	filter := conditionOf(request, "FilterExpression")
+
+	var matches []item
+	for _, stored := range t.sorted() {
+		if keyCondition != nil {
+			ok, err := keyCondition.holds(stored)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		matches = append(matches, stored)
+	}
+	if forward, ok := request["ScanIndexForward"].(bool); ok && !forward {
+		for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+			matches[i], matches[j] = matches[j], matches[i]
+		}
+	}
+
+	if start := asItem(request["ExclusiveStartKey"]); start != nil {
+		startID, err := t.id(start)
+		if err != nil {
+			return nil, err
+		}
+		for i, match := range matches {
+			if id, _ := t.id(match); id == startID {
+				matches = matches[i+1:]
+				break
+			}
+		}
+	}
+
+	response := map[string]interface{}{}
+	if limit, ok := request["Limit"].(float64); ok && int(limit) < len(matches) {
+		matches = matches[:int(limit)]
+		response["LastEvaluatedKey"] = t.key(matches[len(matches)-1])
+	}
+
+	items := []interface{}{}
+	for _, match := range matches {
+		if filter != nil {
+			ok, err := filter.holds(match)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		items = append(items, copyItem(match))
+	}
+	response["Items"] = items
+	response["Count"] = len(items)
+	response["ScannedCount"] = len(matches)
+	return response, nil
+}
+
+// id identifies an item of the table by the values of its key attributes
+func (t *table) id(key item) (string, error) {
+	id, err := keyValue(key, t.partitionKey)
+	if err != nil {
+		return "", err
+	}
+	if t.sortKey != "" {
+		sortValue, err := keyValue(key, t.sortKey)
+		if err != nil {
+			return "", err
+		}
+		id += "\x00" + sortValue
+	}
+	return id, nil
+}
+
+func keyValue(key item, name string) (string, error) {
+	value := asItem(key[name])
+	for _, kind := range []string{"S", "N", "B"} {
+		if text, ok := value[kind].(string); ok {
+			return kind + text, nil
+		}
+	}
+	return "", validation("key attribute %s is missing", name)
+}
+
+// key returns the primary key of a stored item
+func (t *table) key(stored item) item {
+	key := item{t.partitionKey: stored[t.partitionKey]}
+	if t.sortKey != "" {
+		key[t.sortKey] = stored[t.sortKey]
+	}
+	return key
+}
+
+// sorted lists the items of the table in primary key order
+func (t *table) sorted() []item {
+	ids := make([]string, 0, len(t.items))
+	for id := range t.items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	items := make([]item, len(ids))
+	for i, id := range ids {
+		items[i] = t.items[id]
+	}
+	return items
+}
+
+func asItem(value interface{}) item {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v
+	case item:
+		return v
+	}
+	return nil
+}
+
+// copyItem copies an item deeply enough that changing attributes of the copy
+// leaves the original alone
+func copyItem(original item) item {
+	if original == nil {
+		return nil
+	}
+	copied := make(item, len(original))
+	for name, value := range original {
+		copied[name] = copyValue(value)
+	}
+	return copied
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for name, member := range v {
+			copied[name] = copyValue(member)
+		}
+		return copied
+	case item:
+		return copyValue(map[string]interface{}(v))
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, member := range v {
+			copied[i] = copyValue(member)
+		}
+		return copied
+	}
+	return value
+}
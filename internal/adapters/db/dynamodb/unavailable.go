@@ -0,0 +1,30 @@
+package dynamodb
+
+import (
+	"context"
+	"travel-backend/internal/core/domain/models"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// codeDatabaseUnavailable is the error code of requests that failed because
+// DynamoDB could not be reached or kept throttling
+const codeDatabaseUnavailable = "database_unavailable"
+
+// unavailableErrors marks the errors of every DynamoDB call that still fails
+// with a retryable error, such as throttling or a dropped connection, once the
+// SDK has run out of retries as models.Unavailable, so that clients are told
+// to come back later instead of seeing an internal error. Other errors, like
+// failed conditions, pass through unchanged.
+func unavailableErrors(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("UnavailableErrors",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if err != nil && retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary {
+				err = models.Unavailable(codeDatabaseUnavailable, err)
+			}
+			return out, metadata, err
+		}), middleware.Before)
+}
@@ -0,0 +1,130 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// errItemNotFound is returned by the versioned writes when the item is gone;
+// callers turn it into their own "not found" error
+var errItemNotFound = models.NotFound("item")
+
+// versionCondition builds the condition that the item identified by keyName
+// exists and still has the expected version, with the attribute names and
+// values it refers to. Items written before versioning have no version
+// attribute and count as version 0.
+func versionCondition(keyName string, expected int64) (string, map[string]string, map[string]types.AttributeValue) {
+	names := map[string]string{"#version": "version"}
+	if expected == 0 {
+		return "attribute_exists(" + keyName + ") AND attribute_not_exists(#version)", names, nil
+	}
+	return "#version = :expected", names, map[string]types.AttributeValue{
+		":expected": &types.AttributeValueMemberN{Value: strconv.FormatInt(expected, 10)},
+	}
+}
+
+// putVersioned replaces an item, provided the stored copy still has the
+// expected version. item must already carry the new version. It fails with
+// db.ErrVersionConflict if the item changed and errItemNotFound if it is gone.
+func putVersioned(client *dynamodb.Client, table, keyName string, item map[string]types.AttributeValue, expected int64) error {
+	condition, names, values := versionCondition(keyName, expected)
+	_, err := client.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName:                           aws.String(table),
+		Item:                                item,
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	return versionError(err)
+}
+
+// deleteVersioned deletes an item, provided the stored copy still has the
+// expected version, failing like putVersioned
+func deleteVersioned(client *dynamodb.Client, table, keyName string, key map[string]types.AttributeValue, expected int64) (map[string]types.AttributeValue, error) {
+	condition, names, values := versionCondition(keyName, expected)
+	result, err := client.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName:                           aws.String(table),
+		Key:                                 key,
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           values,
+		ReturnValues:                        types.ReturnValueAllOld,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	if err != nil {
+		return nil, versionError(err)
+	}
+	return result.Attributes, nil
+}
+
+// versionError tells a version conflict from a missing item using the old
+// item returned with a failed condition check
+func versionError(err error) error {
+	var conditionErr *types.ConditionalCheckFailedException
+	if !errors.As(err, &conditionErr) {
+		return err
+	}
+	if len(conditionErr.Item) == 0 {
+		return errItemNotFound
+	}
+	return db.ErrVersionConflict
+}
+
+// patchVersioned writes only the named attributes of item to an existing item,
+// provided it still has the expected version, and stores item's version.
+// Names missing from item are removed, which is how fields cleared by a patch
+// disappear. It fails like putVersioned.
+func patchVersioned(client *dynamodb.Client, table, keyName string, key, item map[string]types.AttributeValue, names []string, expected int64) error {
+	condition, attributeNames, values := versionCondition(keyName, expected)
+	if values == nil {
+		values = make(map[string]types.AttributeValue)
+	}
+
+	var set, remove []string
+	seen := make(map[string]bool)
+	for i, name := range append(names, "version") {
+		if seen[name] || name == keyName {
+			continue
+		}
+		seen[name] = true
+
+		placeholder := "#a" + strconv.Itoa(i)
+		if name == "version" {
+			placeholder = "#version"
+		}
+		attributeNames[placeholder] = name
+		value, ok := item[name]
+		if !ok {
+			remove = append(remove, placeholder)
+			continue
+		}
+		valueName := ":v" + strconv.Itoa(len(set))
+		values[valueName] = value
+		set = append(set, placeholder+" = "+valueName)
+	}
+
+	expression := "SET " + strings.Join(set, ", ")
+	if len(remove) > 0 {
+		expression += " REMOVE " + strings.Join(remove, ", ")
+	}
+
+	_, err := client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                           aws.String(table),
+		Key:                                 key,
+		UpdateExpression:                    aws.String(expression),
+		ConditionExpression:                 aws.String(condition),
+		ExpressionAttributeNames:            attributeNames,
+		ExpressionAttributeValues:           values,
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	return versionError(err)
+}
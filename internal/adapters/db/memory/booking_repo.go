@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"errors"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/pagination"
+)
+
+type BookingRepo struct {
+	store *Store
+}
+
+func NewBookingRepo(store *Store) *BookingRepo {
+	return &BookingRepo{store: store}
+}
+
+// ListBookings returns the page of stored bookings matching query. Without a
+// sort order bookings are listed by ID.
+func (r *BookingRepo) ListBookings(query models.BookingQuery) (*models.BookingPage, error) {
+	r.store.mu.RLock()
+	bookings := make([]models.Booking, 0)
+	for _, booking := range r.store.bookings {
+		if query.Matches(booking) {
+			bookings = append(bookings, copyBooking(booking))
+		}
+	}
+	r.store.mu.RUnlock()
+
+	field, _ := query.Page.SortField()
+	sortKey := models.BookingSortKeys[field]
+	start, end, next, err := pagination.Window(bookings, func(i int) (string, string) {
+		if sortKey == nil {
+			return "", bookings[i].BookingID
+		}
+		return sortKey(bookings[i]), bookings[i].BookingID
+	}, query.Page.Cursor, query.Page.Sort, query.Page.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BookingPage{Items: bookings[start:end], NextCursor: next}, nil
+}
+
+// GetBookingByID returns the booking with the given ID, or nil if it does not exist
+func (r *BookingRepo) GetBookingByID(id string) (*models.Booking, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok {
+		return nil, nil
+	}
+	booking = copyBooking(booking)
+	return &booking, nil
+}
+
+// GetBookingByLocator returns the booking with the given record locator, or nil if there is none
+func (r *BookingRepo) GetBookingByLocator(locator string) (*models.Booking, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	id, ok := r.store.bookingLocators[locator]
+	if !ok {
+		return nil, nil
+	}
+	booking := copyBooking(r.store.bookings[id])
+	return &booking, nil
+}
+
+// CreateBooking stores a new booking, failing with db.ErrLocatorTaken if
+// another booking already has its locator
+func (r *BookingRepo) CreateBooking(booking *models.Booking) error {
+	if booking == nil {
+		return errors.New("booking details are nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.bookings[booking.BookingID]; exists {
+		return models.Conflict("booking_exists", "booking already exists")
+	}
+	if _, taken := r.store.bookingLocators[booking.Locator]; taken {
+		return db.ErrLocatorTaken
+	}
+	booking.Version = 1
+	r.store.bookings[booking.BookingID] = copyBooking(*booking)
+	r.store.bookingLocators[booking.Locator] = booking.BookingID
+	return nil
+}
+
+// UpdateBookingStatus applies a status change, provided the booking is still in
+// the status the change starts from
+func (r *BookingRepo) UpdateBookingStatus(id string, change models.BookingStatusChange) error {
+	if id == "" || change.To == "" {
+		return errors.New("id or status cannot be empty")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok {
+		return models.NotFound("booking")
+	}
+	if booking.BookingStatus != change.From {
+		return db.ErrBookingStatusChanged
+	}
+	booking.BookingStatus = change.To
+	booking.StatusHistory = append(append([]models.BookingStatusChange(nil), booking.StatusHistory...), change)
+	booking.UpdatedAt = change.ChangedAt
+	booking.Version++
+	r.store.bookings[id] = booking
+	return nil
+}
+
+// UpdateBookingSegments replaces the segments of a booking, recording their
+// progress with the suppliers, provided the booking is still at version
+func (r *BookingRepo) UpdateBookingSegments(id string, segments []models.BookingSegment, version int64) error {
+	if id == "" {
+		return errors.New("invalid booking ID")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, ok := r.store.bookings[id]
+	if !ok {
+		return models.NotFound("booking")
+	}
+	if booking.Version != version {
+		return db.ErrVersionConflict
+	}
+	booking.Segments = copySegments(segments)
+	booking.UpdatedAt = time.Now().UTC()
+	booking.Version++
+	r.store.bookings[id] = booking
+	return nil
+}
+
+// UpdateBooking replaces the stored booking with the given ID, provided it is
+// still at booking.Version, and bumps the version
+func (r *BookingRepo) UpdateBooking(id string, booking *models.Booking) (*models.Booking, error) {
+	if id == "" || booking == nil {
+		return nil, errors.New("invalid booking ID or booking details")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, exists := r.store.bookings[id]
+	if !exists {
+		return nil, models.NotFound("booking")
+	}
+	if existing.Version != booking.Version {
+		return nil, db.ErrVersionConflict
+	}
+	updated := *booking
+	updated.BookingID = id
+	updated.Locator = existing.Locator
+	updated.Version++
+	r.store.bookings[id] = copyBooking(updated)
+
+	return &updated, nil
+}
+
+// PatchBooking stores a patched booking. The store keeps whole values, so this is
+// the same versioned replace as UpdateBooking.
+func (r *BookingRepo) PatchBooking(id string, booking *models.Booking, fields []string) (*models.Booking, error) {
+	return r.UpdateBooking(id, booking)
+}
+
+// DeleteBooking removes the booking with the given ID, provided it is still at version
+func (r *BookingRepo) DeleteBooking(id string, version int64) error {
+	if id == "" {
+		return errors.New("invalid booking ID")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	booking, exists := r.store.bookings[id]
+	if !exists {
+		return models.NotFound("booking")
+	}
+	if booking.Version != version {
+		return db.ErrVersionConflict
+	}
+	delete(r.store.bookingLocators, booking.Locator)
+	delete(r.store.bookings, id)
+	return nil
+}
+
+// copyBooking detaches the booking's segments, their quotes and its status
+// history, so callers can change what they are given without touching stored
+// state
+func copyBooking(booking models.Booking) models.Booking {
+	booking.Segments = copySegments(booking.Segments)
+	booking.StatusHistory = append([]models.BookingStatusChange(nil), booking.StatusHistory...)
+	if booking.Contact != nil {
+		contact := *booking.Contact
+		booking.Contact = &contact
+	}
+	return booking
+}
+
+func copySegments(segments []models.BookingSegment) []models.BookingSegment {
+	if segments == nil {
+		return nil
+	}
+	copied := make([]models.BookingSegment, len(segments))
+	for i, segment := range segments {
+		if segment.Flight != nil {
+			flight := *segment.Flight
+			if flight.Quote != nil {
+				quote := *flight.Quote
+				if flight.Quote.Passengers != nil {
+					quote.Passengers = make(map[string]int, len(flight.Quote.Passengers))
+					for passengerType, count := range flight.Quote.Passengers {
+						quote.Passengers[passengerType] = count
+					}
+				}
+				quote.Lines = append([]models.QuoteLine(nil), flight.Quote.Lines...)
+				for j := range quote.Lines {
+					quote.Lines[j].Taxes = append([]models.Charge(nil), quote.Lines[j].Taxes...)
+					quote.Lines[j].Fees = append([]models.Charge(nil), quote.Lines[j].Fees...)
+				}
+				flight.Quote = &quote
+			}
+			segment.Flight = &flight
+		}
+		if segment.Hotel != nil {
+			hotel := *segment.Hotel
+			if hotel.Quote != nil {
+				quote := *hotel.Quote
+				quote.Nights = append([]models.NightPrice(nil), hotel.Quote.Nights...)
+				hotel.Quote = &quote
+			}
+			segment.Hotel = &hotel
+		}
+		copied[i] = segment
+	}
+	return copied
+}
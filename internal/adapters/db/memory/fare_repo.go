@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"errors"
+	"travel-backend/internal/core/domain/models"
+)
+
+type FareRepo struct {
+	store *Store
+}
+
+func NewFareRepo(store *Store) *FareRepo {
+	return &FareRepo{store: store}
+}
+
+// GetFaresByFlightID returns the fares sold on a flight
+func (r *FareRepo) GetFaresByFlightID(flightID string) ([]models.Fare, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var fares []models.Fare
+	for _, fare := range r.store.fares {
+		if fare.FlightID == flightID {
+			fares = append(fares, copyFare(fare))
+		}
+	}
+	return fares, nil
+}
+
+// GetFareByID returns the fare with the given ID, or nil if it does not exist
+func (r *FareRepo) GetFareByID(fareID string) (*models.Fare, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	fare, ok := r.store.fares[fareID]
+	if !ok {
+		return nil, nil
+	}
+	fare = copyFare(fare)
+	return &fare, nil
+}
+
+// CreateFare stores a new fare
+func (r *FareRepo) CreateFare(fare *models.Fare) error {
+	if fare == nil {
+		return errors.New("fare details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.fares[fare.FareID]; exists {
+		return models.Conflict("fare_exists", "fare already exists")
+	}
+	r.store.fares[fare.FareID] = copyFare(*fare)
+	return nil
+}
+
+// copyFare detaches the fare's price table so callers cannot mutate stored state
+func copyFare(fare models.Fare) models.Fare {
+	prices := make(map[string]models.FarePrice, len(fare.Prices))
+	for passengerType, price := range fare.Prices {
+		price.Taxes = append([]models.Charge(nil), price.Taxes...)
+		price.Fees = append([]models.Charge(nil), price.Fees...)
+		prices[passengerType] = price
+	}
+	fare.Prices = prices
+	return fare
+}
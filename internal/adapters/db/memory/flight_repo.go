@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"errors"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/pagination"
+)
+
+type FlightRepo struct {
+	store *Store
+}
+
+func NewFlightRepo(store *Store) *FlightRepo {
+	return &FlightRepo{store: store}
+}
+
+// ListFlights returns the page of stored flights matching query. Without a
+// sort order flights are listed by ID.
+func (r *FlightRepo) ListFlights(query models.FlightQuery) (*models.FlightPage, error) {
+	r.store.mu.RLock()
+	flights := make([]models.Flight, 0)
+	for _, flight := range r.store.flights {
+		if query.Matches(flight) {
+			flights = append(flights, flight)
+		}
+	}
+	r.store.mu.RUnlock()
+
+	field, _ := query.Page.SortField()
+	sortKey := models.FlightSortKeys[field]
+	start, end, next, err := pagination.Window(flights, func(i int) (string, string) {
+		if sortKey == nil {
+			return "", flights[i].FlightID
+		}
+		return sortKey(flights[i]), flights[i].FlightID
+	}, query.Page.Cursor, query.Page.Sort, query.Page.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.FlightPage{Items: flights[start:end], NextCursor: next}, nil
+}
+
+// GetFlightByID returns the flight with the given ID, or nil if it does not exist
+func (r *FlightRepo) GetFlightByID(id string) (*models.Flight, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	flight, ok := r.store.flights[id]
+	if !ok {
+		return nil, nil
+	}
+	return &flight, nil
+}
+
+// CreateFlight stores a new flight
+func (r *FlightRepo) CreateFlight(flight *models.Flight) error {
+	if flight == nil {
+		return errors.New("flight details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.flights[flight.FlightID]; exists {
+		return models.Conflict("flight_exists", "flight already exists")
+	}
+	flight.Version = 1
+	r.store.flights[flight.FlightID] = *flight
+	return nil
+}
+
+// GetFlightBookings returns all bookings made against the given flight
+func (r *FlightRepo) GetFlightBookings(flightID string) ([]models.Booking, error) {
+	if flightID == "" {
+		return nil, errors.New("flight ID cannot be empty")
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var bookings []models.Booking
+	for _, booking := range r.store.bookings {
+		if booking.HasFlight(flightID) {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+// SearchFlights returns the flights from origin to destination departing on date
+func (r *FlightRepo) SearchFlights(origin, destination, date string) ([]models.Flight, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var flights []models.Flight
+	for _, flight := range r.store.flights {
+		if strings.EqualFold(flight.Origin, origin) &&
+			strings.EqualFold(flight.Destination, destination) &&
+			flight.DepartureDate() == date {
+			flights = append(flights, flight)
+		}
+	}
+	return flights, nil
+}
+
+// GetFlightsByOrigin returns the flights leaving origin on date, whatever their destination
+func (r *FlightRepo) GetFlightsByOrigin(origin, date string) ([]models.Flight, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var flights []models.Flight
+	for _, flight := range r.store.flights {
+		if strings.EqualFold(flight.Origin, origin) && flight.DepartureDate() == date {
+			flights = append(flights, flight)
+		}
+	}
+	return flights, nil
+}
+
+// UpdateFlight replaces the stored flight with the given ID
+func (r *FlightRepo) UpdateFlight(id string, flight *models.Flight) (*models.Flight, error) {
+	if id == "" || flight == nil {
+		return nil, errors.New("invalid flight ID or flight details")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, exists := r.store.flights[id]
+	if !exists {
+		return nil, models.NotFound("flight")
+	}
+	if existing.Version != flight.Version {
+		return nil, db.ErrVersionConflict
+	}
+	updated := *flight
+	updated.FlightID = id
+	updated.Version++
+	r.store.flights[id] = updated
+
+	return &updated, nil
+}
+
+// PatchFlight stores a patched flight. The store keeps whole values, so this is
+// the same versioned replace as UpdateFlight.
+func (r *FlightRepo) PatchFlight(id string, flight *models.Flight, fields []string) (*models.Flight, error) {
+	return r.UpdateFlight(id, flight)
+}
+
+// DeleteFlight removes the flight with the given ID, provided it is still at version
+func (r *FlightRepo) DeleteFlight(id string, version int64) error {
+	if id == "" {
+		return errors.New("invalid flight ID")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, exists := r.store.flights[id]
+	if !exists {
+		return models.NotFound("flight")
+	}
+	if existing.Version != version {
+		return db.ErrVersionConflict
+	}
+	delete(r.store.flights, id)
+	return nil
+}
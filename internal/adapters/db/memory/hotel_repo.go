@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/pagination"
+)
+
+type HotelRepo struct {
+	store *Store
+}
+
+func NewHotelRepo(store *Store) *HotelRepo {
+	return &HotelRepo{store: store}
+}
+
+// ListHotels returns the page of stored properties matching query. Without a
+// sort order hotels are listed by ID.
+func (r *HotelRepo) ListHotels(query models.HotelQuery) (*models.HotelPage, error) {
+	r.store.mu.RLock()
+	hotels := make([]models.Property, 0)
+	for _, hotel := range r.store.hotels {
+		if query.Matches(hotel) {
+			hotels = append(hotels, copyHotel(hotel))
+		}
+	}
+	r.store.mu.RUnlock()
+
+	field, _ := query.Page.SortField()
+	sortKey := models.HotelSortKeys[field]
+	start, end, next, err := pagination.Window(hotels, func(i int) (string, string) {
+		if sortKey == nil {
+			return "", hotels[i].HotelID
+		}
+		return sortKey(hotels[i]), hotels[i].HotelID
+	}, query.Page.Cursor, query.Page.Sort, query.Page.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return &models.HotelPage{Items: hotels[start:end], NextCursor: next}, nil
+}
+
+// GetHotelByID returns the hotel with the given ID, or nil if it does not exist
+func (r *HotelRepo) GetHotelByID(id string) (*models.Property, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	hotel, ok := r.store.hotels[id]
+	if !ok {
+		return nil, nil
+	}
+	hotel = copyHotel(hotel)
+	return &hotel, nil
+}
+
+// GetHotelsByCity returns the hotels whose address is in the given city
+func (r *HotelRepo) GetHotelsByCity(city string) ([]models.Property, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var hotels []models.Property
+	for _, hotel := range r.store.hotels {
+		if strings.EqualFold(strings.TrimSpace(hotel.Address.City), strings.TrimSpace(city)) {
+			hotels = append(hotels, copyHotel(hotel))
+		}
+	}
+	return hotels, nil
+}
+
+// GetHotelsNear returns the hotels within radiusKm of a point, nearest first
+func (r *HotelRepo) GetHotelsNear(center models.GeoPoint, radiusKm float64) ([]models.NearbyHotel, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var hotels []models.NearbyHotel
+	for _, hotel := range r.store.hotels {
+		if hotel.Location.IsZero() {
+			continue
+		}
+		if distance := center.DistanceKm(hotel.Location); distance <= radiusKm {
+			hotels = append(hotels, models.NearbyHotel{Property: copyHotel(hotel), DistanceKm: distance})
+		}
+	}
+
+	sort.SliceStable(hotels, func(i, j int) bool {
+		return hotels[i].DistanceKm < hotels[j].DistanceKm
+	})
+	return hotels, nil
+}
+
+// CreateHotel stores a new hotel
+func (r *HotelRepo) CreateHotel(hotel *models.Property) error {
+	if hotel == nil {
+		return errors.New("hotel details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.hotels[hotel.HotelID]; exists {
+		return models.Conflict("hotel_exists", "hotel already exists")
+	}
+	hotel.Version = 1
+	r.store.hotels[hotel.HotelID] = copyHotel(*hotel)
+	return nil
+}
+
+// UpdateHotel replaces the stored hotel with the given ID, provided it is still
+// at hotel.Version, and bumps the version
+func (r *HotelRepo) UpdateHotel(id string, hotel *models.Property) (*models.Property, error) {
+	if hotel == nil {
+		return nil, errors.New("hotel details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, exists := r.store.hotels[id]
+	if !exists {
+		return nil, models.NotFound("hotel")
+	}
+	if existing.Version != hotel.Version {
+		return nil, db.ErrVersionConflict
+	}
+	updated := copyHotel(*hotel)
+	updated.HotelID = id
+	updated.Version++
+	r.store.hotels[id] = updated
+
+	return &updated, nil
+}
+
+// PatchHotel stores a patched hotel. The store keeps whole values, so this is
+// the same versioned replace as UpdateHotel.
+func (r *HotelRepo) PatchHotel(id string, hotel *models.Property, fields []string) (*models.Property, error) {
+	return r.UpdateHotel(id, hotel)
+}
+
+// DeleteHotel removes the hotel with the given ID, provided it is still at version
+func (r *HotelRepo) DeleteHotel(id string, version int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, exists := r.store.hotels[id]
+	if !exists {
+		return models.NotFound("hotel")
+	}
+	if existing.Version != version {
+		return db.ErrVersionConflict
+	}
+	delete(r.store.hotels, id)
+	return nil
+}
+
+// copyHotel detaches the property's slices so callers cannot mutate stored state
+func copyHotel(hotel models.Property) models.Property {
+	if hotel.Assets != nil {
+		hotel.Assets = append([]models.Asset(nil), hotel.Assets...)
+	}
+	if hotel.Amenities != nil {
+		hotel.Amenities = append([]string(nil), hotel.Amenities...)
+	}
+	return hotel
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"errors"
+	"travel-backend/internal/core/domain/models"
+)
+
+type HotelReservationRepo struct {
+	store *Store
+}
+
+func NewHotelReservationRepo(store *Store) *HotelReservationRepo {
+	return &HotelReservationRepo{store: store}
+}
+
+// GetReservationsByHotelID returns the reservations made at a property
+func (r *HotelReservationRepo) GetReservationsByHotelID(hotelID string) ([]models.HotelReservation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var reservations []models.HotelReservation
+	for _, reservation := range r.store.hotelReservations {
+		if reservation.HotelID == hotelID {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+// GetReservationsByBookingID returns the hotel reservations sold as part of a booking
+func (r *HotelReservationRepo) GetReservationsByBookingID(bookingID string) ([]models.HotelReservation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var reservations []models.HotelReservation
+	for _, reservation := range r.store.hotelReservations {
+		if reservation.BookingID == bookingID {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+// GetReservationByID returns the reservation with the given ID, or nil if it does not exist
+func (r *HotelReservationRepo) GetReservationByID(reservationID string) (*models.HotelReservation, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	reservation, ok := r.store.hotelReservations[reservationID]
+	if !ok {
+		return nil, nil
+	}
+	return &reservation, nil
+}
+
+// CreateReservation stores a new reservation
+func (r *HotelReservationRepo) CreateReservation(reservation *models.HotelReservation) error {
+	if reservation == nil {
+		return errors.New("reservation details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.hotelReservations[reservation.ReservationID]; exists {
+		return models.Conflict("reservation_exists", "reservation already exists")
+	}
+	r.store.hotelReservations[reservation.ReservationID] = *reservation
+	return nil
+}
+
+// UpdateReservation replaces the stored reservation with the given ID
+func (r *HotelReservationRepo) UpdateReservation(reservationID string, reservation *models.HotelReservation) (*models.HotelReservation, error) {
+	if reservation == nil {
+		return nil, errors.New("reservation details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.hotelReservations[reservationID]; !exists {
+		return nil, models.NotFound("reservation")
+	}
+	updated := *reservation
+	updated.ReservationID = reservationID
+	r.store.hotelReservations[reservationID] = updated
+
+	return &updated, nil
+}
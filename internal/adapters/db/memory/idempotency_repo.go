@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"errors"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+type IdempotencyRepo struct {
+	store *Store
+}
+
+func NewIdempotencyRepo(store *Store) *IdempotencyRepo {
+	return &IdempotencyRepo{store: store}
+}
+
+// GetIdempotencyRecord returns the unexpired record for a key, or nil if there is none
+func (r *IdempotencyRepo) GetIdempotencyRecord(key string) (*models.IdempotencyRecord, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	record, ok := r.store.idempotency[key]
+	if !ok || !record.IsActive(time.Now()) {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// CreateIdempotencyRecord claims a key, failing with db.ErrIdempotencyKeyInUse
+// while an unexpired record exists for it that cannot be taken over
+func (r *IdempotencyRepo) CreateIdempotencyRecord(record *models.IdempotencyRecord) error {
+	if record == nil {
+		return errors.New("idempotency record cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.idempotency[record.Key]; ok && !existing.CanTakeOver(record.Fingerprint, time.Now()) {
+		return db.ErrIdempotencyKeyInUse
+	}
+	r.store.idempotency[record.Key] = *record
+	return nil
+}
+
+// CompleteIdempotencyRecord stores the response sent for a claimed key
+func (r *IdempotencyRepo) CompleteIdempotencyRecord(record *models.IdempotencyRecord) error {
+	if record == nil {
+		return errors.New("idempotency record cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.idempotency[record.Key]; !ok {
+		return models.NotFound("idempotency record")
+	}
+	r.store.idempotency[record.Key] = *record
+	return nil
+}
+
+// DeleteIdempotencyRecord forgets a key so that it can be used again
+func (r *IdempotencyRepo) DeleteIdempotencyRecord(key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.idempotency, key)
+	return nil
+}
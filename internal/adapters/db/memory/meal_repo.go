@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"errors"
+	"travel-backend/internal/core/domain/models"
+)
+
+type MealRepo struct {
+	store *Store
+}
+
+func NewMealRepo(store *Store) *MealRepo {
+	return &MealRepo{store: store}
+}
+
+// GetAllMeals returns the whole meal catalogue
+func (r *MealRepo) GetAllMeals() ([]models.Meal, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	meals := make([]models.Meal, 0, len(r.store.meals))
+	for _, meal := range r.store.meals {
+		meals = append(meals, copyMeal(meal))
+	}
+	return meals, nil
+}
+
+// GetMealByID returns the catalogue meal with the given ID, or nil if it does not exist
+func (r *MealRepo) GetMealByID(mealID string) (*models.Meal, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	meal, ok := r.store.meals[mealID]
+	if !ok {
+		return nil, nil
+	}
+	meal = copyMeal(meal)
+	return &meal, nil
+}
+
+// GetMealsByClass returns the meals offered in the given cabin class
+func (r *MealRepo) GetMealsByClass(class string) ([]models.Meal, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var meals []models.Meal
+	for _, meal := range r.store.meals {
+		if meal.AvailableClasses.Contains(class) {
+			meals = append(meals, copyMeal(meal))
+		}
+	}
+	return meals, nil
+}
+
+// AddMeal stores a new catalogue meal
+func (r *MealRepo) AddMeal(meal *models.Meal) error {
+	if meal == nil {
+		return errors.New("meal details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.meals[meal.MealID]; exists {
+		return models.Conflict("meal_exists", "meal already exists")
+	}
+	r.store.meals[meal.MealID] = copyMeal(*meal)
+	return nil
+}
+
+// GetPassengerMeals returns the meals chosen by passengers on a booking
+func (r *MealRepo) GetPassengerMeals(bookingID string) ([]models.MealSelection, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return passengerMeals(r.store, bookingID), nil
+}
+
+// AssignMealToPassenger records the meal chosen by the passenger named on it
+func (r *MealRepo) AssignMealToPassenger(passengerMeal *models.MealSelection) error {
+	if passengerMeal == nil {
+		return errors.New("meal details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return assignMeal(r.store, passengerMeal)
+}
+
+// assignMeal must be called with the store's write lock held
+func assignMeal(store *Store, passengerMeal *models.MealSelection) error {
+	if passengerMeal.PassengerID == "" {
+		return errors.New("passenger ID is required")
+	}
+	if _, ok := store.meals[passengerMeal.MealID]; !ok {
+		return models.NotFound("meal")
+	}
+	store.passengerMeals[passengerMeal.PassengerID] = *passengerMeal
+	return nil
+}
+
+// passengerMeals must be called with the store's lock held
+func passengerMeals(store *Store, bookingID string) []models.MealSelection {
+	var meals []models.MealSelection
+	for _, meal := range store.passengerMeals {
+		if meal.BookingID == bookingID {
+			meals = append(meals, meal)
+		}
+	}
+	return meals
+}
+
+// copyMeal detaches the meal's slices so callers cannot mutate stored state
+func copyMeal(meal models.Meal) models.Meal {
+	meal.AvailableClasses = append(models.CabinClasses(nil), meal.AvailableClasses...)
+	meal.DietaryTags = append([]string(nil), meal.DietaryTags...)
+	return meal
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"errors"
+	"travel-backend/internal/core/domain/models"
+)
+
+type PassengerRepo struct {
+	store *Store
+}
+
+func NewPassengerRepo(store *Store) *PassengerRepo {
+	return &PassengerRepo{store: store}
+}
+
+// GetAllPassengersByBookingID returns the passengers travelling on a booking
+func (r *PassengerRepo) GetAllPassengersByBookingID(bookingID string) ([]models.Passenger, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var passengers []models.Passenger
+	for _, passenger := range r.store.passengers {
+		if passenger.BookingID == bookingID {
+			passengers = append(passengers, passenger)
+		}
+	}
+	return passengers, nil
+}
+
+// GetPassengerByID returns the passenger with the given ID, or nil if it does not exist
+func (r *PassengerRepo) GetPassengerByID(passengerID string) (*models.Passenger, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	passenger, ok := r.store.passengers[passengerID]
+	if !ok {
+		return nil, nil
+	}
+	return &passenger, nil
+}
+
+// AddPassenger stores a new passenger
+func (r *PassengerRepo) AddPassenger(passenger *models.Passenger) error {
+	if passenger == nil {
+		return errors.New("passenger details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.passengers[passenger.PassengerID]; exists {
+		return models.Conflict("passenger_exists", "passenger already exists")
+	}
+	r.store.passengers[passenger.PassengerID] = *passenger
+	return nil
+}
+
+// UpdatePassenger replaces the stored passenger with the given ID
+func (r *PassengerRepo) UpdatePassenger(passengerID string, passenger *models.Passenger) (*models.Passenger, error) {
+	if passenger == nil {
+		return nil, errors.New("passenger details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.passengers[passengerID]; !exists {
+		return nil, models.NotFound("passenger")
+	}
+	updated := *passenger
+	updated.PassengerID = passengerID
+	r.store.passengers[passengerID] = updated
+
+	return &updated, nil
+}
+
+// DeletePassenger removes a passenger together with their meal selection
+func (r *PassengerRepo) DeletePassenger(passengerID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.passengers[passengerID]; !exists {
+		return models.NotFound("passenger")
+	}
+	delete(r.store.passengers, passengerID)
+	delete(r.store.passengerMeals, passengerID)
+	return nil
+}
+
+// AssignSeat marks the seat as taken by the passenger named on it
+func (r *PassengerRepo) AssignSeat(passengerSeat *models.Seat) error {
+	if passengerSeat == nil {
+		return errors.New("seat details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return assignSeat(r.store, passengerSeat.SeatID, passengerSeat.PassengerID, passengerSeat.BookingID)
+}
+
+// GetPassengerSeats returns the seats assigned to passengers on a booking
+func (r *PassengerRepo) GetPassengerSeats(bookingID string) ([]models.Seat, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var seats []models.Seat
+	for _, seat := range r.store.seats {
+		if seat.BookingID == bookingID {
+			seats = append(seats, seat)
+		}
+	}
+	return seats, nil
+}
+
+// AssignMeal records the meal chosen by the passenger named on it
+func (r *PassengerRepo) AssignMeal(passengerMeal *models.MealSelection) error {
+	if passengerMeal == nil {
+		return errors.New("meal details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return assignMeal(r.store, passengerMeal)
+}
+
+// GetPassengerMeals returns the meals chosen by passengers on a booking
+func (r *PassengerRepo) GetPassengerMeals(bookingID string) ([]models.MealSelection, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return passengerMeals(r.store, bookingID), nil
+}
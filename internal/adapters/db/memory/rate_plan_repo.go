@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"errors"
+	"travel-backend/internal/core/domain/models"
+)
+
+type RatePlanRepo struct {
+	store *Store
+}
+
+func NewRatePlanRepo(store *Store) *RatePlanRepo {
+	return &RatePlanRepo{store: store}
+}
+
+// GetRatePlansByHotelID returns the rate plans of a hotel
+func (r *RatePlanRepo) GetRatePlansByHotelID(hotelID string) ([]models.RatePlan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var ratePlans []models.RatePlan
+	for _, ratePlan := range r.store.ratePlans {
+		if ratePlan.HotelID == hotelID {
+			ratePlans = append(ratePlans, ratePlan)
+		}
+	}
+	return ratePlans, nil
+}
+
+// GetRatePlanByID returns the rate plan with the given ID, or nil if it does not exist
+func (r *RatePlanRepo) GetRatePlanByID(ratePlanID string) (*models.RatePlan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ratePlan, ok := r.store.ratePlans[ratePlanID]
+	if !ok {
+		return nil, nil
+	}
+	return &ratePlan, nil
+}
+
+// CreateRatePlan stores a new rate plan
+func (r *RatePlanRepo) CreateRatePlan(ratePlan *models.RatePlan) error {
+	if ratePlan == nil {
+		return errors.New("rate plan details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.ratePlans[ratePlan.RatePlanID]; exists {
+		return models.Conflict("rate_plan_exists", "rate plan already exists")
+	}
+	r.store.ratePlans[ratePlan.RatePlanID] = *ratePlan
+	return nil
+}
+
+// UpdateRatePlan replaces the stored rate plan with the given ID
+func (r *RatePlanRepo) UpdateRatePlan(ratePlanID string, ratePlan *models.RatePlan) (*models.RatePlan, error) {
+	if ratePlan == nil {
+		return nil, errors.New("rate plan details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.ratePlans[ratePlanID]; !exists {
+		return nil, models.NotFound("rate plan")
+	}
+	updated := *ratePlan
+	updated.RatePlanID = ratePlanID
+	r.store.ratePlans[ratePlanID] = updated
+
+	return &updated, nil
+}
+
+// DeleteRatePlan removes a rate plan together with its nightly rates
+func (r *RatePlanRepo) DeleteRatePlan(ratePlanID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.ratePlans[ratePlanID]; !exists {
+		return models.NotFound("rate plan")
+	}
+	delete(r.store.ratePlans, ratePlanID)
+	for key, rate := range r.store.roomRates {
+		if rate.RatePlanID == ratePlanID {
+			delete(r.store.roomRates, key)
+		}
+	}
+	return nil
+}
+
+// GetRoomRates returns the stored rates of a room type under a rate plan among the given nights
+func (r *RatePlanRepo) GetRoomRates(ratePlanID, roomTypeID string, dates []string) ([]models.RoomRate, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var rates []models.RoomRate
+	for _, date := range dates {
+		if rate, ok := r.store.roomRates[roomRateKey(ratePlanID, roomTypeID, date)]; ok {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+// SetRoomRates stores nightly rates, replacing any already set for the same nights
+func (r *RatePlanRepo) SetRoomRates(rates []models.RoomRate) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, rate := range rates {
+		r.store.roomRates[roomRateKey(rate.RatePlanID, rate.RoomTypeID, rate.Date)] = rate
+	}
+	return nil
+}
+
+func roomRateKey(ratePlanID, roomTypeID, date string) string {
+	return ratePlanID + "#" + roomTypeID + "#" + date
+}
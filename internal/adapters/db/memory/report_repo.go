@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"time"
+	"travel-backend/internal/core/domain/models"
+)
+
+type ReportRepo struct {
+	store *Store
+}
+
+func NewReportRepo(store *Store) *ReportRepo {
+	return &ReportRepo{store: store}
+}
+
+// GetBookingReport returns the bookings created within [startDate, endDate]
+func (r *ReportRepo) GetBookingReport(startDate, endDate time.Time) ([]models.Booking, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var bookings []models.Booking
+	for _, booking := range r.store.bookings {
+		if booking.CreatedAt.Before(startDate) || booking.CreatedAt.After(endDate) {
+			continue
+		}
+		bookings = append(bookings, copyBooking(booking))
+	}
+	return bookings, nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+type RoomInventoryRepo struct {
+	store *Store
+}
+
+func NewRoomInventoryRepo(store *Store) *RoomInventoryRepo {
+	return &RoomInventoryRepo{store: store}
+}
+
+// GetRoomNights returns the stored inventory rows among the given nights
+func (r *RoomInventoryRepo) GetRoomNights(hotelID, roomTypeID string, dates []string) ([]models.RoomNight, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var nights []models.RoomNight
+	for _, date := range dates {
+		if night, ok := r.store.roomNights[roomNightKey(hotelID, roomTypeID, date)]; ok {
+			nights = append(nights, night)
+		}
+	}
+	return nights, nil
+}
+
+// SetAllotment sets the number of rooms for sale on each of the given nights
+func (r *RoomInventoryRepo) SetAllotment(hotelID, roomTypeID string, dates []string, total int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, date := range dates {
+		night := r.store.roomNights[roomNightKey(hotelID, roomTypeID, date)]
+		if total < night.Sold+night.Held {
+			return db.ErrAllotmentBelowSold
+		}
+	}
+	for _, date := range dates {
+		key := roomNightKey(hotelID, roomTypeID, date)
+		night, ok := r.store.roomNights[key]
+		if !ok {
+			night = models.RoomNight{HotelID: hotelID, RoomTypeID: roomTypeID, Date: date}
+		}
+		night.Total = total
+		r.store.roomNights[key] = night
+	}
+	return nil
+}
+
+// ReserveRoomNights sells one room on every given night, or none if any night is sold out
+func (r *RoomInventoryRepo) ReserveRoomNights(hotelID, roomTypeID string, dates []string, defaultTotal int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	nights := make([]models.RoomNight, len(dates))
+	for i, date := range dates {
+		night, ok := r.store.roomNights[roomNightKey(hotelID, roomTypeID, date)]
+		if !ok {
+			night = models.RoomNight{HotelID: hotelID, RoomTypeID: roomTypeID, Date: date, Total: defaultTotal}
+		}
+		if night.Available() < 1 {
+			return db.ErrRoomsSoldOut
+		}
+		night.Sold++
+		nights[i] = night
+	}
+	for _, night := range nights {
+		r.store.roomNights[roomNightKey(hotelID, roomTypeID, night.Date)] = night
+	}
+	return nil
+}
+
+// ReleaseRoomNights gives back one sold room on every given night
+func (r *RoomInventoryRepo) ReleaseRoomNights(hotelID, roomTypeID string, dates []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, date := range dates {
+		key := roomNightKey(hotelID, roomTypeID, date)
+		if night, ok := r.store.roomNights[key]; ok && night.Sold > 0 {
+			night.Sold--
+			r.store.roomNights[key] = night
+		}
+	}
+	return nil
+}
+
+func roomNightKey(hotelID, roomTypeID, date string) string {
+	return hotelID + "#" + roomTypeID + "#" + date
+}
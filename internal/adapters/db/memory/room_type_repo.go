@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"errors"
+	"travel-backend/internal/core/domain/models"
+)
+
+type RoomTypeRepo struct {
+	store *Store
+}
+
+func NewRoomTypeRepo(store *Store) *RoomTypeRepo {
+	return &RoomTypeRepo{store: store}
+}
+
+// GetRoomTypesByHotelID returns the room types of a property
+func (r *RoomTypeRepo) GetRoomTypesByHotelID(hotelID string) ([]models.RoomType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var roomTypes []models.RoomType
+	for _, roomType := range r.store.roomTypes {
+		if roomType.HotelID == hotelID {
+			roomTypes = append(roomTypes, copyRoomType(roomType))
+		}
+	}
+	return roomTypes, nil
+}
+
+// GetRoomTypeByID returns the room type with the given ID, or nil if it does not exist
+func (r *RoomTypeRepo) GetRoomTypeByID(roomTypeID string) (*models.RoomType, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roomType, ok := r.store.roomTypes[roomTypeID]
+	if !ok {
+		return nil, nil
+	}
+	roomType = copyRoomType(roomType)
+	return &roomType, nil
+}
+
+// CreateRoomType stores a new room type
+func (r *RoomTypeRepo) CreateRoomType(roomType *models.RoomType) error {
+	if roomType == nil {
+		return errors.New("room type details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.roomTypes[roomType.RoomTypeID]; exists {
+		return models.Conflict("room_type_exists", "room type already exists")
+	}
+	r.store.roomTypes[roomType.RoomTypeID] = copyRoomType(*roomType)
+	return nil
+}
+
+// UpdateRoomType replaces the stored room type with the given ID
+func (r *RoomTypeRepo) UpdateRoomType(roomTypeID string, roomType *models.RoomType) (*models.RoomType, error) {
+	if roomType == nil {
+		return nil, errors.New("room type details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.roomTypes[roomTypeID]; !exists {
+		return nil, models.NotFound("room type")
+	}
+	updated := copyRoomType(*roomType)
+	updated.RoomTypeID = roomTypeID
+	r.store.roomTypes[roomTypeID] = updated
+
+	return &updated, nil
+}
+
+// DeleteRoomType removes the room type with the given ID
+func (r *RoomTypeRepo) DeleteRoomType(roomTypeID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.roomTypes[roomTypeID]; !exists {
+		return models.NotFound("room type")
+	}
+	delete(r.store.roomTypes, roomTypeID)
+	return nil
+}
+
+// copyRoomType detaches the room type's slices so callers cannot mutate stored state
+func copyRoomType(roomType models.RoomType) models.RoomType {
+	if roomType.Beds != nil {
+		roomType.Beds = append([]models.BedConfig(nil), roomType.Beds...)
+	}
+	if roomType.Amenities != nil {
+		roomType.Amenities = append([]string(nil), roomType.Amenities...)
+	}
+	return roomType
+}
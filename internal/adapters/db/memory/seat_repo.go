@@ -0,0 +1,249 @@
+package memory
+
+import (
+	"errors"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+type SeatRepo struct {
+	store *Store
+}
+
+func NewSeatRepo(store *Store) *SeatRepo {
+	return &SeatRepo{store: store}
+}
+
+// CreateSeats stores a batch of seats, failing without changes if any already exists
+func (r *SeatRepo) CreateSeats(seats []models.Seat) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, seat := range seats {
+		if _, exists := r.store.seats[seat.SeatID]; exists {
+			return errors.New("seat already exists: " + seat.SeatID)
+		}
+	}
+	for _, seat := range seats {
+		r.store.seats[seat.SeatID] = seat
+	}
+	return nil
+}
+
+// GetSeatByID returns the seat with the given ID, or nil if it does not exist
+func (r *SeatRepo) GetSeatByID(seatID string) (*models.Seat, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	seat, ok := r.store.seats[seatID]
+	if !ok {
+		return nil, nil
+	}
+	return &seat, nil
+}
+
+// GetSeatsByFlightID returns every seat on a flight
+func (r *SeatRepo) GetSeatsByFlightID(flightID string) ([]models.Seat, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var seats []models.Seat
+	for _, seat := range r.store.seats {
+		if seat.FlightID == flightID {
+			seats = append(seats, seat)
+		}
+	}
+	return seats, nil
+}
+
+// UpdateSeatAvailability flips the availability flag of a seat
+func (r *SeatRepo) UpdateSeatAvailability(seatID string, isAvailable bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	seat, ok := r.store.seats[seatID]
+	if !ok {
+		return models.NotFound("seat")
+	}
+	seat.IsAvailable = isAvailable
+	if isAvailable {
+		seat.PassengerID = ""
+		seat.BookingID = ""
+	}
+	r.store.seats[seatID] = seat
+	return nil
+}
+
+// GetAvailableSeatsByFlightID returns the seats on a flight that are neither
+// assigned nor under an active hold
+func (r *SeatRepo) GetAvailableSeatsByFlightID(flightID string) ([]models.Seat, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := time.Now()
+	var seats []models.Seat
+	for _, seat := range r.store.seats {
+		if seat.FlightID != flightID || !seat.IsAvailable {
+			continue
+		}
+		if hold, held := r.store.seatHolds[seat.SeatID]; held && hold.IsActive(now) {
+			continue
+		}
+		seats = append(seats, seat)
+	}
+	return seats, nil
+}
+
+// AssignSeatToPassenger gives an available seat to a passenger on a booking
+func (r *SeatRepo) AssignSeatToPassenger(seatID, passengerID, bookingID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return assignSeat(r.store, seatID, passengerID, bookingID)
+}
+
+// DeleteSeatsByFlightID removes a flight's whole seat inventory
+func (r *SeatRepo) DeleteSeatsByFlightID(flightID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, seat := range r.store.seats {
+		if seat.FlightID == flightID {
+			delete(r.store.seats, id)
+			delete(r.store.seatHolds, id)
+		}
+	}
+	return nil
+}
+
+// HoldSeat blocks an available seat for a booking until the hold expires.
+// Holding a seat the booking already holds extends the hold.
+func (r *SeatRepo) HoldSeat(hold *models.SeatHold) error {
+	if hold == nil {
+		return errors.New("seat hold details cannot be nil")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	seat, ok := r.store.seats[hold.SeatID]
+	if !ok {
+		return models.NotFound("seat")
+	}
+	if !seat.IsAvailable {
+		return db.ErrSeatUnavailable
+	}
+	if existing, held := r.store.seatHolds[hold.SeatID]; held && existing.IsActive(time.Now()) && existing.BookingID != hold.BookingID {
+		return db.ErrSeatUnavailable
+	}
+
+	r.store.seatHolds[hold.SeatID] = *hold
+	return nil
+}
+
+// ReleaseSeatHold drops the booking's hold on a seat
+func (r *SeatRepo) ReleaseSeatHold(seatID, bookingID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := activeHold(r.store, seatID, bookingID); err != nil {
+		return err
+	}
+	delete(r.store.seatHolds, seatID)
+	return nil
+}
+
+// ConfirmSeatHold turns the booking's hold on a seat into a passenger assignment
+func (r *SeatRepo) ConfirmSeatHold(seatID, passengerID, bookingID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, err := activeHold(r.store, seatID, bookingID); err != nil {
+		return err
+	}
+	if err := assignSeat(r.store, seatID, passengerID, bookingID); err != nil {
+		return err
+	}
+	delete(r.store.seatHolds, seatID)
+	return nil
+}
+
+// GetFlightInventories counts the seats of several flights and how many of
+// them are sold, leaving out flights with neither
+func (r *SeatRepo) GetFlightInventories(flightIDs []string) (map[string]models.FlightInventory, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	requested := make(map[string]bool, len(flightIDs))
+	inventories := make(map[string]models.FlightInventory, len(flightIDs))
+	for _, flightID := range flightIDs {
+		requested[flightID] = true
+		if sold, ok := r.store.seatsSold[flightID]; ok {
+			inventories[flightID] = models.FlightInventory{FlightID: flightID, SeatsSold: sold}
+		}
+	}
+	for _, seat := range r.store.seats {
+		if !requested[seat.FlightID] {
+			continue
+		}
+		inventory := inventories[seat.FlightID]
+		inventory.FlightID = seat.FlightID
+		inventory.Capacity++
+		inventories[seat.FlightID] = inventory
+	}
+	return inventories, nil
+}
+
+// ReserveSeats counts count more of a flight's seats as sold, provided no more
+// than capacity are sold afterwards
+func (r *SeatRepo) ReserveSeats(flightID string, count, capacity int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.seatsSold[flightID]+count > capacity {
+		return db.ErrSeatsSoldOut
+	}
+	r.store.seatsSold[flightID] += count
+	return nil
+}
+
+// ReleaseSeats gives back count sold seats of a flight
+func (r *SeatRepo) ReleaseSeats(flightID string, count int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.seatsSold[flightID] < count {
+		return errors.New("cannot release more seats than were sold on flight " + flightID)
+	}
+	r.store.seatsSold[flightID] -= count
+	return nil
+}
+
+// activeHold must be called with the store's lock held
+func activeHold(store *Store, seatID, bookingID string) (models.SeatHold, error) {
+	hold, held := store.seatHolds[seatID]
+	if !held || !hold.IsActive(time.Now()) || hold.BookingID != bookingID {
+		return models.SeatHold{}, db.ErrSeatHoldNotFound
+	}
+	return hold, nil
+}
+
+// assignSeat must be called with the store's write lock held
+func assignSeat(store *Store, seatID, passengerID, bookingID string) error {
+	seat, ok := store.seats[seatID]
+	if !ok {
+		return models.NotFound("seat")
+	}
+	if !seat.IsAvailable {
+		return db.ErrSeatUnavailable
+	}
+	if hold, held := store.seatHolds[seatID]; held && hold.IsActive(time.Now()) && hold.BookingID != bookingID {
+		return db.ErrSeatUnavailable
+	}
+	seat.IsAvailable = false
+	seat.PassengerID = passengerID
+	seat.BookingID = bookingID
+	store.seats[seatID] = seat
+	return nil
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+	"travel-backend/internal/core/domain/models"
+)
+
+// Store holds every in-memory table behind a single lock so that repositories
+// sharing a Store see a consistent view of the data.
+type Store struct {
+	mu sync.RWMutex
+
+	hotels            map[string]models.Property
+	roomTypes         map[string]models.RoomType
+	hotelReservations map[string]models.HotelReservation
+	roomNights        map[string]models.RoomNight
+	ratePlans         map[string]models.RatePlan
+	roomRates         map[string]models.RoomRate
+	flights           map[string]models.Flight
+	bookings          map[string]models.Booking
+	bookingLocators   map[string]string // locator -> bookingID
+	passengers        map[string]models.Passenger
+	seats             map[string]models.Seat
+	seatHolds         map[string]models.SeatHold
+	seatsSold         map[string]int // flightID -> seats taken by confirmed bookings
+	meals             map[string]models.Meal
+	passengerMeals    map[string]models.MealSelection
+	fares             map[string]models.Fare
+	idempotency       map[string]models.IdempotencyRecord
+}
+
+// NewStore creates an empty in-memory store
+func NewStore() *Store {
+	return &Store{
+		hotels:            make(map[string]models.Property),
+		roomTypes:         make(map[string]models.RoomType),
+		hotelReservations: make(map[string]models.HotelReservation),
+		roomNights:        make(map[string]models.RoomNight),
+		ratePlans:         make(map[string]models.RatePlan),
+		roomRates:         make(map[string]models.RoomRate),
+		flights:           make(map[string]models.Flight),
+		bookings:          make(map[string]models.Booking),
+		bookingLocators:   make(map[string]string),
+		passengers:        make(map[string]models.Passenger),
+		seats:             make(map[string]models.Seat),
+		seatHolds:         make(map[string]models.SeatHold),
+		seatsSold:         make(map[string]int),
+		meals:             make(map[string]models.Meal),
+		passengerMeals:    make(map[string]models.MealSelection),
+		fares:             make(map[string]models.Fare),
+		idempotency:       make(map[string]models.IdempotencyRecord),
+	}
+}
+
+// StartSweeper periodically purges expired entries, such as lapsed seat holds
+// and idempotency keys, until ctx is cancelled. Reads already ignore expired
+// entries, so the sweeper only keeps memory usage bounded.
+func (s *Store) StartSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		log.Println("Sweeper disabled: interval must be positive")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if purged := s.sweep(now); purged > 0 {
+					log.Printf("Sweeper purged %d expired entries", purged)
+				}
+			}
+		}
+	}()
+}
+
+// sweep removes every entry that expired before now and returns how many were removed
+func (s *Store) sweep(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for seatID, hold := range s.seatHolds {
+		if !hold.IsActive(now) {
+			delete(s.seatHolds, seatID)
+			purged++
+		}
+	}
+	for key, record := range s.idempotency {
+		if !record.IsActive(now) {
+			delete(s.idempotency, key)
+			purged++
+		}
+	}
+	return purged
+}
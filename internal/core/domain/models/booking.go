@@ -1,12 +1,115 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
+// Booking is an itinerary sold as one unit: an ordered list of segments such as
+// flight legs and hotel stays that are confirmed or cancelled together. Locator
+// is the short record locator customers quote, unique across all bookings.
+// Contact is the only part the owner edits directly. Version goes up by one
+// with every stored change.
 type Booking struct {
-	BookingID     string    `json:"bookingID" dynamodbav:"bookingID"`
-	UserID        string    `json:"userID" dynamodbav:"userID"`
-	FlightID      string    `json:"flightID" dynamodbav:"flightID"`
-	BookingStatus string    `json:"bookingStatus" dynamodbav:"bookingStatus"`
-	CreatedAt     time.Time `json:"createdAt" dynamodbav:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
+	BookingID     string                `json:"bookingID" dynamodbav:"bookingID"`
+	Locator       string                `json:"locator" dynamodbav:"locator"`
+	UserID        string                `json:"userID" dynamodbav:"userID" validate:"required"`
+	BookingStatus BookingStatus         `json:"bookingStatus" dynamodbav:"bookingStatus"`
+	StatusHistory []BookingStatusChange `json:"statusHistory,omitempty" dynamodbav:"statusHistory,omitempty"`
+	Segments      []BookingSegment      `json:"segments" dynamodbav:"segments" validate:"required"`
+	Contact       *BookingContact       `json:"contact,omitempty" dynamodbav:"contact,omitempty"`
+	CreatedAt     time.Time             `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt     time.Time             `json:"updatedAt" dynamodbav:"updatedAt"`
+	Version       int64                 `json:"version" dynamodbav:"version"`
+}
+
+// BookingContact is how to reach the traveller about a booking
+type BookingContact struct {
+	Email string `json:"email,omitempty" dynamodbav:"email,omitempty" validate:"email"`
+	Phone string `json:"phone,omitempty" dynamodbav:"phone,omitempty" validate:"phone"`
+}
+
+// FlightIDs lists the flights booked in the itinerary, in segment order
+func (b Booking) FlightIDs() []string {
+	var flightIDs []string
+	for _, segment := range b.Segments {
+		if segment.Flight != nil {
+			flightIDs = append(flightIDs, segment.Flight.FlightID)
+		}
+	}
+	return flightIDs
+}
+
+// HasFlight reports whether the itinerary includes a leg on the given flight
+func (b Booking) HasFlight(flightID string) bool {
+	for _, id := range b.FlightIDs() {
+		if id == flightID {
+			return true
+		}
+	}
+	return false
+}
+
+// Booking segment types
+const (
+	SegmentFlight = "FLIGHT"
+	SegmentHotel  = "HOTEL"
+)
+
+// SegmentStatus is the state of one segment of a booking with its supplier
+type SegmentStatus string
+
+const (
+	SegmentPending   SegmentStatus = "PENDING"
+	SegmentConfirmed SegmentStatus = "CONFIRMED"
+	SegmentFailed    SegmentStatus = "FAILED"
+	SegmentCancelled SegmentStatus = "CANCELLED"
+)
+
+// BookingSegment is one product in a booking. Exactly one of Flight and Hotel
+// is set, matching Type.
+type BookingSegment struct {
+	SegmentID     string         `json:"segmentID" dynamodbav:"segmentID"`
+	Type          string         `json:"type" dynamodbav:"type" validate:"required,oneof=FLIGHT HOTEL"`
+	Status        SegmentStatus  `json:"status" dynamodbav:"status"`
+	FailureReason string         `json:"failureReason,omitempty" dynamodbav:"failureReason,omitempty"`
+	Flight        *FlightSegment `json:"flight,omitempty" dynamodbav:"flight,omitempty"`
+	Hotel         *HotelSegment  `json:"hotel,omitempty" dynamodbav:"hotel,omitempty"`
+}
+
+// FlightSegment is a flight leg sold at a fare. Quote names the fare and
+// passengers when the booking is created and holds the fresh price afterwards.
+type FlightSegment struct {
+	FlightID string     `json:"flightID" dynamodbav:"flightID" validate:"required"`
+	Quote    *FareQuote `json:"quote" dynamodbav:"quote" validate:"required"`
+}
+
+// HotelSegment is a stay in one room type under a rate plan; the cheapest
+// rate plan is used when RatePlanID is empty. Dates are in StayDateLayout.
+// ReservationID is set once the room has been reserved.
+type HotelSegment struct {
+	HotelID        string     `json:"hotelID" dynamodbav:"hotelID" validate:"required"`
+	RoomTypeID     string     `json:"roomTypeID" dynamodbav:"roomTypeID" validate:"required"`
+	RatePlanID     string     `json:"ratePlanID,omitempty" dynamodbav:"ratePlanID,omitempty"`
+	CheckInDate    string     `json:"checkInDate" dynamodbav:"checkInDate" validate:"required,date"`
+	CheckOutDate   string     `json:"checkOutDate" dynamodbav:"checkOutDate" validate:"required,date,after=CheckInDate"`
+	NumberOfGuests int        `json:"numberOfGuests" dynamodbav:"numberOfGuests" validate:"min=1"`
+	ReservationID  string     `json:"reservationID,omitempty" dynamodbav:"reservationID,omitempty"`
+	Quote          *StayQuote `json:"quote,omitempty" dynamodbav:"quote,omitempty"`
+}
+
+// SegmentFailedError is returned when a booking could not be confirmed because
+// one of its segments failed. Segments confirmed before it have been cancelled.
+type SegmentFailedError struct {
+	BookingID string `json:"bookingID"`
+	SegmentID string `json:"segmentID"`
+	Reason    string `json:"reason"`
+}
+
+func (e *SegmentFailedError) Error() string {
+	return fmt.Sprintf("booking %s failed at segment %s: %s", e.BookingID, e.SegmentID, e.Reason)
+}
+
+func (e *SegmentFailedError) DomainError() *Error {
+	return &Error{Kind: KindConflict, Code: "segment_failed", Message: e.Error(), Details: e, Err: e}
 }
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// BookingStatus is a stage in the booking lifecycle
+type BookingStatus string
+
+const (
+	BookingPending   BookingStatus = "PENDING"
+	BookingHeld      BookingStatus = "HELD"
+	BookingConfirmed BookingStatus = "CONFIRMED"
+	BookingTicketed  BookingStatus = "TICKETED"
+	BookingCancelled BookingStatus = "CANCELLED"
+	BookingRefunded  BookingStatus = "REFUNDED"
+	BookingCompleted BookingStatus = "COMPLETED"
+)
+
+// bookingTransitions lists, for every status, the statuses it may move to next.
+// REFUNDED and COMPLETED are terminal.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingPending:   {BookingHeld, BookingConfirmed, BookingCancelled},
+	BookingHeld:      {BookingConfirmed, BookingCancelled},
+	BookingConfirmed: {BookingTicketed, BookingCancelled},
+	BookingTicketed:  {BookingCompleted, BookingCancelled},
+	BookingCancelled: {BookingRefunded},
+	BookingRefunded:  {},
+	BookingCompleted: {},
+}
+
+// IsValid reports whether s is one of the known booking statuses
+func (s BookingStatus) IsValid() bool {
+	_, ok := bookingTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a booking in status s may move to next
+func (s BookingStatus) CanTransitionTo(next BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// customerStatuses are the statuses a customer may move their own booking to;
+// every other step is taken by staff
+var customerStatuses = map[BookingStatus]bool{
+	BookingConfirmed: true,
+	BookingCancelled: true,
+}
+
+// CustomerMaySet reports whether a customer may move their own booking to s
+func (s BookingStatus) CustomerMaySet() bool {
+	return customerStatuses[s]
+}
+
+// BookingStatusChange records a single step through the booking lifecycle
+type BookingStatusChange struct {
+	From      BookingStatus `json:"from,omitempty" dynamodbav:"from,omitempty"`
+	To        BookingStatus `json:"to" dynamodbav:"to"`
+	ChangedAt time.Time     `json:"changedAt" dynamodbav:"changedAt"`
+	Actor     string        `json:"actor" dynamodbav:"actor"`
+}
+
+// InvalidTransitionError is returned when a booking is asked to move to a
+// status that is not reachable from its current one
+type InvalidTransitionError struct {
+	From BookingStatus
+	To   BookingStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("booking cannot move from %s to %s", e.From, e.To)
+}
+
+func (e *InvalidTransitionError) DomainError() *Error {
+	return &Error{Kind: KindConflict, Code: "invalid_status_transition", Message: e.Error(), Err: e}
+}
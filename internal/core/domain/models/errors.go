@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorKind classifies domain errors by what the caller can do about them
+type ErrorKind string
+
+const (
+	KindNotFound        ErrorKind = "NOT_FOUND"
+	KindValidation      ErrorKind = "VALIDATION"
+	KindConflict        ErrorKind = "CONFLICT"
+	KindUnauthenticated ErrorKind = "UNAUTHENTICATED"
+	KindForbidden       ErrorKind = "FORBIDDEN"
+	KindUnavailable     ErrorKind = "UNAVAILABLE"
+)
+
+// CodeValidationFailed is the code of every validation error
+const CodeValidationFailed = "validation_failed"
+
+// FieldViolation is one problem with one field of a request
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error raised by the domain. Code is a stable machine-readable
+// identifier such as "booking_not_found", Fields lists the offending request
+// fields of validation errors, and Details carries extra data for the client,
+// such as the segment a booking failed on. Err is the underlying cause, which
+// is not shown to clients.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldViolation
+	Details interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil && e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NotFound returns the error for a missing resource, such as "booking" or
+// "room type", with the code "<resource>_not_found"
+func NotFound(resource string) *Error {
+	return &Error{
+		Kind:    KindNotFound,
+		Code:    strings.ReplaceAll(resource, " ", "_") + "_not_found",
+		Message: resource + " not found",
+	}
+}
+
+// Invalid returns a validation error that is not about a single field
+func Invalid(format string, args ...interface{}) *Error {
+	return &Error{Kind: KindValidation, Code: CodeValidationFailed, Message: fmt.Sprintf(format, args...)}
+}
+
+// InvalidField returns a validation error about one field of a request
+func InvalidField(field, format string, args ...interface{}) *Error {
+	message := fmt.Sprintf(format, args...)
+	return &Error{
+		Kind:    KindValidation,
+		Code:    CodeValidationFailed,
+		Message: message,
+		Fields:  []FieldViolation{{Field: field, Message: message}},
+	}
+}
+
+// InvalidFields returns a validation error listing every field of a request
+// at fault; each message completes the sentence started by its field's name
+func InvalidFields(fields []FieldViolation) *Error {
+	message := fields[0].Field + " " + fields[0].Message
+	switch len(fields) {
+	case 1:
+	case 2:
+		message += ", and 1 more problem"
+	default:
+		message = fmt.Sprintf("%s, and %d more problems", message, len(fields)-1)
+	}
+	return &Error{Kind: KindValidation, Code: CodeValidationFailed, Message: message, Fields: fields}
+}
+
+// Conflict returns the error for a request that clashes with the current state of a resource
+func Conflict(code, format string, args ...interface{}) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unauthenticated returns the error for a request that needs a caller
+// identity it did not prove
+func Unauthenticated(code, format string, args ...interface{}) *Error {
+	return &Error{Kind: KindUnauthenticated, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Forbidden returns the error for a request the caller is not allowed to make
+func Forbidden(code, format string, args ...interface{}) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unavailable wraps the failure of a dependency, such as the database, that
+// is expected to recover so the request can be retried later
+func Unavailable(code string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Code: code, Message: "service temporarily unavailable", Err: err}
+}
+
+// AsError finds the domain error in err's chain, including typed errors such
+// as SegmentFailedError that describe themselves as one
+func AsError(err error) (*Error, bool) {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	var typed interface{ DomainError() *Error }
+	if errors.As(err, &typed) {
+		return typed.DomainError(), true
+	}
+	return nil, false
+}
+
+// IsKind reports whether err is a domain error of the given kind
+func IsKind(err error, kind ErrorKind) bool {
+	domainErr, ok := AsError(err)
+	return ok && domainErr.Kind == kind
+}
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Passenger type codes used to price fares
+const (
+	PassengerAdult  = "ADT"
+	PassengerChild  = "CHD"
+	PassengerInfant = "INF"
+)
+
+// IsPassengerType reports whether code is a known passenger type
+func IsPassengerType(code string) bool {
+	switch code {
+	case PassengerAdult, PassengerChild, PassengerInfant:
+		return true
+	}
+	return false
+}
+
+// Fare is a bookable fare class on a flight. All amounts are in minor units
+// (e.g. cents) of Currency.
+type Fare struct {
+	FareID      string               `json:"fareID" dynamodbav:"fareID"`
+	FlightID    string               `json:"flightID" dynamodbav:"flightID"`
+	Cabin       string               `json:"cabin" dynamodbav:"cabin"`
+	BookingCode string               `json:"bookingCode" dynamodbav:"bookingCode"` // RBD, e.g. "Y" or "J"
+	FareBasis   string               `json:"fareBasis,omitempty" dynamodbav:"fareBasis,omitempty"`
+	Currency    string               `json:"currency" dynamodbav:"currency"`
+	Prices      map[string]FarePrice `json:"prices" dynamodbav:"prices"` // keyed by passenger type
+	Rules       FareRules            `json:"rules" dynamodbav:"rules"`
+}
+
+// FarePrice is what one passenger of a given type pays for a fare
+type FarePrice struct {
+	BaseFare int64    `json:"baseFare" dynamodbav:"baseFare"`
+	Taxes    []Charge `json:"taxes,omitempty" dynamodbav:"taxes,omitempty"`
+	Fees     []Charge `json:"fees,omitempty" dynamodbav:"fees,omitempty"`
+}
+
+// Charge is a single tax or fee line
+type Charge struct {
+	Code        string `json:"code" dynamodbav:"code"`
+	Description string `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Amount      int64  `json:"amount" dynamodbav:"amount"`
+}
+
+// FareRules describe what a passenger may do after buying a fare
+type FareRules struct {
+	Refundable   bool  `json:"refundable" dynamodbav:"refundable"`
+	RefundFee    int64 `json:"refundFee" dynamodbav:"refundFee"`
+	Changeable   bool  `json:"changeable" dynamodbav:"changeable"`
+	ChangeFee    int64 `json:"changeFee" dynamodbav:"changeFee"`
+	CheckedBags  int   `json:"checkedBags" dynamodbav:"checkedBags"`
+	CheckedBagKg int   `json:"checkedBagKg" dynamodbav:"checkedBagKg"`
+	CabinBagKg   int   `json:"cabinBagKg" dynamodbav:"cabinBagKg"`
+}
+
+// FareQuote is the priced total of a fare for a mix of passengers. Clients
+// request a quote by sending FareID and Passengers; everything else is filled
+// in by the pricing engine.
+type FareQuote struct {
+	FareID      string         `json:"fareID" dynamodbav:"fareID"`
+	FlightID    string         `json:"flightID" dynamodbav:"flightID"`
+	Passengers  map[string]int `json:"passengers" dynamodbav:"passengers"` // count per passenger type
+	Cabin       string         `json:"cabin" dynamodbav:"cabin"`
+	BookingCode string         `json:"bookingCode" dynamodbav:"bookingCode"`
+	Currency    string         `json:"currency" dynamodbav:"currency"`
+	Lines       []QuoteLine    `json:"lines" dynamodbav:"lines"`
+	BaseTotal   int64          `json:"baseTotal" dynamodbav:"baseTotal"`
+	TaxTotal    int64          `json:"taxTotal" dynamodbav:"taxTotal"`
+	FeeTotal    int64          `json:"feeTotal" dynamodbav:"feeTotal"`
+	Markup      int64          `json:"markup" dynamodbav:"markup"` // the selling tenant's markup on BaseTotal
+	Total       int64          `json:"total" dynamodbav:"total"`
+	Rules       FareRules      `json:"rules" dynamodbav:"rules"`
+	QuotedAt    time.Time      `json:"quotedAt" dynamodbav:"quotedAt"`
+}
+
+// QuoteLine prices every passenger of one type within a quote
+type QuoteLine struct {
+	PassengerType string   `json:"passengerType" dynamodbav:"passengerType"`
+	Count         int      `json:"count" dynamodbav:"count"`
+	BaseFare      int64    `json:"baseFare" dynamodbav:"baseFare"` // per passenger
+	Taxes         []Charge `json:"taxes,omitempty" dynamodbav:"taxes,omitempty"`
+	Fees          []Charge `json:"fees,omitempty" dynamodbav:"fees,omitempty"`
+	UnitTotal     int64    `json:"unitTotal" dynamodbav:"unitTotal"`
+	Subtotal      int64    `json:"subtotal" dynamodbav:"subtotal"`
+}
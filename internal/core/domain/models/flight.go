@@ -2,12 +2,48 @@ package models
 
 import "time"
 
+// Flight is a scheduled flight. Version goes up by one with every stored change.
 type Flight struct {
 	FlightID      string    `json:"flightID" dynamodbav:"flightID"`
-	Airline       string    `json:"airline" dynamodbav:"airline"`
-	Origin        string    `json:"origin" dynamodbav:"origin"`
-	Destination   string    `json:"destination" dynamodbav:"destination"`
-	DepartureTime time.Time `json:"departureTime" dynamodbav:"departureTime"`
-	ArrivalTime   time.Time `json:"arrivalTime" dynamodbav:"arrivalTime"`
+	Airline       string    `json:"airline" dynamodbav:"airline" validate:"required,iata_airline"`
+	Origin        string    `json:"origin" dynamodbav:"origin" validate:"required,iata_airport"`
+	Destination   string    `json:"destination" dynamodbav:"destination" validate:"required,iata_airport,ne=Origin"`
+	DepartureTime time.Time `json:"departureTime" dynamodbav:"departureTime" validate:"required"`
+	ArrivalTime   time.Time `json:"arrivalTime" dynamodbav:"arrivalTime" validate:"required,after=DepartureTime"`
 	AircraftType  string    `json:"aircraftType" dynamodbav:"aircraftType"`
+	Version       int64     `json:"version" dynamodbav:"version"`
+}
+
+// FlightDateLayout is the format of calendar dates used to search flights
+const FlightDateLayout = "2006-01-02"
+
+// Sort orders accepted by flight search
+const (
+	FlightSortDeparture = "departure"
+	FlightSortDuration  = "duration"
+)
+
+// FlightSearch holds the criteria of a flight search
+type FlightSearch struct {
+	Origin      string
+	Destination string
+	Date        string // departure date in FlightDateLayout
+	Passengers  int
+	SortBy      string
+}
+
+// FlightSearchResult is a flight matching a search, with the seats still open for sale
+type FlightSearchResult struct {
+	Flight
+	AvailableSeats int `json:"availableSeats"`
+}
+
+// Duration returns the scheduled time between departure and arrival
+func (f Flight) Duration() time.Duration {
+	return f.ArrivalTime.Sub(f.DepartureTime)
+}
+
+// DepartureDate returns the local calendar day of departure in FlightDateLayout
+func (f Flight) DepartureDate() string {
+	return f.DepartureTime.Format(FlightDateLayout)
 }
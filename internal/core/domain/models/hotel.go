@@ -1,25 +1,129 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+)
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
 
 type Asset struct {
 	Type   string `json:"type"`
 	S3Link string `json:"s3Link"`
 }
 
-type Hotel struct {
+// Property is a hotel as a place: where it is and what it offers. Rooms and
+// reservations are stored separately as RoomType and HotelReservation.
+// Version goes up by one with every stored change.
+type Property struct {
+	HotelID     string    `json:"hotelID" dynamodbav:"hotelID"`
+	Name        string    `json:"name" dynamodbav:"name" validate:"required"`
+	Chain       string    `json:"chain,omitempty" dynamodbav:"chain,omitempty"` // empty for independent hotels
+	Description string    `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Address     Address   `json:"address" dynamodbav:"address"`
+	Location    GeoPoint  `json:"location" dynamodbav:"location"`
+	Amenities   []string  `json:"amenities,omitempty" dynamodbav:"amenities,omitempty"`
+	StarRating  int       `json:"starRating" dynamodbav:"starRating" validate:"min=0,max=5"`
+	Assets      []Asset   `json:"assets,omitempty" dynamodbav:"assets,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" dynamodbav:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
+	Version     int64     `json:"version" dynamodbav:"version"`
+}
+
+type Address struct {
+	Line1      string `json:"line1" dynamodbav:"line1"`
+	Line2      string `json:"line2,omitempty" dynamodbav:"line2,omitempty"`
+	City       string `json:"city" dynamodbav:"city"`
+	State      string `json:"state,omitempty" dynamodbav:"state,omitempty"`
+	PostalCode string `json:"postalCode,omitempty" dynamodbav:"postalCode,omitempty"`
+	Country    string `json:"country" dynamodbav:"country" validate:"country"` // ISO 3166-1 alpha-2
+}
+
+// GeoPoint is a WGS84 coordinate in decimal degrees
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude" dynamodbav:"latitude" validate:"min=-90,max=90"`
+	Longitude float64 `json:"longitude" dynamodbav:"longitude" validate:"min=-180,max=180"`
+}
+
+// IsZero reports whether the point was never set
+func (p GeoPoint) IsZero() bool {
+	return p.Latitude == 0 && p.Longitude == 0
+}
+
+// DistanceKm returns the great-circle distance to another point
+func (p GeoPoint) DistanceKm(other GeoPoint) float64 {
+	lat1, lat2 := p.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (other.Longitude - p.Longitude) * math.Pi / 180
+
+	// Haversine formula
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// NearbyHotel is a hotel found by a radius search
+type NearbyHotel struct {
+	Property
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// RoomType is a category of identical rooms in a property
+type RoomType struct {
+	RoomTypeID  string      `json:"roomTypeID" dynamodbav:"roomTypeID"`
+	HotelID     string      `json:"hotelID" dynamodbav:"hotelID"`
+	Name        string      `json:"name" dynamodbav:"name" validate:"required"`
+	Description string      `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	MaxAdults   int         `json:"maxAdults" dynamodbav:"maxAdults" validate:"min=0"`
+	MaxChildren int         `json:"maxChildren" dynamodbav:"maxChildren" validate:"min=0"`
+	MaxGuests   int         `json:"maxGuests" dynamodbav:"maxGuests" validate:"min=1"`
+	Beds        []BedConfig `json:"beds,omitempty" dynamodbav:"beds,omitempty"`
+	Amenities   []string    `json:"amenities,omitempty" dynamodbav:"amenities,omitempty"`
+	TotalRooms  int         `json:"totalRooms" dynamodbav:"totalRooms" validate:"min=0"` // rooms of this type in the property
+}
+
+// BedConfig is a number of beds of one kind, e.g. two TWIN beds
+type BedConfig struct {
+	Type  string `json:"type" dynamodbav:"type" validate:"required,oneof=SINGLE TWIN DOUBLE QUEEN KING SOFA"`
+	Count int    `json:"count" dynamodbav:"count" validate:"min=1"`
+}
+
+// Bed types accepted in a room's bed configuration
+const (
+	BedSingle = "SINGLE"
+	BedTwin   = "TWIN"
+	BedDouble = "DOUBLE"
+	BedQueen  = "QUEEN"
+	BedKing   = "KING"
+	BedSofa   = "SOFA"
+)
+
+// Hotel reservation statuses
+const (
+	ReservationConfirmed = "CONFIRMED"
+	ReservationCancelled = "CANCELLED"
+)
+
+// HotelReservation is a stay in one room type of a property, sold as part of a booking
+type HotelReservation struct {
+	ReservationID     string    `json:"reservationID" dynamodbav:"reservationID"`
 	HotelID           string    `json:"hotelID" dynamodbav:"hotelID"`
-	BookingID         string    `json:"bookingID" dynamodbav:"bookingID"`
+	RoomTypeID        string    `json:"roomTypeID" dynamodbav:"roomTypeID" validate:"required"`
+	RatePlanID        string    `json:"ratePlanID,omitempty" dynamodbav:"ratePlanID,omitempty"`
+	BookingID         string    `json:"bookingID" dynamodbav:"bookingID" validate:"required"`
 	UserID            string    `json:"userID" dynamodbav:"userID"`
-	CheckInDate       time.Time `json:"checkInDate" dynamodbav:"checkInDate"`
-	CheckOutDate      time.Time `json:"checkOutDate" dynamodbav:"checkOutDate"`
+	CheckInDate       time.Time `json:"checkInDate" dynamodbav:"checkInDate" validate:"required"`
+	CheckOutDate      time.Time `json:"checkOutDate" dynamodbav:"checkOutDate" validate:"required,after=CheckInDate"`
 	IsCheckinFlexible bool      `json:"isCheckinFlexible" dynamodbav:"isCheckinFlexible"`
-	BookingStatus     string    `json:"bookingStatus" dynamodbav:"bookingStatus"`
+	NumberOfGuests    int       `json:"numberOfGuests" dynamodbav:"numberOfGuests" validate:"min=1"`
+	Status            string    `json:"status" dynamodbav:"status"`
+	PaymentStatus     string    `json:"paymentStatus,omitempty" dynamodbav:"paymentStatus,omitempty"`
+	SpecialRequests   string    `json:"specialRequests,omitempty" dynamodbav:"specialRequests,omitempty"`
 	CreatedAt         time.Time `json:"createdAt" dynamodbav:"createdAt"`
 	UpdatedAt         time.Time `json:"updatedAt" dynamodbav:"updatedAt"`
-	PaymentStatus     string    `json:"paymentStatus" dynamodbav:"paymentStatus"`
-	RoomType          string    `json:"roomType" dynamodbav:"roomType"`
-	NumberOfGuests    int       `json:"numberOfGuests" dynamodbav:"numberOfGuests"`
-	SpecialRequests   string    `json:"specialRequests" dynamodbav:"specialRequests"`
-	Assets            []Asset   `json:"assets" dynamodbav:"assets"`
+}
+
+// Nights returns the number of nights between check-in and check-out
+func (r HotelReservation) Nights() int {
+	return int(r.CheckOutDate.Sub(r.CheckInDate).Hours() / 24)
 }
@@ -0,0 +1,46 @@
+package models
+
+// Sort orders accepted by a hotel search
+const (
+	HotelSortPrice    = "price"
+	HotelSortStars    = "stars"
+	HotelSortDistance = "distance"
+)
+
+// HotelSearch describes the stay a guest wants to book, either in a city or
+// within RadiusKm of Near. Dates are in StayDateLayout; the guests are spread
+// over Rooms rooms of the same type.
+type HotelSearch struct {
+	City         string
+	Country      string
+	Near         *GeoPoint
+	RadiusKm     float64
+	CheckInDate  string
+	CheckOutDate string
+	Guests       int
+	Rooms        int
+	MinStars     int
+	Amenities    []string
+	SortBy       string
+}
+
+// HotelSearchResult is a bookable hotel with the room types that can sell the
+// whole stay, cheapest first. LowestTotal is the price of the first offer.
+type HotelSearchResult struct {
+	Property
+	DistanceKm  *float64    `json:"distanceKm,omitempty"` // set on searches by location
+	Offers      []RoomOffer `json:"offers"`
+	Currency    string      `json:"currency"`
+	LowestTotal int64       `json:"lowestTotal"`
+}
+
+// RoomOffer is the cheapest way of booking the requested rooms of one room
+// type. Quote prices a single room; Total covers every room of the search.
+type RoomOffer struct {
+	RoomTypeID     string    `json:"roomTypeID"`
+	Name           string    `json:"name"`
+	MaxGuests      int       `json:"maxGuests"`
+	RoomsAvailable int       `json:"roomsAvailable"`
+	Quote          StayQuote `json:"quote"`
+	Total          int64     `json:"total"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key header and,
+// once it has completed, the response that was sent, so that retries with the
+// same key get the same response instead of repeating the request. Fingerprint
+// identifies the method, path and body the key was first used with. Until it
+// completes, the request holds the key up to LockedUntil; after that a retry
+// may take the key over, since the request may have died with its process.
+type IdempotencyRecord struct {
+	Key         string            `json:"key" dynamodbav:"idempotencyKey"`
+	Fingerprint string            `json:"fingerprint" dynamodbav:"fingerprint"`
+	Completed   bool              `json:"completed" dynamodbav:"completed"`
+	StatusCode  int               `json:"statusCode,omitempty" dynamodbav:"statusCode,omitempty"`
+	ContentType string            `json:"contentType,omitempty" dynamodbav:"contentType,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty" dynamodbav:"headers,omitempty"`
+	Body        []byte            `json:"body,omitempty" dynamodbav:"body,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt" dynamodbav:"createdAt"`
+	LockedUntil time.Time         `json:"lockedUntil" dynamodbav:"lockedUntil,unixtime"`
+	ExpiresAt   time.Time         `json:"expiresAt" dynamodbav:"expiresAt,unixtime"`
+}
+
+// IsActive reports whether the key is still remembered at the given time
+func (r IdempotencyRecord) IsActive(now time.Time) bool {
+	return now.Before(r.ExpiresAt)
+}
+
+// IsLocked reports whether the request that claimed the key may still be
+// running at the given time
+func (r IdempotencyRecord) IsLocked(now time.Time) bool {
+	return !r.Completed && now.Before(r.LockedUntil)
+}
+
+// CanTakeOver reports whether a request with the given fingerprint may claim
+// the key of r at the given time: once r has expired, or when r is a request
+// just like it that stopped holding the key without completing
+func (r IdempotencyRecord) CanTakeOver(fingerprint string, now time.Time) bool {
+	if !r.IsActive(now) {
+		return true
+	}
+	return !r.Completed && !r.IsLocked(now) && r.Fingerprint == fingerprint
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxItineraryStops is the largest number of connections an itinerary may have
+const MaxItineraryStops = 2
+
+// ItinerarySearch holds the criteria of a connecting-itinerary search
+type ItinerarySearch struct {
+	Origin      string
+	Destination string
+	Date        string // departure date of the first leg in FlightDateLayout
+	MaxStops    int
+}
+
+// Itinerary is a journey made of one or more flights, each departing from the
+// airport the previous one arrived at
+type Itinerary struct {
+	Legs                 []Flight     `json:"legs"`
+	Connections          []Connection `json:"connections,omitempty"`
+	Stops                int          `json:"stops"`
+	DepartureTime        time.Time    `json:"departureTime"`
+	ArrivalTime          time.Time    `json:"arrivalTime"`
+	TotalDurationMinutes int          `json:"totalDurationMinutes"`
+}
+
+// Connection describes the layover between two consecutive legs
+type Connection struct {
+	Airport         string `json:"airport"`
+	DurationMinutes int    `json:"durationMinutes"`
+}
+
+// TotalDuration returns the time from the first departure to the last arrival
+func (i Itinerary) TotalDuration() time.Duration {
+	return i.ArrivalTime.Sub(i.DepartureTime)
+}
+
+// ConnectionTime bounds the layover allowed at an airport
+type ConnectionTime struct {
+	Min time.Duration `json:"min"`
+	Max time.Duration `json:"max"`
+}
+
+// ConnectionRules holds the default connection time and per-airport overrides,
+// keyed by IATA airport code
+type ConnectionRules struct {
+	Default  ConnectionTime
+	Airports map[string]ConnectionTime
+}
+
+// For returns the connection time that applies at airport
+func (r ConnectionRules) For(airport string) ConnectionTime {
+	if window, ok := r.Airports[strings.ToUpper(airport)]; ok {
+		return window
+	}
+	return r.Default
+}
@@ -1,7 +1,59 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
+// DietaryCodes lists the IATA special meal codes a catalogue meal can be tagged with
+var DietaryCodes = map[string]string{
+	"AVML": "Asian vegetarian",
+	"BBML": "Baby meal",
+	"BLML": "Bland meal",
+	"CHML": "Child meal",
+	"DBML": "Diabetic meal",
+	"FPML": "Fruit platter",
+	"GFML": "Gluten-free meal",
+	"HNML": "Hindu meal",
+	"KSML": "Kosher meal",
+	"LCML": "Low-calorie meal",
+	"LFML": "Low-fat meal",
+	"LSML": "Low-salt meal",
+	"MOML": "Muslim meal",
+	"NLML": "Lactose-free meal",
+	"RVML": "Raw vegetarian meal",
+	"SFML": "Seafood meal",
+	"VGML": "Vegan meal",
+	"VJML": "Jain vegetarian meal",
+	"VLML": "Vegetarian lacto-ovo meal",
+	"VOML": "Vegetarian oriental meal",
+}
+
 type Meal struct {
-	MealID           string `json:"mealID" dynamodbav:"mealID"`
-	Description      string `json:"description" dynamodbav:"description"`
-	AvailableClasses string `json:"availableClasses" dynamodbav:"availableClasses"`
+	MealID           string       `json:"mealID" dynamodbav:"mealID"`
+	Description      string       `json:"description" dynamodbav:"description"`
+	AvailableClasses CabinClasses `json:"availableClasses" dynamodbav:"availableClasses"`
+	DietaryTags      []string     `json:"dietaryTags,omitempty" dynamodbav:"dietaryTags,omitempty"`
+}
+
+// MealSelection records the catalogue meal a passenger chose for their cabin class
+type MealSelection struct {
+	PassengerID string    `json:"passengerID" dynamodbav:"passengerID"`
+	BookingID   string    `json:"bookingID" dynamodbav:"bookingID"`
+	MealID      string    `json:"mealID" dynamodbav:"mealID"`
+	Class       string    `json:"class" dynamodbav:"class"`
+	SelectedAt  time.Time `json:"selectedAt" dynamodbav:"selectedAt"`
+}
+
+// CabinClasses is the list of cabin classes a meal is served in
+type CabinClasses []string
+
+// Contains reports whether class is one of the listed cabin classes
+func (c CabinClasses) Contains(class string) bool {
+	for _, available := range c {
+		if strings.EqualFold(available, class) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Page sizes of list endpoints
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageRequest asks for one page of a list
+type PageRequest struct {
+	Limit  int    // at most MaxPageLimit, DefaultPageLimit when zero
+	Cursor string // NextCursor of the previous page, empty for the first page
+	Sort   string // a sortable field, prefixed with - for descending order; empty for storage order
+}
+
+// SortField splits Sort into the field name and whether the order is descending
+func (p PageRequest) SortField() (string, bool) {
+	if strings.HasPrefix(p.Sort, "-") {
+		return p.Sort[1:], true
+	}
+	return p.Sort, false
+}
+
+// sortableTime formats times at a fixed width so they sort as strings
+const sortableTime = "2006-01-02T15:04:05.000000000Z"
+
+// FlightSortKeys lists the fields flights can be sorted on, each mapped to a
+// key that orders flights when compared as strings
+var FlightSortKeys = map[string]func(Flight) string{
+	"departureTime": func(f Flight) string { return f.DepartureTime.UTC().Format(sortableTime) },
+	"arrivalTime":   func(f Flight) string { return f.ArrivalTime.UTC().Format(sortableTime) },
+	"airline":       func(f Flight) string { return strings.ToLower(f.Airline) },
+}
+
+// HotelSortKeys lists the fields hotels can be sorted on
+var HotelSortKeys = map[string]func(Property) string{
+	"name":       func(p Property) string { return strings.ToLower(p.Name) },
+	"starRating": func(p Property) string { return fmt.Sprintf("%02d", p.StarRating) },
+	"createdAt":  func(p Property) string { return p.CreatedAt.UTC().Format(sortableTime) },
+}
+
+// BookingSortKeys lists the fields bookings can be sorted on
+var BookingSortKeys = map[string]func(Booking) string{
+	"createdAt":     func(b Booking) string { return b.CreatedAt.UTC().Format(sortableTime) },
+	"updatedAt":     func(b Booking) string { return b.UpdatedAt.UTC().Format(sortableTime) },
+	"bookingStatus": func(b Booking) string { return string(b.BookingStatus) },
+}
+
+// FlightQuery filters the flight list. From and To bound the departure date,
+// both inclusive, in FlightDateLayout.
+type FlightQuery struct {
+	Airline     string
+	Origin      string
+	Destination string
+	From        string
+	To          string
+	Page        PageRequest
+}
+
+// Matches reports whether a flight passes the query's filters
+func (q FlightQuery) Matches(flight Flight) bool {
+	date := flight.DepartureDate()
+	return (q.Airline == "" || flight.Airline == q.Airline) &&
+		(q.Origin == "" || flight.Origin == q.Origin) &&
+		(q.Destination == "" || flight.Destination == q.Destination) &&
+		(q.From == "" || date >= q.From) &&
+		(q.To == "" || date <= q.To)
+}
+
+// FlightPage is one page of the flight list
+type FlightPage struct {
+	Items      []Flight `json:"items"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// HotelQuery filters the hotel list
+type HotelQuery struct {
+	City     string
+	Country  string
+	MinStars int
+	Page     PageRequest
+}
+
+// Matches reports whether a hotel passes the query's filters
+func (q HotelQuery) Matches(hotel Property) bool {
+	return (q.City == "" || strings.EqualFold(strings.TrimSpace(hotel.Address.City), q.City)) &&
+		(q.Country == "" || strings.EqualFold(hotel.Address.Country, q.Country)) &&
+		hotel.StarRating >= q.MinStars
+}
+
+// HotelPage is one page of the hotel list
+type HotelPage struct {
+	Items      []Property `json:"items"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// BookingQuery filters the booking list. From and To bound the UTC creation
+// date, both inclusive, in FlightDateLayout.
+type BookingQuery struct {
+	UserID string
+	Status BookingStatus
+	From   string
+	To     string
+	Page   PageRequest
+}
+
+// Matches reports whether a booking passes the query's filters
+func (q BookingQuery) Matches(booking Booking) bool {
+	date := booking.CreatedAt.UTC().Format(FlightDateLayout)
+	return (q.UserID == "" || booking.UserID == q.UserID) &&
+		(q.Status == "" || booking.BookingStatus == q.Status) &&
+		(q.From == "" || date >= q.From) &&
+		(q.To == "" || date <= q.To)
+}
+
+// BookingPage is one page of the booking list
+type BookingPage struct {
+	Items      []Booking `json:"items"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
+
+// NextDay returns the calendar day after date, both in FlightDateLayout
+func NextDay(date string) (string, error) {
+	day, err := time.Parse(FlightDateLayout, date)
+	if err != nil {
+		return "", err
+	}
+	return day.AddDate(0, 0, 1).Format(FlightDateLayout), nil
+}
@@ -3,8 +3,8 @@ package models
 type Passenger struct {
 	PassengerID    string `json:"passengerID" dynamodbav:"passengerID"`
 	BookingID      string `json:"bookingID" dynamodbav:"bookingID"`
-	Name           string `json:"name" dynamodbav:"name"`
-	Age            int    `json:"age" dynamodbav:"age"`
-	Gender         string `json:"gender" dynamodbav:"gender"`
-	PassportNumber string `json:"passportNumber" dynamodbav:"passportNumber"`
+	Name           string `json:"name" dynamodbav:"name" validate:"required"`
+	Age            int    `json:"age" dynamodbav:"age" validate:"min=0,max=130"`
+	Gender         string `json:"gender" dynamodbav:"gender" validate:"oneof=M F X"`
+	PassportNumber string `json:"passportNumber" dynamodbav:"passportNumber" validate:"passport"`
 }
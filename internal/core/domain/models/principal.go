@@ -0,0 +1,83 @@
+package models
+
+import "context"
+
+// Codes of requests turned away for who made them
+const (
+	CodeAuthenticationRequired = "authentication_required"
+	CodeInsufficientRole       = "insufficient_role"
+	CodeBookingAccessDenied    = "booking_access_denied"
+	CodeStatusChangeDenied     = "status_change_denied"
+)
+
+// Role is what a caller may do
+type Role string
+
+const (
+	// RoleCustomer books trips for themselves
+	RoleCustomer Role = "customer"
+	// RoleAgent books and manages trips on behalf of any customer
+	RoleAgent Role = "agent"
+	// RoleOpsAdmin runs the catalogue of flights and hotels and has every
+	// permission of an agent
+	RoleOpsAdmin Role = "ops-admin"
+)
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleCustomer, RoleAgent, RoleOpsAdmin:
+		return true
+	}
+	return false
+}
+
+// Principal is the authenticated caller of a request. TenantID names the
+// travel agency the caller belongs to; it is empty for tokens that name none,
+// whose callers belong to the default tenant.
+type Principal struct {
+	UserID   string
+	Roles    []Role
+	TenantID string
+}
+
+// HasRole reports whether the principal holds any of roles
+func (p *Principal) HasRole(roles ...Role) bool {
+	for _, held := range p.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CanActForAnyUser reports whether the principal may see and change other
+// users' bookings
+func (p *Principal) CanActForAnyUser() bool {
+	return p.HasRole(RoleAgent, RoleOpsAdmin)
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// RequirePrincipal returns the principal carried by ctx, or an
+// Unauthenticated error when the request is anonymous
+func RequirePrincipal(ctx context.Context) (*Principal, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, Unauthenticated(CodeAuthenticationRequired, "authentication required")
+	}
+	return principal, nil
+}
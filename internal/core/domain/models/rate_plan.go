@@ -0,0 +1,89 @@
+package models
+
+// Well-known rate plan codes. Hotels may define others.
+const (
+	RatePlanBestAvailable     = "BAR"
+	RatePlanNonRefundable     = "NRF"
+	RatePlanBreakfastIncluded = "BB"
+)
+
+// RatePlan is a way of selling a hotel's rooms, such as the best available
+// rate or a non-refundable rate. Prices and restrictions are set per room
+// type and night as RoomRate rows.
+type RatePlan struct {
+	RatePlanID        string `json:"ratePlanID" dynamodbav:"ratePlanID"`
+	HotelID           string `json:"hotelID" dynamodbav:"hotelID"`
+	Code              string `json:"code" dynamodbav:"code"`
+	Name              string `json:"name" dynamodbav:"name"`
+	Description       string `json:"description,omitempty" dynamodbav:"description,omitempty"`
+	Currency          string `json:"currency" dynamodbav:"currency"`
+	Refundable        bool   `json:"refundable" dynamodbav:"refundable"`
+	BreakfastIncluded bool   `json:"breakfastIncluded" dynamodbav:"breakfastIncluded"`
+}
+
+// RoomRate is the price and stay restrictions of one room type under one rate
+// plan for one night. Amounts are in minor units of the rate plan's currency.
+type RoomRate struct {
+	RatePlanID        string `json:"ratePlanID" dynamodbav:"ratePlanID"`
+	RoomTypeID        string `json:"roomTypeID" dynamodbav:"roomTypeID"`
+	HotelID           string `json:"hotelID" dynamodbav:"hotelID"`
+	Date              string `json:"date" dynamodbav:"date"` // in StayDateLayout
+	Amount            int64  `json:"amount" dynamodbav:"amount"`
+	IncludedGuests    int    `json:"includedGuests" dynamodbav:"includedGuests"`
+	ExtraGuestAmount  int64  `json:"extraGuestAmount" dynamodbav:"extraGuestAmount"`
+	MinStay           int    `json:"minStay,omitempty" dynamodbav:"minStay,omitempty"` // nights, checked on the arrival date
+	MaxStay           int    `json:"maxStay,omitempty" dynamodbav:"maxStay,omitempty"` // nights, checked on the arrival date
+	ClosedToArrival   bool   `json:"closedToArrival" dynamodbav:"closedToArrival"`
+	ClosedToDeparture bool   `json:"closedToDeparture" dynamodbav:"closedToDeparture"`
+}
+
+// RoomRateUpdate sets the same price and restrictions on every night from up
+// to but excluding to
+type RoomRateUpdate struct {
+	RoomTypeID        string `json:"roomTypeID"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Amount            int64  `json:"amount"`
+	IncludedGuests    int    `json:"includedGuests"`
+	ExtraGuestAmount  int64  `json:"extraGuestAmount"`
+	MinStay           int    `json:"minStay"`
+	MaxStay           int    `json:"maxStay"`
+	ClosedToArrival   bool   `json:"closedToArrival"`
+	ClosedToDeparture bool   `json:"closedToDeparture"`
+}
+
+// StayQuoteRequest asks for the price of a stay in one room type. Dates are in
+// StayDateLayout.
+type StayQuoteRequest struct {
+	RoomTypeID     string `json:"roomTypeID"`
+	RatePlanID     string `json:"ratePlanID,omitempty"` // every rate plan is quoted when empty
+	CheckInDate    string `json:"checkInDate"`
+	CheckOutDate   string `json:"checkOutDate"`
+	NumberOfGuests int    `json:"numberOfGuests"`
+}
+
+// StayQuote is the price of a stay in one room type under one rate plan
+type StayQuote struct {
+	HotelID           string       `json:"hotelID" dynamodbav:"hotelID"`
+	RoomTypeID        string       `json:"roomTypeID" dynamodbav:"roomTypeID"`
+	RatePlanID        string       `json:"ratePlanID" dynamodbav:"ratePlanID"`
+	RatePlanCode      string       `json:"ratePlanCode" dynamodbav:"ratePlanCode"`
+	Currency          string       `json:"currency" dynamodbav:"currency"`
+	CheckInDate       string       `json:"checkInDate" dynamodbav:"checkInDate"`
+	CheckOutDate      string       `json:"checkOutDate" dynamodbav:"checkOutDate"`
+	NumberOfGuests    int          `json:"numberOfGuests" dynamodbav:"numberOfGuests"`
+	Refundable        bool         `json:"refundable" dynamodbav:"refundable"`
+	BreakfastIncluded bool         `json:"breakfastIncluded" dynamodbav:"breakfastIncluded"`
+	Nights            []NightPrice `json:"nights" dynamodbav:"nights"`
+	Markup            int64        `json:"markup" dynamodbav:"markup"` // the selling tenant's markup on the nights
+	Total             int64        `json:"total" dynamodbav:"total"`
+}
+
+// NightPrice is one night of a stay quote
+type NightPrice struct {
+	Date        string `json:"date" dynamodbav:"date"`
+	RoomAmount  int64  `json:"roomAmount" dynamodbav:"roomAmount"`
+	ExtraGuests int    `json:"extraGuests" dynamodbav:"extraGuests"`
+	ExtraAmount int64  `json:"extraAmount" dynamodbav:"extraAmount"`
+	Total       int64  `json:"total" dynamodbav:"total"`
+}
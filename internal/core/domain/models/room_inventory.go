@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// StayDateLayout is the format of the nights in the room inventory calendar
+const StayDateLayout = "2006-01-02"
+
+// RoomNight is the inventory of one room type for one night
+type RoomNight struct {
+	HotelID    string `json:"hotelID" dynamodbav:"hotelID"`
+	RoomTypeID string `json:"roomTypeID" dynamodbav:"roomTypeID"`
+	Date       string `json:"date" dynamodbav:"date"` // in StayDateLayout
+	Total      int    `json:"total" dynamodbav:"total"`
+	Sold       int    `json:"sold" dynamodbav:"sold"`
+	Held       int    `json:"held" dynamodbav:"held"`
+}
+
+// Available returns the rooms still open for sale that night
+func (n RoomNight) Available() int {
+	if available := n.Total - n.Sold - n.Held; available > 0 {
+		return available
+	}
+	return 0
+}
+
+// RoomAvailability is the inventory calendar of one room type
+type RoomAvailability struct {
+	RoomTypeID string             `json:"roomTypeID"`
+	Name       string             `json:"name"`
+	Nights     []RoomNightSummary `json:"nights"`
+}
+
+// RoomNightSummary is a RoomNight as shown in the availability calendar
+type RoomNightSummary struct {
+	Date      string `json:"date"`
+	Total     int    `json:"total"`
+	Sold      int    `json:"sold"`
+	Held      int    `json:"held"`
+	Available int    `json:"available"`
+}
+
+// RoomAllotment sets the rooms for sale on the nights from up to but excluding to
+type RoomAllotment struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Total int    `json:"total"`
+}
+
+// StayDates is a check-in and check-out pair
+type StayDates struct {
+	CheckInDate  time.Time `json:"checkInDate"`
+	CheckOutDate time.Time `json:"checkOutDate"`
+}
+
+// RoomsSoldOutError is returned when a room type has no room left on at least
+// one night of a stay. Alternatives lists nearby stays of the same length that
+// are still available, when the guest said their check-in is flexible.
+type RoomsSoldOutError struct {
+	RoomTypeID   string      `json:"roomTypeID"`
+	CheckInDate  time.Time   `json:"checkInDate"`
+	CheckOutDate time.Time   `json:"checkOutDate"`
+	Alternatives []StayDates `json:"alternatives,omitempty"`
+}
+
+func (e *RoomsSoldOutError) Error() string {
+	return fmt.Sprintf("room type %s is sold out between %s and %s",
+		e.RoomTypeID, e.CheckInDate.Format(StayDateLayout), e.CheckOutDate.Format(StayDateLayout))
+}
+
+func (e *RoomsSoldOutError) DomainError() *Error {
+	return &Error{Kind: KindConflict, Code: "rooms_sold_out", Message: e.Error(), Details: e, Err: e}
+}
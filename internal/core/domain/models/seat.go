@@ -1,9 +1,57 @@
 package models
 
+import "time"
+
+// Cabin classes a seat can belong to
+const (
+	CabinFirst          = "FIRST"
+	CabinBusiness       = "BUSINESS"
+	CabinPremiumEconomy = "PREMIUM_ECONOMY"
+	CabinEconomy        = "ECONOMY"
+)
+
+// IsCabinClass reports whether class is one of the known cabin classes
+func IsCabinClass(class string) bool {
+	switch class {
+	case CabinFirst, CabinBusiness, CabinPremiumEconomy, CabinEconomy:
+		return true
+	}
+	return false
+}
+
 type Seat struct {
 	SeatID      string `json:"seatID" dynamodbav:"seatID"`
 	FlightID    string `json:"flightID" dynamodbav:"flightID"`
 	SeatNumber  string `json:"seatNumber" dynamodbav:"seatNumber"`
 	Class       string `json:"class" dynamodbav:"class"`
 	IsAvailable bool   `json:"isAvailable" dynamodbav:"isAvailable"`
+	PassengerID string `json:"passengerID,omitempty" dynamodbav:"passengerID,omitempty"`
+	BookingID   string `json:"bookingID,omitempty" dynamodbav:"bookingID,omitempty"`
+}
+
+// FlightInventory counts the seats of a flight and how many of them confirmed
+// bookings have taken
+type FlightInventory struct {
+	FlightID  string `json:"flightID" dynamodbav:"flightID"`
+	Capacity  int    `json:"capacity" dynamodbav:"capacity"`
+	SeatsSold int    `json:"seatsSold" dynamodbav:"seatsSold"`
+}
+
+// SeatFilter narrows down the seats returned for a flight
+type SeatFilter struct {
+	Class     string
+	Available *bool
+}
+
+// SeatHold reserves a seat for a booking until ExpiresAt while checkout completes
+type SeatHold struct {
+	SeatID    string    `json:"seatID" dynamodbav:"seatID"`
+	FlightID  string    `json:"flightID" dynamodbav:"flightID"`
+	BookingID string    `json:"bookingID" dynamodbav:"bookingID"`
+	ExpiresAt time.Time `json:"expiresAt" dynamodbav:"expiresAt,unixtime"`
+}
+
+// IsActive reports whether the hold still blocks the seat at the given time
+func (h SeatHold) IsActive(now time.Time) bool {
+	return now.Before(h.ExpiresAt)
 }
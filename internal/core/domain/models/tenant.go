@@ -0,0 +1,80 @@
+package models
+
+import "context"
+
+// Codes of requests turned away for the tenant they were made for
+const (
+	CodeTenantRequired     = "tenant_required"
+	CodeUnknownTenant      = "unknown_tenant"
+	CodeInvalidAPIKey      = "invalid_api_key"
+	CodeTenantMismatch     = "tenant_mismatch"
+	CodeSupplierNotAllowed = "supplier_not_allowed"
+)
+
+// Tenant is a travel agency served by this deployment. Its catalogue, trips
+// and users are kept apart from those of every other tenant.
+type Tenant struct {
+	ID        string `json:"tenantID"`
+	BrandName string `json:"brandName"`
+	// Markup is added on top of the supplier price of every fare and stay
+	// the tenant quotes
+	Markup Markup `json:"markupBasisPoints"`
+	// AllowedSuppliers are the airlines and hotel chains the tenant sells
+	AllowedSuppliers SupplierAllowList `json:"allowedSuppliers"`
+	// APIKeys identify requests made on behalf of the tenant by its own systems
+	APIKeys []string `json:"-"`
+}
+
+// SupplierAllowList names the suppliers a tenant may sell. An empty list
+// allows every supplier of its kind.
+type SupplierAllowList struct {
+	// Airlines are IATA airline designators, e.g. "AI"
+	Airlines []string `json:"airlines,omitempty"`
+	// HotelChains are chain codes as set on hotels. Independent hotels, which
+	// belong to no chain, are not sold once the list names any chain.
+	HotelChains []string `json:"hotelChains,omitempty"`
+}
+
+// AllowsAirline reports whether the tenant may sell flights of airline
+func (t *Tenant) AllowsAirline(airline string) bool {
+	return allowed(t.AllowedSuppliers.Airlines, airline)
+}
+
+// AllowsHotelChain reports whether the tenant may sell stays at hotels of
+// chain, which is empty for independent hotels
+func (t *Tenant) AllowsHotelChain(chain string) bool {
+	return allowed(t.AllowedSuppliers.HotelChains, chain)
+}
+
+func allowed(list []string, code string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, entry := range list {
+		if entry == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Markup is a surcharge in basis points (hundredths of a percent) of a price
+type Markup int64
+
+// On returns the markup on amount, rounded half up to the minor unit
+func (m Markup) On(amount int64) int64 {
+	return (amount*int64(m) + 5000) / 10000
+}
+
+type tenantKey struct{}
+
+// ContextWithTenant returns a copy of ctx carrying tenant
+func ContextWithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant carried by ctx, if any
+func TenantFromContext(ctx context.Context) (*Tenant, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(*Tenant)
+	return tenant, ok && tenant != nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "travel-backend/pkg/validate"
+
+// Validate checks v against the rules in its validate struct tags and returns
+// a validation error listing every violation, or nil when v is valid
+func Validate(v interface{}) error {
+	violations := validate.Struct(v)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]FieldViolation, len(violations))
+	for i, violation := range violations {
+		fields[i] = FieldViolation{Field: violation.Field, Message: violation.Message}
+	}
+	return InvalidFields(fields)
+}
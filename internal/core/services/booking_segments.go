@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+// segmentSupplier sells one type of booking segment. price checks a new
+// segment, including that the tenant sells its airline or hotel chain, and
+// fills in its quote, confirm books it with the supplier and cancel undoes a
+// confirmed segment, which is how a booking that fails part way through is
+// compensated.
+type segmentSupplier interface {
+	price(segment *models.BookingSegment) error
+	confirm(booking *models.Booking, segment *models.BookingSegment) error
+	cancel(booking *models.Booking, segment *models.BookingSegment) error
+}
+
+// flightSupplier sells flight legs. Seats are assigned per passenger later on,
+// so confirming a leg takes seats off the flight's count of seats for sale
+// and cancelling it gives them back.
+type flightSupplier struct {
+	flightRepo db.FlightRepository
+	fareRepo   db.FareRepository
+	seatRepo   db.SeatRepository
+	tenant     *models.Tenant
+}
+
+func (f *flightSupplier) price(segment *models.BookingSegment) error {
+	leg := segment.Flight
+	if leg == nil || leg.FlightID == "" {
+		return models.InvalidField("flight", "flight segments need a flight ID")
+	}
+	if leg.Quote == nil {
+		return models.InvalidField("quote", "flight segments need a fare quote with fareID and passengers")
+	}
+
+	if err := checkAirline(f.flightRepo, f.tenant, leg.FlightID); err != nil {
+		return err
+	}
+
+	// Never trust client-side prices: re-price the fare as of now
+	quote, err := quoteFare(f.fareRepo, leg.FlightID, leg.Quote.FareID, leg.Quote.Passengers, f.tenant.Markup)
+	if err != nil {
+		return err
+	}
+	leg.Quote = quote
+	return nil
+}
+
+func (f *flightSupplier) confirm(booking *models.Booking, segment *models.BookingSegment) error {
+	leg := segment.Flight
+	seated := seatedPassengers(leg.Quote)
+	if seated == 0 {
+		return nil
+	}
+	inventories, err := flightInventories(f.seatRepo, []string{leg.FlightID})
+	if err != nil {
+		return err
+	}
+	err = f.seatRepo.ReserveSeats(leg.FlightID, seated, inventories[leg.FlightID].Capacity)
+	if errors.Is(err, db.ErrSeatsSoldOut) {
+		return models.Conflict("seats_unavailable", "fewer than %d seats left on flight %s", seated, leg.FlightID)
+	}
+	return err
+}
+
+func (f *flightSupplier) cancel(booking *models.Booking, segment *models.BookingSegment) error {
+	leg := segment.Flight
+	seated := seatedPassengers(leg.Quote)
+	if seated == 0 {
+		return nil
+	}
+	return f.seatRepo.ReleaseSeats(leg.FlightID, seated)
+}
+
+// seatedPassengers counts the passengers of a quote who need a seat of their
+// own; infants travel on a lap
+func seatedPassengers(quote *models.FareQuote) int {
+	return quote.Passengers[models.PassengerAdult] + quote.Passengers[models.PassengerChild]
+}
+
+// hotelSupplier sells hotel stays, taking the rooms from the hotel's inventory
+// as a reservation linked to the booking
+type hotelSupplier struct {
+	hotelRepo       db.HotelRepository
+	roomTypeRepo    db.RoomTypeRepository
+	ratePlanRepo    db.RatePlanRepository
+	reservationRepo db.HotelReservationRepository
+	inventoryRepo   db.RoomInventoryRepository
+	tenant          *models.Tenant
+}
+
+func (h *hotelSupplier) price(segment *models.BookingSegment) error {
+	stay := segment.Hotel
+	if stay == nil {
+		return models.InvalidField("hotel", "hotel segments need a hotel stay")
+	}
+	if err := checkHotelChain(h.hotelRepo, h.tenant, stay.HotelID); err != nil {
+		return err
+	}
+	roomType, err := getRoomType(h.roomTypeRepo, stay.HotelID, stay.RoomTypeID)
+	if err != nil {
+		return err
+	}
+	nights, err := parseStay(stay.CheckInDate, stay.CheckOutDate)
+	if err != nil {
+		return err
+	}
+	if stay.NumberOfGuests <= 0 {
+		return models.InvalidField("numberOfGuests", "number of guests must be positive")
+	}
+	if stay.NumberOfGuests > roomType.MaxGuests {
+		return models.Invalid("room type %s sleeps at most %d guests", roomType.Name, roomType.MaxGuests)
+	}
+
+	var ratePlans []models.RatePlan
+	if stay.RatePlanID != "" {
+		ratePlan, err := h.ratePlanRepo.GetRatePlanByID(stay.RatePlanID)
+		if err != nil {
+			return err
+		}
+		if ratePlan == nil || ratePlan.HotelID != stay.HotelID {
+			return models.NotFound("rate plan")
+		}
+		ratePlans = []models.RatePlan{*ratePlan}
+	} else {
+		ratePlans, err = h.ratePlanRepo.GetRatePlansByHotelID(stay.HotelID)
+		if err != nil {
+			return err
+		}
+	}
+
+	request := models.StayQuoteRequest{
+		RoomTypeID:     stay.RoomTypeID,
+		CheckInDate:    stay.CheckInDate,
+		CheckOutDate:   stay.CheckOutDate,
+		NumberOfGuests: stay.NumberOfGuests,
+	}
+	quotes, reason, err := quoteRatePlans(h.ratePlanRepo, ratePlans, request, nights, h.tenant.Markup)
+	if err != nil {
+		return err
+	}
+	if len(quotes) == 0 {
+		if reason == "" {
+			reason = "hotel has no rate plans"
+		}
+		return models.Conflict("no_rate_available", "%s", reason)
+	}
+
+	stay.RatePlanID = quotes[0].RatePlanID
+	stay.Quote = &quotes[0]
+	stay.ReservationID = ""
+	return nil
+}
+
+func (h *hotelSupplier) confirm(booking *models.Booking, segment *models.BookingSegment) error {
+	stay := segment.Hotel
+	roomType, err := getRoomType(h.roomTypeRepo, stay.HotelID, stay.RoomTypeID)
+	if err != nil {
+		return err
+	}
+	checkIn, err := time.Parse(models.StayDateLayout, stay.CheckInDate)
+	if err != nil {
+		return err
+	}
+	checkOut, err := time.Parse(models.StayDateLayout, stay.CheckOutDate)
+	if err != nil {
+		return err
+	}
+
+	reservation := &models.HotelReservation{
+		HotelID:        stay.HotelID,
+		RoomTypeID:     stay.RoomTypeID,
+		RatePlanID:     stay.RatePlanID,
+		BookingID:      booking.BookingID,
+		UserID:         booking.UserID,
+		CheckInDate:    checkIn,
+		CheckOutDate:   checkOut,
+		NumberOfGuests: stay.NumberOfGuests,
+	}
+	err = reserveRoom(h.reservationRepo, h.inventoryRepo, *roomType, reservation)
+	if errors.Is(err, db.ErrRoomsSoldOut) {
+		return &models.RoomsSoldOutError{RoomTypeID: roomType.RoomTypeID, CheckInDate: checkIn, CheckOutDate: checkOut}
+	}
+	if err != nil {
+		return err
+	}
+	stay.ReservationID = reservation.ReservationID
+	return nil
+}
+
+func (h *hotelSupplier) cancel(booking *models.Booking, segment *models.BookingSegment) error {
+	stay := segment.Hotel
+	if stay.ReservationID == "" {
+		return nil
+	}
+	reservation, err := h.reservationRepo.GetReservationByID(stay.ReservationID)
+	if err != nil {
+		return err
+	}
+	if reservation == nil || reservation.Status == models.ReservationCancelled {
+		return nil
+	}
+	_, err = cancelReservation(h.reservationRepo, h.inventoryRepo, reservation)
+	return err
+}
@@ -1,89 +1,435 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 	"travel-backend/internal/core/domain/models"
 	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
 )
 
+// maxLocatorAttempts bounds how many random locators CreateBooking tries
+// before giving up. With 32^6 possible locators a clash is rare, so running
+// out of attempts means something else is wrong.
+const maxLocatorAttempts = 5
+
+// maxSegmentSaveAttempts bounds how often cancelSegments reloads a booking that
+// keeps being changed under it before it gives up and logs the failure
+const maxSegmentSaveAttempts = 3
+
 type BookingServiceImpl struct {
-	bookingRepo db.BookingRepository
+	bookingRepo   db.BookingRepository
+	passengerRepo db.PassengerRepository
+	suppliers     map[string]segmentSupplier
 }
 
-func NewBookingService(bookingRepo db.BookingRepository) *BookingServiceImpl {
+// NewBookingService creates a new instance of BookingServiceImpl that books
+// trips for tenant, selling only the airlines and hotel chains it allows at
+// its markup
+func NewBookingService(bookingRepo db.BookingRepository, passengerRepo db.PassengerRepository, flightRepo db.FlightRepository, fareRepo db.FareRepository, seatRepo db.SeatRepository, hotelRepo db.HotelRepository, roomTypeRepo db.RoomTypeRepository, ratePlanRepo db.RatePlanRepository, reservationRepo db.HotelReservationRepository, inventoryRepo db.RoomInventoryRepository, tenant *models.Tenant) *BookingServiceImpl {
 	return &BookingServiceImpl{
-		bookingRepo: bookingRepo,
+		bookingRepo:   bookingRepo,
+		passengerRepo: passengerRepo,
+		suppliers: map[string]segmentSupplier{
+			models.SegmentFlight: &flightSupplier{flightRepo: flightRepo, fareRepo: fareRepo, seatRepo: seatRepo, tenant: tenant},
+			models.SegmentHotel: &hotelSupplier{
+				hotelRepo:       hotelRepo,
+				roomTypeRepo:    roomTypeRepo,
+				ratePlanRepo:    ratePlanRepo,
+				reservationRepo: reservationRepo,
+				inventoryRepo:   inventoryRepo,
+				tenant:          tenant,
+			},
+		},
+	}
+}
+
+// ListBookings returns one page of the bookings matching query. Customers
+// only ever see their own bookings; staff may list anyone's.
+func (s *BookingServiceImpl) ListBookings(ctx context.Context, query models.BookingQuery) (*models.BookingPage, error) {
+	principal, err := models.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !principal.CanActForAnyUser() {
+		if query.UserID != "" && query.UserID != principal.UserID {
+			return nil, models.Forbidden(models.CodeBookingAccessDenied, "you can only list your own bookings")
+		}
+		query.UserID = principal.UserID
+	}
+	query.Status = models.BookingStatus(strings.ToUpper(string(query.Status)))
+	if query.Status != "" && !query.Status.IsValid() {
+		return nil, models.InvalidField("status", "unknown booking status %q", query.Status)
+	}
+	if err := checkDateRange(query.From, query.To); err != nil {
+		return nil, err
+	}
+	err = checkPage(&query.Page, func(field string) bool {
+		_, ok := models.BookingSortKeys[field]
+		return ok
+	})
+	if err != nil {
+		return nil, err
 	}
+	return s.bookingRepo.ListBookings(query)
 }
 
-func (s *BookingServiceImpl) GetAllBookings() ([]models.Booking, error) {
-	bookings, err := s.bookingRepo.GetAllBookings()
+// GetBookingByID retrieves a booking, provided the caller in ctx owns it or
+// is staff
+func (s *BookingServiceImpl) GetBookingByID(ctx context.Context, id string) (*models.Booking, error) {
+	principal, err := models.RequirePrincipal(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return bookings, nil
+	return s.getOwnedBooking(principal, id)
 }
 
-func (s *BookingServiceImpl) GetBookingByID(id string) (*models.Booking, error) {
+// getOwnedBooking retrieves a booking the principal may see and change: one
+// of their own, or anyone's for staff
+func (s *BookingServiceImpl) getOwnedBooking(principal *models.Principal, id string) (*models.Booking, error) {
 	booking, err := s.bookingRepo.GetBookingByID(id)
 	if err != nil {
 		return nil, err
 	}
 	if booking == nil {
-		return nil, errors.New("booking not found")
+		return nil, models.NotFound("booking")
+	}
+	if booking.UserID != principal.UserID && !principal.CanActForAnyUser() {
+		return nil, models.Forbidden(models.CodeBookingAccessDenied, "booking belongs to another user")
 	}
 	return booking, nil
 }
 
-func (s *BookingServiceImpl) CreateBooking(booking *models.Booking) error {
+// GetBookingByLocator retrieves a booking by its record locator, provided one of
+// its passengers has the given surname. Unknown locators and surnames that do
+// not match fail alike, so locators cannot be probed.
+func (s *BookingServiceImpl) GetBookingByLocator(locator, lastName string) (*models.Booking, error) {
+	locator = strings.ToUpper(strings.TrimSpace(locator))
+	lastName = strings.TrimSpace(lastName)
+	if !utils.IsLocator(locator) {
+		return nil, models.InvalidField("locator", "invalid booking locator")
+	}
+	if lastName == "" {
+		return nil, models.InvalidField("lastName", "last name is required")
+	}
+
+	booking, err := s.bookingRepo.GetBookingByLocator(locator)
+	if err != nil {
+		return nil, err
+	}
 	if booking == nil {
-		return errors.New("invalid booking details")
+		return nil, models.NotFound("booking")
 	}
-	err := s.bookingRepo.CreateBooking(booking)
+
+	passengers, err := s.passengerRepo.GetAllPassengersByBookingID(booking.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	for _, passenger := range passengers {
+		if strings.EqualFold(surname(passenger.Name), lastName) {
+			return booking, nil
+		}
+	}
+	return nil, models.NotFound("booking")
+}
+
+// CreateBooking stores a new booking under a freshly generated booking ID and
+// record locator; bookings that name an ID of their own are refused.
+// Customers always book for themselves, whatever user the request names;
+// staff book for the user named, or for themselves. Every booking and each of
+// its segments starts out PENDING, whatever status the caller supplied, and
+// every segment is priced afresh by its supplier. Nothing is booked with the
+// suppliers until the booking is confirmed.
+func (s *BookingServiceImpl) CreateBooking(ctx context.Context, booking *models.Booking) error {
+	principal, err := models.RequirePrincipal(ctx)
 	if err != nil {
 		return err
 	}
-	return nil
+	if booking == nil {
+		return models.Invalid("invalid booking details")
+	}
+	if booking.BookingID != "" {
+		return models.InvalidField("bookingID", "booking IDs are assigned by the server")
+	}
+	if booking.UserID == "" || !principal.CanActForAnyUser() {
+		booking.UserID = principal.UserID
+	}
+	if err := models.Validate(booking); err != nil {
+		return err
+	}
+
+	for i := range booking.Segments {
+		segment := &booking.Segments[i]
+		supplier, err := s.supplier(segment)
+		if err != nil {
+			return err
+		}
+		if err := supplier.price(segment); err != nil {
+			return fmt.Errorf("segment %d: %w", i+1, err)
+		}
+		segment.SegmentID = utils.NewID()
+		segment.Status = models.SegmentPending
+		segment.FailureReason = ""
+	}
+
+	now := time.Now().UTC()
+	booking.BookingStatus = models.BookingPending
+	booking.StatusHistory = []models.BookingStatusChange{{
+		To:        models.BookingPending,
+		ChangedAt: now,
+		Actor:     booking.UserID,
+	}}
+	booking.CreatedAt = now
+	booking.UpdatedAt = now
+	booking.BookingID = utils.NewID()
+
+	// Locators are random, so retry on the rare clash with an existing booking
+	for attempt := 1; ; attempt++ {
+		booking.Locator = utils.NewLocator()
+		err := s.bookingRepo.CreateBooking(booking)
+		if !errors.Is(err, db.ErrLocatorTaken) {
+			return err
+		}
+		if attempt == maxLocatorAttempts {
+			return models.Unavailable("locator_unavailable", err)
+		}
+	}
 }
 
-func (s *BookingServiceImpl) UpdateBookingStatus(id string, status string) error {
+// UpdateBookingStatus moves a booking to a new lifecycle status on behalf of
+// the caller in ctx, who must own it or be staff and is recorded as the
+// actor. Customers may only confirm or cancel; ticketing, refunding and
+// completing are left to staff. Transitions the lifecycle does not allow fail
+// with *models.InvalidTransitionError. Confirming a booking books every
+// segment with its supplier in order; if one fails, the segments booked before
+// it are cancelled again, the booking is cancelled and
+// *models.SegmentFailedError is returned. Cancelling a booking cancels its
+// confirmed segments.
+func (s *BookingServiceImpl) UpdateBookingStatus(ctx context.Context, id string, status models.BookingStatus) (*models.Booking, error) {
+	principal, err := models.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if id == "" || status == "" {
-		return errors.New("invalid booking ID or status")
+		return nil, models.Invalid("invalid booking ID or status")
+	}
+	if !status.IsValid() {
+		return nil, models.InvalidField("status", "unknown booking status %q", status)
+	}
+	if !status.CustomerMaySet() && !principal.CanActForAnyUser() {
+		return nil, models.Forbidden(models.CodeStatusChangeDenied, "only staff may move a booking to %s", status)
+	}
+	actor := principal.UserID
+
+	booking, err := s.getOwnedBooking(principal, id)
+	if err != nil {
+		return nil, err
+	}
+	if !booking.BookingStatus.CanTransitionTo(status) {
+		return nil, &models.InvalidTransitionError{From: booking.BookingStatus, To: status}
+	}
+
+	var booked map[string]bool
+	if status == models.BookingConfirmed {
+		if booked, err = s.confirmSegments(booking, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.changeStatus(booking, status, actor)
+	if errors.Is(err, db.ErrBookingStatusChanged) && status == models.BookingConfirmed {
+		// Someone else moved the booking on while its segments were being
+		// booked, so give back the segments this request booked
+		s.cancelSegments(booking, booked)
+		return nil, err
 	}
-	err := s.bookingRepo.UpdateBookingStatus(id, status)
 	if err != nil {
+		return nil, err
+	}
+
+	if status == models.BookingCancelled {
+		s.cancelSegments(booking, nil)
+	}
+	return booking, nil
+}
+
+// confirmSegments books each pending segment with its supplier, saving progress
+// after every step, and returns the IDs of the segments it booked. When a
+// segment fails, the ones already booked are cancelled in reverse order and
+// the booking is cancelled. When the booking was changed by someone else in
+// the meantime, only the segment that could not be recorded is given back and
+// db.ErrVersionConflict is returned; the stored booking stands as they left it.
+func (s *BookingServiceImpl) confirmSegments(booking *models.Booking, actor string) (map[string]bool, error) {
+	booked := make(map[string]bool)
+	for i := range booking.Segments {
+		segment := &booking.Segments[i]
+		if segment.Status != models.SegmentPending {
+			continue
+		}
+		supplier, err := s.supplier(segment)
+		if err == nil {
+			err = supplier.confirm(booking, segment)
+		}
+		if err != nil {
+			segment.Status = models.SegmentFailed
+			segment.FailureReason = err.Error()
+			s.compensate(booking, actor)
+			return nil, &models.SegmentFailedError{BookingID: booking.BookingID, SegmentID: segment.SegmentID, Reason: err.Error()}
+		}
+
+		segment.Status = models.SegmentConfirmed
+		err = s.saveSegments(booking)
+		if errors.Is(err, db.ErrVersionConflict) {
+			s.cancelSegment(booking, segment)
+			return nil, err
+		}
+		if err != nil {
+			// The supplier holds the segment but the booking does not say so;
+			// undo it rather than leave it orphaned
+			s.compensate(booking, actor)
+			return nil, err
+		}
+		booked[segment.SegmentID] = true
+	}
+	return booked, nil
+}
+
+// compensate undoes a confirmation that could not be completed: the booked
+// segments are cancelled with their suppliers, newest first, the remaining ones
+// are never booked, and the booking itself is cancelled
+func (s *BookingServiceImpl) compensate(booking *models.Booking, actor string) {
+	s.cancelSegments(booking, nil)
+	if err := s.changeStatus(booking, models.BookingCancelled, actor); err != nil {
+		log.Printf("Error cancelling booking %s: %v", booking.BookingID, err)
+	}
+}
+
+// cancelSegments cancels the segments of a booking named in only, or all of
+// them when only is nil, and records that. If someone else stored the segments
+// in the meantime, the latest booking is loaded and the work is redone on it,
+// without cancelling a segment with its supplier twice. Failures are logged
+// rather than returned, since the booking itself is already being cancelled.
+func (s *BookingServiceImpl) cancelSegments(booking *models.Booking, only map[string]bool) {
+	cancelled := make(map[string]bool)
+	for attempt := 1; ; attempt++ {
+		for i := len(booking.Segments) - 1; i >= 0; i-- {
+			segment := &booking.Segments[i]
+			switch {
+			case cancelled[segment.SegmentID]:
+				segment.Status = models.SegmentCancelled
+			case only == nil || only[segment.SegmentID]:
+				wasConfirmed := segment.Status == models.SegmentConfirmed
+				s.cancelSegment(booking, segment)
+				if wasConfirmed && segment.Status == models.SegmentCancelled {
+					cancelled[segment.SegmentID] = true
+				}
+			}
+		}
+
+		err := s.saveSegments(booking)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, db.ErrVersionConflict) || attempt == maxSegmentSaveAttempts {
+			log.Printf("Error saving segments of booking %s: %v", booking.BookingID, err)
+			return
+		}
+		latest, err := s.bookingRepo.GetBookingByID(booking.BookingID)
+		if err != nil || latest == nil {
+			log.Printf("Error reloading booking %s: %v", booking.BookingID, err)
+			return
+		}
+		*booking = *latest
+	}
+}
+
+func (s *BookingServiceImpl) cancelSegment(booking *models.Booking, segment *models.BookingSegment) {
+	switch segment.Status {
+	case models.SegmentConfirmed:
+		supplier, err := s.supplier(segment)
+		if err == nil {
+			err = supplier.cancel(booking, segment)
+		}
+		if err != nil {
+			log.Printf("Error cancelling segment %s of booking %s: %v", segment.SegmentID, booking.BookingID, err)
+			return
+		}
+		segment.Status = models.SegmentCancelled
+	case models.SegmentPending:
+		segment.Status = models.SegmentCancelled
+	}
+}
+
+// changeStatus records a status change on a booking, both stored and in memory
+func (s *BookingServiceImpl) changeStatus(booking *models.Booking, status models.BookingStatus, actor string) error {
+	change := models.BookingStatusChange{
+		From:      booking.BookingStatus,
+		To:        status,
+		ChangedAt: time.Now().UTC(),
+		Actor:     actor,
+	}
+	if err := s.bookingRepo.UpdateBookingStatus(booking.BookingID, change); err != nil {
 		return err
 	}
+
+	booking.BookingStatus = status
+	booking.StatusHistory = append(booking.StatusHistory, change)
+	booking.UpdatedAt = change.ChangedAt
+	booking.Version++
 	return nil
 }
 
-func (s *BookingServiceImpl) GetBookingsByUserID(userID string) ([]models.Booking, error) {
-	if userID == "" {
-		return nil, errors.New("invalid user ID")
+// saveSegments stores the progress of a booking's segments, provided nobody
+// else changed the booking since it was read
+func (s *BookingServiceImpl) saveSegments(booking *models.Booking) error {
+	if err := s.bookingRepo.UpdateBookingSegments(booking.BookingID, booking.Segments, booking.Version); err != nil {
+		return err
 	}
-	bookings, err := s.bookingRepo.GetBookingsByUserID(userID)
-	if err != nil {
-		return nil, err
+	booking.Version++
+	return nil
+}
+
+// supplier returns the supplier that sells a segment's type, checking that the
+// segment carries the details for that type
+func (s *BookingServiceImpl) supplier(segment *models.BookingSegment) (segmentSupplier, error) {
+	supplier, ok := s.suppliers[segment.Type]
+	if !ok {
+		return nil, models.InvalidField("type", "unknown segment type %q", segment.Type)
 	}
-	return bookings, nil
+	if segment.Type == models.SegmentFlight && segment.Hotel != nil || segment.Type == models.SegmentHotel && segment.Flight != nil {
+		return nil, models.Invalid("a %s segment must carry only %s details", segment.Type, strings.ToLower(segment.Type))
+	}
+	return supplier, nil
 }
 
-func (s *BookingServiceImpl) DeleteBooking(id string) error {
+// DeleteBooking deletes a booking, provided it is still at version and the
+// caller in ctx owns it or is staff
+func (s *BookingServiceImpl) DeleteBooking(ctx context.Context, id string, version int64) error {
+	principal, err := models.RequirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
 	if id == "" {
-		return errors.New("invalid booking ID")
+		return models.InvalidField("bookingID", "invalid booking ID")
 	}
 
 	// Ensure the booking exists before attempting to delete it
-	booking, err := s.bookingRepo.GetBookingByID(id)
+	booking, err := s.getOwnedBooking(principal, id)
 	if err != nil {
 		return err
 	}
-	if booking == nil {
-		return errors.New("booking not found")
+	for _, segment := range booking.Segments {
+		if segment.Status == models.SegmentConfirmed {
+			return models.Conflict("booking_has_confirmed_segments", "booking has confirmed segments, cancel it first")
+		}
 	}
 
 	// Call the repository to delete the booking
-	err = s.bookingRepo.DeleteBooking(id)
+	err = s.bookingRepo.DeleteBooking(id, version)
 	if err != nil {
 		return err
 	}
@@ -91,21 +437,66 @@ func (s *BookingServiceImpl) DeleteBooking(id string) error {
 	return nil
 }
 
-func (s *BookingServiceImpl) UpdateBooking(id string, booking *models.Booking) (*models.Booking, error) {
+// PatchBooking applies a JSON merge patch to a booking, provided it is still at
+// version. Only the contact details can be patched; the lifecycle, segments
+// and locator change through their own operations, and the owner never
+// changes.
+func (s *BookingServiceImpl) PatchBooking(ctx context.Context, id string, patch []byte, version int64) (*models.Booking, error) {
+	existingBooking, err := s.GetBookingByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existingBooking.Version != version {
+		return nil, db.ErrVersionConflict
+	}
+
+	var booking models.Booking
+	fields, err := applyMergePatch(existingBooking, patch, &booking,
+		"bookingID", "locator", "userID", "bookingStatus", "statusHistory", "segments", "createdAt", "updatedAt", "version")
+	if err != nil {
+		return nil, err
+	}
+	if err := models.Validate(&booking); err != nil {
+		return nil, err
+	}
+
+	booking.BookingID = id
+	booking.Version = version
+	booking.UpdatedAt = time.Now().UTC()
+	return s.bookingRepo.PatchBooking(id, &booking, append(fields, "updatedAt"))
+}
+
+// UpdateBooking replaces a booking's contact details, provided it is still at
+// booking.Version and the caller in ctx owns it or is staff. Everything else is
+// kept as stored.
+func (s *BookingServiceImpl) UpdateBooking(ctx context.Context, id string, booking *models.Booking) (*models.Booking, error) {
+	principal, err := models.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if id == "" {
-		return nil, errors.New("invalid booking ID")
+		return nil, models.InvalidField("bookingID", "invalid booking ID")
 	}
 	if booking == nil {
-		return nil, errors.New("invalid booking details")
+		return nil, models.Invalid("invalid booking details")
 	}
 
 	// Ensure the booking exists before attempting to update it
-	existingBooking, err := s.bookingRepo.GetBookingByID(id)
+	existingBooking, err := s.getOwnedBooking(principal, id)
 	if err != nil {
 		return nil, err
 	}
-	if existingBooking == nil {
-		return nil, errors.New("booking not found")
+
+	// Status only changes through UpdateBookingStatus, so keep the lifecycle as stored
+	booking.BookingStatus = existingBooking.BookingStatus
+	booking.StatusHistory = existingBooking.StatusHistory
+	booking.Locator = existingBooking.Locator
+	booking.UserID = existingBooking.UserID
+	booking.Segments = existingBooking.Segments
+	booking.CreatedAt = existingBooking.CreatedAt
+	booking.UpdatedAt = time.Now().UTC()
+	if err := models.Validate(booking); err != nil {
+		return nil, err
 	}
 
 	// Call the repository to update the booking
@@ -116,3 +507,12 @@ func (s *BookingServiceImpl) UpdateBooking(id string, booking *models.Booking) (
 
 	return updatedBooking, nil
 }
+
+// surname returns the last word of a passenger's full name
+func surname(name string) string {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[len(words)-1]
+}
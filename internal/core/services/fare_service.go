@@ -0,0 +1,228 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
+)
+
+// maxSeatedPassengers caps adults and children on a single quote; lap infants
+// do not take a seat
+const maxSeatedPassengers = 9
+
+// passengerTypeOrder fixes the order of quote lines
+var passengerTypeOrder = []string{models.PassengerAdult, models.PassengerChild, models.PassengerInfant}
+
+type FareServiceImpl struct {
+	fareRepo   db.FareRepository
+	flightRepo db.FlightRepository
+	tenant     *models.Tenant
+}
+
+// NewFareService creates a new instance of FareServiceImpl that quotes fares
+// of the airlines tenant sells, at its markup
+func NewFareService(fareRepo db.FareRepository, flightRepo db.FlightRepository, tenant *models.Tenant) *FareServiceImpl {
+	return &FareServiceImpl{
+		fareRepo:   fareRepo,
+		flightRepo: flightRepo,
+		tenant:     tenant,
+	}
+}
+
+// GetFlightFares retrieves the fares sold on a flight
+func (s *FareServiceImpl) GetFlightFares(flightID string) ([]models.Fare, error) {
+	if err := s.checkFlight(flightID); err != nil {
+		return nil, err
+	}
+	return s.fareRepo.GetFaresByFlightID(flightID)
+}
+
+// CreateFare adds a fare class to a flight
+func (s *FareServiceImpl) CreateFare(flightID string, fare *models.Fare) error {
+	if fare == nil {
+		return models.Invalid("fare details cannot be nil")
+	}
+	if err := s.checkFlight(flightID); err != nil {
+		return err
+	}
+
+	fare.FlightID = flightID
+	fare.Cabin = strings.ToUpper(fare.Cabin)
+	fare.BookingCode = strings.ToUpper(fare.BookingCode)
+	fare.Currency = strings.ToUpper(fare.Currency)
+	if !models.IsCabinClass(fare.Cabin) {
+		return models.Invalid("unknown cabin class %q", fare.Cabin)
+	}
+	if len(fare.BookingCode) != 1 || fare.BookingCode[0] < 'A' || fare.BookingCode[0] > 'Z' {
+		return models.InvalidField("bookingCode", "booking code must be a single letter")
+	}
+	if len(fare.Currency) != 3 {
+		return models.InvalidField("currency", "currency must be a three-letter ISO 4217 code")
+	}
+	if _, ok := fare.Prices[models.PassengerAdult]; !ok {
+		return models.InvalidField("prices", "fare must have an adult (ADT) price")
+	}
+	for passengerType, price := range fare.Prices {
+		if !models.IsPassengerType(passengerType) {
+			return models.Invalid("unknown passenger type %q", passengerType)
+		}
+		if price.BaseFare < 0 || chargesTotal(price.Taxes) < 0 || chargesTotal(price.Fees) < 0 {
+			return models.Invalid("%s price cannot be negative", passengerType)
+		}
+	}
+	if fare.Rules.RefundFee < 0 || fare.Rules.ChangeFee < 0 {
+		return models.Invalid("fare rule fees cannot be negative")
+	}
+
+	if fare.FareID == "" {
+		fare.FareID = utils.NewID()
+	}
+	return s.fareRepo.CreateFare(fare)
+}
+
+// QuoteFare prices the requested fare for the passenger mix in request. Fares
+// of airlines the tenant does not sell are refused.
+func (s *FareServiceImpl) QuoteFare(flightID string, request models.FareQuote) (*models.FareQuote, error) {
+	if err := checkAirline(s.flightRepo, s.tenant, flightID); err != nil {
+		return nil, err
+	}
+	return quoteFare(s.fareRepo, flightID, request.FareID, request.Passengers, s.tenant.Markup)
+}
+
+func (s *FareServiceImpl) checkFlight(flightID string) error {
+	if flightID == "" {
+		return models.InvalidField("flightID", "flight ID cannot be empty")
+	}
+	flight, err := s.flightRepo.GetFlightByID(flightID)
+	if err != nil {
+		return err
+	}
+	if flight == nil {
+		return models.NotFound("flight")
+	}
+	return nil
+}
+
+// checkAirline makes sure the flight exists and is flown by an airline the
+// tenant sells
+func checkAirline(flightRepo db.FlightRepository, tenant *models.Tenant, flightID string) error {
+	if flightID == "" {
+		return models.InvalidField("flightID", "flight ID cannot be empty")
+	}
+	flight, err := flightRepo.GetFlightByID(flightID)
+	if err != nil {
+		return err
+	}
+	if flight == nil {
+		return models.NotFound("flight")
+	}
+	if !tenant.AllowsAirline(flight.Airline) {
+		return models.Forbidden(models.CodeSupplierNotAllowed, "this agency does not sell flights of %s", flight.Airline)
+	}
+	return nil
+}
+
+// quoteFare loads a fare of the given flight and prices it for passengers
+func quoteFare(fareRepo db.FareRepository, flightID, fareID string, passengers map[string]int, markup models.Markup) (*models.FareQuote, error) {
+	if fareID == "" {
+		return nil, models.InvalidField("fareID", "fare ID is required")
+	}
+	fare, err := fareRepo.GetFareByID(fareID)
+	if err != nil {
+		return nil, err
+	}
+	if fare == nil || fare.FlightID != flightID {
+		return nil, models.NotFound("fare")
+	}
+	return priceFare(fare, passengers, markup, time.Now().UTC())
+}
+
+// priceFare builds the quote for a passenger mix, one line per passenger type,
+// and adds markup on the base fares
+func priceFare(fare *models.Fare, passengers map[string]int, markup models.Markup, now time.Time) (*models.FareQuote, error) {
+	if err := checkPassengerMix(passengers); err != nil {
+		return nil, err
+	}
+
+	quote := &models.FareQuote{
+		FareID:      fare.FareID,
+		FlightID:    fare.FlightID,
+		Passengers:  make(map[string]int),
+		Cabin:       fare.Cabin,
+		BookingCode: fare.BookingCode,
+		Currency:    fare.Currency,
+		Rules:       fare.Rules,
+		QuotedAt:    now,
+	}
+	for _, passengerType := range passengerTypeOrder {
+		count := passengers[passengerType]
+		if count == 0 {
+			continue
+		}
+		price, ok := fare.Prices[passengerType]
+		if !ok {
+			return nil, models.Invalid("fare %s is not sold to %s passengers", fare.FareID, passengerType)
+		}
+
+		taxes, fees := chargesTotal(price.Taxes), chargesTotal(price.Fees)
+		line := models.QuoteLine{
+			PassengerType: passengerType,
+			Count:         count,
+			BaseFare:      price.BaseFare,
+			Taxes:         append([]models.Charge(nil), price.Taxes...),
+			Fees:          append([]models.Charge(nil), price.Fees...),
+			UnitTotal:     price.BaseFare + taxes + fees,
+		}
+		line.Subtotal = line.UnitTotal * int64(count)
+
+		quote.Passengers[passengerType] = count
+		quote.Lines = append(quote.Lines, line)
+		quote.BaseTotal += price.BaseFare * int64(count)
+		quote.TaxTotal += taxes * int64(count)
+		quote.FeeTotal += fees * int64(count)
+		quote.Total += line.Subtotal
+	}
+	quote.Markup = markup.On(quote.BaseTotal)
+	quote.Total += quote.Markup
+	return quote, nil
+}
+
+// checkPassengerMix enforces the usual ticketing limits: known types only, at
+// most nine seated passengers, and an adult with every child or lap infant
+func checkPassengerMix(passengers map[string]int) error {
+	for passengerType, count := range passengers {
+		if !models.IsPassengerType(passengerType) {
+			return models.Invalid("unknown passenger type %q", passengerType)
+		}
+		if count < 0 {
+			return models.Invalid("%s passenger count cannot be negative", passengerType)
+		}
+	}
+
+	adults := passengers[models.PassengerAdult]
+	children := passengers[models.PassengerChild]
+	infants := passengers[models.PassengerInfant]
+	if adults+children+infants == 0 {
+		return models.InvalidField("passengers", "at least one passenger is required")
+	}
+	if adults+children > maxSeatedPassengers {
+		return models.Invalid("at most %d seated passengers can be quoted together", maxSeatedPassengers)
+	}
+	if adults == 0 {
+		return models.Invalid("children and infants must travel with an adult")
+	}
+	if infants > adults {
+		return models.Invalid("each infant must travel with their own adult")
+	}
+	return nil
+}
+
+func chargesTotal(charges []models.Charge) int64 {
+	var total int64
+	for _, charge := range charges {
+		total += charge.Amount
+	}
+	return total
+}
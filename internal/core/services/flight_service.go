@@ -1,7 +1,10 @@
 package services
 
 import (
-	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
 	"travel-backend/internal/core/domain/models"
 	"travel-backend/internal/ports/db"
 )
@@ -12,71 +15,198 @@ type FlightServiceImpl struct {
 	seatRepo    db.SeatRepository
 }
 
-func NewFlightService(flightRepo db.FlightRepository, bookingRepo db.BookingRepository, /* seatRepo db.SeatRepository */) *FlightServiceImpl {
+func NewFlightService(flightRepo db.FlightRepository, bookingRepo db.BookingRepository, seatRepo db.SeatRepository) *FlightServiceImpl {
 	return &FlightServiceImpl{
 		flightRepo:  flightRepo,
 		bookingRepo: bookingRepo,
-		// seatRepo:    seatRepo,
+		seatRepo:    seatRepo,
 	}
 }
 
-func (s *FlightServiceImpl) GetAllFlights() ([]models.Flight, error) {
-	return s.flightRepo.GetAllFlights()
+// ListFlights returns one page of the flights matching query
+func (s *FlightServiceImpl) ListFlights(query models.FlightQuery) (*models.FlightPage, error) {
+	query.Airline = strings.TrimSpace(query.Airline)
+	query.Origin = strings.ToUpper(strings.TrimSpace(query.Origin))
+	query.Destination = strings.ToUpper(strings.TrimSpace(query.Destination))
+	if err := checkDateRange(query.From, query.To); err != nil {
+		return nil, err
+	}
+	err := checkPage(&query.Page, func(field string) bool {
+		_, ok := models.FlightSortKeys[field]
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.flightRepo.ListFlights(query)
 }
 
 func (s *FlightServiceImpl) GetFlightByID(id string) (*models.Flight, error) {
 	if id == "" {
-		return nil, errors.New("flight ID cannot be empty")
+		return nil, models.InvalidField("flightID", "flight ID cannot be empty")
 	}
 	return s.flightRepo.GetFlightByID(id)
 }
 
+// SearchFlights finds the flights on a route and day that still have enough
+// available seats for the whole party, ordered by departure time or duration
+func (s *FlightServiceImpl) SearchFlights(search models.FlightSearch) ([]models.FlightSearchResult, error) {
+	origin := strings.ToUpper(strings.TrimSpace(search.Origin))
+	destination := strings.ToUpper(strings.TrimSpace(search.Destination))
+	if origin == "" || destination == "" {
+		return nil, models.Invalid("origin and destination are required")
+	}
+	if _, err := time.Parse(models.FlightDateLayout, search.Date); err != nil {
+		return nil, models.InvalidField("date", "departure date must be formatted as YYYY-MM-DD")
+	}
+
+	passengers := search.Passengers
+	if passengers == 0 {
+		passengers = 1
+	}
+	if passengers < 0 {
+		return nil, models.InvalidField("passengers", "passenger count must be positive")
+	}
+
+	sortBy := search.SortBy
+	if sortBy == "" {
+		sortBy = models.FlightSortDeparture
+	}
+	if sortBy != models.FlightSortDeparture && sortBy != models.FlightSortDuration {
+		return nil, models.InvalidField("sort", "sort must be departure or duration")
+	}
+
+	flights, err := s.flightRepo.SearchFlights(origin, destination, search.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	flightIDs := make([]string, len(flights))
+	for i, flight := range flights {
+		flightIDs[i] = flight.FlightID
+	}
+	inventories, err := flightInventories(s.seatRepo, flightIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.FlightSearchResult, 0, len(flights))
+	for _, flight := range flights {
+		forSale := seatsForSale(inventories[flight.FlightID])
+		if forSale < passengers {
+			continue
+		}
+		results = append(results, models.FlightSearchResult{Flight: flight, AvailableSeats: forSale})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if sortBy == models.FlightSortDuration {
+			if di, dj := results[i].Duration(), results[j].Duration(); di != dj {
+				return di < dj
+			}
+		}
+		return results[i].DepartureTime.Before(results[j].DepartureTime)
+	})
+	return results, nil
+}
+
 func (s *FlightServiceImpl) CreateFlight(flight *models.Flight) error {
 	if flight == nil {
-		return errors.New("flight details cannot be nil")
+		return models.Invalid("flight details cannot be nil")
+	}
+	if flight.FlightID == "" {
+		return models.InvalidField("flightID", "flight ID is required")
+	}
+	if err := checkFlight(flight); err != nil {
+		return err
 	}
-	if flight.FlightID == "" || flight.Airline == "" {
-		return errors.New("flight ID and airline are required")
+
+	// Build the seat map up front so an unknown aircraft type is rejected
+	// before anything is persisted
+	var seats []models.Seat
+	if flight.AircraftType != "" {
+		var err error
+		seats, err = GenerateSeatMap(flight)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.flightRepo.CreateFlight(flight); err != nil {
+		return err
+	}
+	if len(seats) == 0 {
+		return nil
 	}
-	return s.flightRepo.CreateFlight(flight)
+
+	// Roll the flight back if its seat inventory cannot be stored
+	if err := s.seatRepo.CreateSeats(seats); err != nil {
+		if delErr := s.flightRepo.DeleteFlight(flight.FlightID, flight.Version); delErr != nil {
+			log.Printf("Error rolling back flight %s: %v", flight.FlightID, delErr)
+		}
+		return err
+	}
+	return nil
 }
 
 // GetFlightBookings retrieves all bookings for a specific flight
 func (s *FlightServiceImpl) GetFlightBookings(flightID string) ([]models.Booking, error) {
 	if flightID == "" {
-		return nil, errors.New("flight ID cannot be empty")
+		return nil, models.InvalidField("flightID", "flight ID cannot be empty")
 	}
 	return s.flightRepo.GetFlightBookings(flightID)
 }
 
 // GetAvailableSeats retrieves all available seats for a specific flight
 func (s *FlightServiceImpl) GetAvailableSeats(flightID string) ([]models.Seat, error) {
-	// if flightID == "" {
-	// 	return nil, errors.New("flight ID cannot be empty")
-	// }
-	// seats, err := s.seatRepo.GetAvailableSeatsByFlightID(flightID)
-	// if err != nil {
-	// 	return nil, err
-	// }
-
-	// Filter available seats
-	var availableSeats []models.Seat
-	// for _, seat := range seats {
-	// 	if seat.IsAvailable {
-	// 		availableSeats = append(availableSeats, seat)
-	// 	}
-	// }
-
-	return availableSeats, nil
+	available := true
+	return s.GetFlightSeats(flightID, models.SeatFilter{Available: &available})
+}
+
+// GetFlightSeats retrieves the seats of a flight, optionally narrowed by class and availability
+func (s *FlightServiceImpl) GetFlightSeats(flightID string, filter models.SeatFilter) ([]models.Seat, error) {
+	if flightID == "" {
+		return nil, models.InvalidField("flightID", "flight ID cannot be empty")
+	}
+
+	var (
+		seats []models.Seat
+		err   error
+	)
+	if filter.Available != nil && *filter.Available {
+		seats, err = s.seatRepo.GetAvailableSeatsByFlightID(flightID)
+	} else {
+		seats, err = s.seatRepo.GetSeatsByFlightID(flightID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.Seat, 0, len(seats))
+	for _, seat := range seats {
+		if filter.Class != "" && !strings.EqualFold(seat.Class, filter.Class) {
+			continue
+		}
+		if filter.Available != nil && seat.IsAvailable != *filter.Available {
+			continue
+		}
+		filtered = append(filtered, seat)
+	}
+
+	sortSeats(filtered)
+	return filtered, nil
 }
 
-// UpdateFlight updates the details of a flight by its ID
+// UpdateFlight updates the details of a flight by its ID, provided it is still at flight.Version
 func (s *FlightServiceImpl) UpdateFlight(id string, flight *models.Flight) (*models.Flight, error) {
 	if id == "" {
-		return nil, errors.New("flight ID cannot be empty")
+		return nil, models.InvalidField("flightID", "flight ID cannot be empty")
 	}
 	if flight == nil {
-		return nil, errors.New("flight details cannot be nil")
+		return nil, models.Invalid("flight details cannot be nil")
+	}
+	if err := checkFlight(flight); err != nil {
+		return nil, err
 	}
 
 	// Fetch the existing flight to ensure it exists
@@ -85,7 +215,7 @@ func (s *FlightServiceImpl) UpdateFlight(id string, flight *models.Flight) (*mod
 		return nil, err
 	}
 	if existingFlight == nil {
-		return nil, errors.New("flight not found")
+		return nil, models.NotFound("flight")
 	}
 
 	// Update the flight details
@@ -97,10 +227,38 @@ func (s *FlightServiceImpl) UpdateFlight(id string, flight *models.Flight) (*mod
 	return updatedFlight, nil
 }
 
-// DeleteFlight deletes a flight by its ID
-func (s *FlightServiceImpl) DeleteFlight(id string) error {
+// PatchFlight applies a JSON merge patch to a flight, provided it is still at
+// version. Only the fields named in the patch are written.
+func (s *FlightServiceImpl) PatchFlight(id string, patch []byte, version int64) (*models.Flight, error) {
+	existingFlight, err := s.GetFlightByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if existingFlight == nil {
+		return nil, models.NotFound("flight")
+	}
+	if existingFlight.Version != version {
+		return nil, db.ErrVersionConflict
+	}
+
+	var flight models.Flight
+	fields, err := applyMergePatch(existingFlight, patch, &flight, "flightID", "version")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFlight(&flight); err != nil {
+		return nil, err
+	}
+
+	flight.FlightID = id
+	flight.Version = version
+	return s.flightRepo.PatchFlight(id, &flight, fields)
+}
+
+// DeleteFlight deletes a flight by its ID, provided it is still at version
+func (s *FlightServiceImpl) DeleteFlight(id string, version int64) error {
 	if id == "" {
-		return errors.New("flight ID cannot be empty")
+		return models.InvalidField("flightID", "flight ID cannot be empty")
 	}
 
 	// Fetch the existing flight to ensure it exists
@@ -109,7 +267,7 @@ func (s *FlightServiceImpl) DeleteFlight(id string) error {
 		return err
 	}
 	if existingFlight == nil {
-		return errors.New("flight not found")
+		return models.NotFound("flight")
 	}
 
 	// Check if there are any bookings associated with the flight
@@ -118,14 +276,56 @@ func (s *FlightServiceImpl) DeleteFlight(id string) error {
 		return err
 	}
 	if len(bookings) > 0 {
-		return errors.New("flight has active bookings and cannot be deleted")
+		return models.Conflict("flight_has_bookings", "flight has active bookings and cannot be deleted")
 	}
 
 	// Delete the flight
-	errr := s.flightRepo.DeleteFlight(id)
+	errr := s.flightRepo.DeleteFlight(id, version)
 	if errr != nil {
 		return errr
 	}
 
-	return nil
+	// Remove the flight's seat inventory
+	return s.seatRepo.DeleteSeatsByFlightID(id)
+}
+
+// checkFlight normalizes the codes of a flight and validates its schedule
+func checkFlight(flight *models.Flight) error {
+	flight.Airline = strings.ToUpper(strings.TrimSpace(flight.Airline))
+	flight.Origin = strings.ToUpper(strings.TrimSpace(flight.Origin))
+	flight.Destination = strings.ToUpper(strings.TrimSpace(flight.Destination))
+	return models.Validate(flight)
+}
+
+// flightInventories reads the seat counters of several flights at once. Flights
+// whose counter does not record their capacity, such as flights booked before
+// it was recorded, have their seats counted instead.
+func flightInventories(seatRepo db.SeatRepository, flightIDs []string) (map[string]models.FlightInventory, error) {
+	inventories, err := seatRepo.GetFlightInventories(flightIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, flightID := range flightIDs {
+		inventory := inventories[flightID]
+		if inventory.Capacity > 0 {
+			continue
+		}
+		seats, err := seatRepo.GetSeatsByFlightID(flightID)
+		if err != nil {
+			return nil, err
+		}
+		inventory.FlightID = flightID
+		inventory.Capacity = len(seats)
+		inventories[flightID] = inventory
+	}
+	return inventories, nil
+}
+
+// seatsForSale returns how many of a flight's seats are not yet taken by
+// confirmed bookings
+func seatsForSale(inventory models.FlightInventory) int {
+	if forSale := inventory.Capacity - inventory.SeatsSold; forSale > 0 {
+		return forSale
+	}
+	return 0
 }
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
+)
+
+type HotelReservationServiceImpl struct {
+	reservationRepo db.HotelReservationRepository
+	hotelRepo       db.HotelRepository
+	roomTypeRepo    db.RoomTypeRepository
+	inventoryRepo   db.RoomInventoryRepository
+	bookingRepo     db.BookingRepository
+	flexibleDays    int
+}
+
+// NewHotelReservationService creates a new instance of HotelReservationServiceImpl.
+// flexibleDays is how many days either side of a sold-out check-in are
+// searched for flexible guests; zero or less uses DefaultFlexibleCheckinDays.
+func NewHotelReservationService(reservationRepo db.HotelReservationRepository, hotelRepo db.HotelRepository, roomTypeRepo db.RoomTypeRepository, inventoryRepo db.RoomInventoryRepository, bookingRepo db.BookingRepository, flexibleDays int) *HotelReservationServiceImpl {
+	if flexibleDays <= 0 {
+		flexibleDays = DefaultFlexibleCheckinDays
+	}
+	return &HotelReservationServiceImpl{
+		reservationRepo: reservationRepo,
+		hotelRepo:       hotelRepo,
+		roomTypeRepo:    roomTypeRepo,
+		inventoryRepo:   inventoryRepo,
+		bookingRepo:     bookingRepo,
+		flexibleDays:    flexibleDays,
+	}
+}
+
+// GetHotelReservations retrieves the reservations made at a hotel
+func (s *HotelReservationServiceImpl) GetHotelReservations(hotelID string) ([]models.HotelReservation, error) {
+	if _, err := getHotel(s.hotelRepo, hotelID); err != nil {
+		return nil, err
+	}
+	return s.reservationRepo.GetReservationsByHotelID(hotelID)
+}
+
+// GetHotelReservation retrieves a single reservation of a hotel
+func (s *HotelReservationServiceImpl) GetHotelReservation(hotelID, reservationID string) (*models.HotelReservation, error) {
+	return s.getReservation(hotelID, reservationID)
+}
+
+// CreateHotelReservation reserves a room type of a hotel for the stay described
+// in reservation. The reservation is attached to an existing booking and belongs
+// to the booking's user. One room is taken from the inventory of every night of
+// the stay; if any night is sold out a *models.RoomsSoldOutError is returned,
+// listing alternative dates when the guest's check-in is flexible.
+func (s *HotelReservationServiceImpl) CreateHotelReservation(hotelID string, reservation *models.HotelReservation) error {
+	if reservation == nil {
+		return models.Invalid("reservation details cannot be nil")
+	}
+	reservation.CheckInDate = stayDate(reservation.CheckInDate)
+	reservation.CheckOutDate = stayDate(reservation.CheckOutDate)
+	if err := models.Validate(reservation); err != nil {
+		return err
+	}
+	if _, err := getHotel(s.hotelRepo, hotelID); err != nil {
+		return err
+	}
+	roomType, err := getRoomType(s.roomTypeRepo, hotelID, reservation.RoomTypeID)
+	if err != nil {
+		return err
+	}
+
+	booking, err := s.bookingRepo.GetBookingByID(reservation.BookingID)
+	if err != nil {
+		return err
+	}
+	if booking == nil {
+		return models.NotFound("booking")
+	}
+
+	if reservation.NumberOfGuests > roomType.MaxGuests {
+		return models.Invalid("room type %s sleeps at most %d guests", roomType.Name, roomType.MaxGuests)
+	}
+	nights := stayNights(reservation.CheckInDate, reservation.CheckOutDate)
+	if len(nights) > maxStayNights {
+		return models.Invalid("a reservation can last at most %d nights", maxStayNights)
+	}
+
+	reservation.HotelID = hotelID
+	reservation.UserID = booking.UserID
+	err = reserveRoom(s.reservationRepo, s.inventoryRepo, *roomType, reservation)
+	if errors.Is(err, db.ErrRoomsSoldOut) {
+		soldOut := &models.RoomsSoldOutError{
+			RoomTypeID:   roomType.RoomTypeID,
+			CheckInDate:  reservation.CheckInDate,
+			CheckOutDate: reservation.CheckOutDate,
+		}
+		if reservation.IsCheckinFlexible {
+			soldOut.Alternatives, err = s.alternativeStays(*roomType, reservation.CheckInDate, len(nights))
+			if err != nil {
+				return err
+			}
+		}
+		return soldOut
+	}
+	return err
+}
+
+// CancelHotelReservation cancels a confirmed reservation and puts its rooms back on sale
+func (s *HotelReservationServiceImpl) CancelHotelReservation(hotelID, reservationID string) (*models.HotelReservation, error) {
+	reservation, err := s.getReservation(hotelID, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.Status == models.ReservationCancelled {
+		return nil, models.Conflict("reservation_cancelled", "reservation is already cancelled")
+	}
+	return cancelReservation(s.reservationRepo, s.inventoryRepo, reservation)
+}
+
+// reserveRoom takes one room of a type on every night of a stay and stores the
+// reservation as confirmed. The rooms are given back if the reservation cannot
+// be stored. Sold-out stays fail with db.ErrRoomsSoldOut.
+func reserveRoom(reservationRepo db.HotelReservationRepository, inventoryRepo db.RoomInventoryRepository, roomType models.RoomType, reservation *models.HotelReservation) error {
+	nights := stayNights(reservation.CheckInDate, reservation.CheckOutDate)
+	if err := inventoryRepo.ReserveRoomNights(roomType.HotelID, roomType.RoomTypeID, nights, roomType.TotalRooms); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	reservation.ReservationID = utils.NewID()
+	reservation.Status = models.ReservationConfirmed
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+	if err := reservationRepo.CreateReservation(reservation); err != nil {
+		// Give the rooms back so a failed write does not leak inventory
+		if releaseErr := inventoryRepo.ReleaseRoomNights(roomType.HotelID, roomType.RoomTypeID, nights); releaseErr != nil {
+			log.Printf("Error releasing rooms for failed reservation %s: %v", reservation.ReservationID, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// cancelReservation marks a reservation cancelled and puts its rooms back on sale
+func cancelReservation(reservationRepo db.HotelReservationRepository, inventoryRepo db.RoomInventoryRepository, reservation *models.HotelReservation) (*models.HotelReservation, error) {
+	reservation.Status = models.ReservationCancelled
+	reservation.UpdatedAt = time.Now().UTC()
+	updated, err := reservationRepo.UpdateReservation(reservation.ReservationID, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reservations migrated from legacy hotel rows never took inventory, so a
+	// failed release is logged rather than undoing the cancellation
+	nights := stayNights(reservation.CheckInDate, reservation.CheckOutDate)
+	if err := inventoryRepo.ReleaseRoomNights(reservation.HotelID, reservation.RoomTypeID, nights); err != nil {
+		log.Printf("Error releasing rooms for cancelled reservation %s: %v", reservation.ReservationID, err)
+	}
+	return updated, nil
+}
+
+// alternativeStays looks for stays of the same length starting up to
+// flexibleDays before or after checkIn, closest first, skipping past dates
+func (s *HotelReservationServiceImpl) alternativeStays(roomType models.RoomType, checkIn time.Time, nights int) ([]models.StayDates, error) {
+	today := stayDate(time.Now().UTC())
+
+	var alternatives []models.StayDates
+	for shift := 1; shift <= s.flexibleDays; shift++ {
+		for _, offset := range []int{-shift, shift} {
+			start := checkIn.AddDate(0, 0, offset)
+			if start.Before(today) {
+				continue
+			}
+			end := start.AddDate(0, 0, nights)
+
+			calendar, err := roomCalendar(s.inventoryRepo, roomType, stayNights(start, end))
+			if err != nil {
+				return nil, err
+			}
+			if roomsAvailable(calendar) {
+				alternatives = append(alternatives, models.StayDates{CheckInDate: start, CheckOutDate: end})
+			}
+		}
+	}
+	return alternatives, nil
+}
+
+func (s *HotelReservationServiceImpl) getReservation(hotelID, reservationID string) (*models.HotelReservation, error) {
+	if hotelID == "" || reservationID == "" {
+		return nil, models.Invalid("hotel ID and reservation ID are required")
+	}
+	reservation, err := s.reservationRepo.GetReservationByID(reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil || reservation.HotelID != hotelID {
+		return nil, models.NotFound("reservation")
+	}
+	return reservation, nil
+}
+
+// stayDate reduces a check-in or check-out time to its calendar day in UTC
+func stayDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+)
+
+// maxSearchRadiusKm caps radius searches, which keeps the number of geohash
+// cells read per search small
+const maxSearchRadiusKm = 100
+
+// GetHotelsNear returns the hotels within radiusKm of a point, nearest first
+func (s *HotelServiceImpl) GetHotelsNear(center models.GeoPoint, radiusKm float64) ([]models.NearbyHotel, error) {
+	if err := checkRadiusSearch(center, radiusKm); err != nil {
+		return nil, err
+	}
+	return s.hotelRepo.GetHotelsNear(center, radiusKm)
+}
+
+// SearchHotels finds the hotels in a city, or within RadiusKm of Near, that
+// can sell the whole stay: at least one room type has enough rooms free on
+// every night, sleeps the guests spread evenly over the requested rooms, and
+// has a rate plan that prices the stay. Only hotels of chains the tenant sells
+// are offered. Hotels are filtered by star rating and property amenities and
+// ordered by their cheapest offer, by star rating or by distance from Near.
+func (s *HotelServiceImpl) SearchHotels(search models.HotelSearch) ([]models.HotelSearchResult, error) {
+	city := strings.TrimSpace(search.City)
+	if city == "" && search.Near == nil {
+		return nil, models.Invalid("city or location is required")
+	}
+	if city != "" && search.Near != nil {
+		return nil, models.Invalid("search by city or by location, not both")
+	}
+	if search.Near != nil {
+		if err := checkRadiusSearch(*search.Near, search.RadiusKm); err != nil {
+			return nil, err
+		}
+	}
+	nights, err := parseStay(search.CheckInDate, search.CheckOutDate)
+	if err != nil {
+		return nil, err
+	}
+
+	guests, rooms := search.Guests, search.Rooms
+	if guests == 0 {
+		guests = 1
+	}
+	if rooms == 0 {
+		rooms = 1
+	}
+	if guests < 0 || rooms < 0 {
+		return nil, models.Invalid("guest and room counts must be positive")
+	}
+	if guests < rooms {
+		return nil, models.Invalid("every room needs at least one guest")
+	}
+	if search.MinStars < 0 || search.MinStars > 5 {
+		return nil, models.InvalidField("starRating", "star rating must be between 0 and 5")
+	}
+
+	sortBy := search.SortBy
+	if sortBy == "" {
+		sortBy = models.HotelSortPrice
+	}
+	switch {
+	case sortBy == models.HotelSortDistance && search.Near == nil:
+		return nil, models.Invalid("sorting by distance needs a location")
+	case sortBy != models.HotelSortPrice && sortBy != models.HotelSortStars && sortBy != models.HotelSortDistance:
+		return nil, models.InvalidField("sort", "sort must be price, stars or distance")
+	}
+
+	var hotels []models.NearbyHotel
+	if search.Near != nil {
+		hotels, err = s.hotelRepo.GetHotelsNear(*search.Near, search.RadiusKm)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		properties, err := s.hotelRepo.GetHotelsByCity(city)
+		if err != nil {
+			return nil, err
+		}
+		for _, property := range properties {
+			hotels = append(hotels, models.NearbyHotel{Property: property})
+		}
+	}
+
+	// Guests are spread as evenly as possible, so the fullest room decides
+	// which room types fit and what each room is priced for
+	guestsPerRoom := (guests + rooms - 1) / rooms
+
+	results := make([]models.HotelSearchResult, 0, len(hotels))
+	for _, hotel := range hotels {
+		if search.Country != "" && !strings.EqualFold(hotel.Address.Country, search.Country) {
+			continue
+		}
+		if hotel.StarRating < search.MinStars || !hasAmenities(hotel.Amenities, search.Amenities) {
+			continue
+		}
+		if !s.tenant.AllowsHotelChain(hotel.Chain) {
+			continue
+		}
+
+		offers, err := s.roomOffers(hotel.Property, search.CheckInDate, search.CheckOutDate, nights, guestsPerRoom, rooms)
+		if err != nil {
+			return nil, err
+		}
+		if len(offers) == 0 {
+			continue
+		}
+		result := models.HotelSearchResult{
+			Property:    hotel.Property,
+			Offers:      offers,
+			Currency:    offers[0].Quote.Currency,
+			LowestTotal: offers[0].Total,
+		}
+		if search.Near != nil {
+			distance := hotel.DistanceKm
+			result.DistanceKm = &distance
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		switch {
+		case sortBy == models.HotelSortStars && results[i].StarRating != results[j].StarRating:
+			return results[i].StarRating > results[j].StarRating
+		case sortBy == models.HotelSortDistance && *results[i].DistanceKm != *results[j].DistanceKm:
+			return *results[i].DistanceKm < *results[j].DistanceKm
+		}
+		return results[i].LowestTotal < results[j].LowestTotal
+	})
+	return results, nil
+}
+
+// roomOffers prices the requested rooms in each room type of a hotel that can
+// sell them on every night of the stay, cheapest first
+func (s *HotelServiceImpl) roomOffers(hotel models.Property, checkIn, checkOut string, nights []string, guestsPerRoom, rooms int) ([]models.RoomOffer, error) {
+	ratePlans, err := s.ratePlanRepo.GetRatePlansByHotelID(hotel.HotelID)
+	if err != nil || len(ratePlans) == 0 {
+		return nil, err
+	}
+	roomTypes, err := s.roomTypeRepo.GetRoomTypesByHotelID(hotel.HotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var offers []models.RoomOffer
+	for _, roomType := range roomTypes {
+		if roomType.MaxGuests < guestsPerRoom {
+			continue
+		}
+
+		calendar, err := roomCalendar(s.inventoryRepo, roomType, nights)
+		if err != nil {
+			return nil, err
+		}
+		available := roomsFree(calendar)
+		if available < rooms {
+			continue
+		}
+
+		request := models.StayQuoteRequest{
+			RoomTypeID:     roomType.RoomTypeID,
+			CheckInDate:    checkIn,
+			CheckOutDate:   checkOut,
+			NumberOfGuests: guestsPerRoom,
+		}
+		quotes, _, err := quoteRatePlans(s.ratePlanRepo, ratePlans, request, nights, s.tenant.Markup)
+		if err != nil {
+			return nil, err
+		}
+		if len(quotes) == 0 {
+			continue
+		}
+
+		offers = append(offers, models.RoomOffer{
+			RoomTypeID:     roomType.RoomTypeID,
+			Name:           roomType.Name,
+			MaxGuests:      roomType.MaxGuests,
+			RoomsAvailable: available,
+			Quote:          quotes[0],
+			Total:          quotes[0].Total * int64(rooms),
+		})
+	}
+
+	sort.SliceStable(offers, func(i, j int) bool {
+		return offers[i].Total < offers[j].Total
+	})
+	return offers, nil
+}
+
+// hasAmenities reports whether every wanted amenity is offered, ignoring case
+func hasAmenities(offered, wanted []string) bool {
+	for _, amenity := range wanted {
+		found := false
+		for _, candidate := range offered {
+			if strings.EqualFold(strings.TrimSpace(candidate), strings.TrimSpace(amenity)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func checkRadiusSearch(center models.GeoPoint, radiusKm float64) error {
+	if center.Latitude < -90 || center.Latitude > 90 || center.Longitude < -180 || center.Longitude > 180 {
+		return models.InvalidField("location", "location is not a valid latitude and longitude")
+	}
+	if radiusKm <= 0 || radiusKm > maxSearchRadiusKm {
+		return models.InvalidField("radiusKm", "radius must be between 0 and %d km", maxSearchRadiusKm)
+	}
+	return nil
+}
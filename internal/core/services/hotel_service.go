@@ -1,87 +1,341 @@
 package services
 
 import (
-	"errors"
+	"strings"
+	"time"
 	"travel-backend/internal/core/domain/models"
 	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
 )
 
 type HotelServiceImpl struct {
-	hotelRepo   db.HotelRepository
-	bookingRepo db.BookingRepository
+	hotelRepo       db.HotelRepository
+	roomTypeRepo    db.RoomTypeRepository
+	reservationRepo db.HotelReservationRepository
+	inventoryRepo   db.RoomInventoryRepository
+	ratePlanRepo    db.RatePlanRepository
+	tenant          *models.Tenant
 }
 
-// NewHotelService creates a new instance of HotelServiceImpl
-func NewHotelService(hotelRepo db.HotelRepository, bookingRepo db.BookingRepository) *HotelServiceImpl {
+// NewHotelService creates a new instance of HotelServiceImpl whose searches
+// offer the hotel chains tenant sells, priced at its markup
+func NewHotelService(hotelRepo db.HotelRepository, roomTypeRepo db.RoomTypeRepository, reservationRepo db.HotelReservationRepository, inventoryRepo db.RoomInventoryRepository, ratePlanRepo db.RatePlanRepository, tenant *models.Tenant) *HotelServiceImpl {
 	return &HotelServiceImpl{
-		hotelRepo:   hotelRepo,
-		bookingRepo: bookingRepo,
+		hotelRepo:       hotelRepo,
+		roomTypeRepo:    roomTypeRepo,
+		reservationRepo: reservationRepo,
+		inventoryRepo:   inventoryRepo,
+		ratePlanRepo:    ratePlanRepo,
+		tenant:          tenant,
 	}
 }
 
-// GetAllHotels retrieves all hotels from the repository
-func (s *HotelServiceImpl) GetAllHotels() ([]models.Hotel, error) {
-	return s.hotelRepo.GetAllHotels()
+// ListHotels returns one page of the hotels matching query
+func (s *HotelServiceImpl) ListHotels(query models.HotelQuery) (*models.HotelPage, error) {
+	query.City = strings.TrimSpace(query.City)
+	query.Country = strings.ToUpper(strings.TrimSpace(query.Country))
+	if query.MinStars < 0 || query.MinStars > 5 {
+		return nil, models.InvalidField("minStars", "minimum star rating must be between 0 and 5")
+	}
+	err := checkPage(&query.Page, func(field string) bool {
+		_, ok := models.HotelSortKeys[field]
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.hotelRepo.ListHotels(query)
 }
 
 // GetHotelByID retrieves a hotel by its ID
-func (s *HotelServiceImpl) GetHotelByID(id string) (*models.Hotel, error) {
+func (s *HotelServiceImpl) GetHotelByID(id string) (*models.Property, error) {
 	if id == "" {
-		return nil, errors.New("hotel ID cannot be empty")
+		return nil, models.InvalidField("hotelID", "hotel ID cannot be empty")
 	}
 	return s.hotelRepo.GetHotelByID(id)
 }
 
 // CreateHotel creates a new hotel in the repository
-func (s *HotelServiceImpl) CreateHotel(hotel *models.Hotel) error {
+func (s *HotelServiceImpl) CreateHotel(hotel *models.Property) error {
 	if hotel == nil {
-		return errors.New("hotel details cannot be nil")
+		return models.Invalid("hotel details cannot be nil")
 	}
 	if hotel.HotelID == "" {
-		return errors.New("hotel ID is required")
+		return models.InvalidField("hotelID", "hotel ID is required")
 	}
+	if err := checkProperty(hotel); err != nil {
+		return err
+	}
+
+	hotel.CreatedAt = time.Now().UTC()
+	hotel.UpdatedAt = hotel.CreatedAt
 	return s.hotelRepo.CreateHotel(hotel)
 }
 
-// GetHotelBookings retrieves all bookings for a specific hotel
-func (s *HotelServiceImpl) GetHotelBookings(hotelID string) ([]models.Booking, error) {
-	if hotelID == "" {
-		return nil, errors.New("hotel ID cannot be empty")
+// UpdateHotel updates a hotel's details, provided it is still at hotel.Version
+func (s *HotelServiceImpl) UpdateHotel(id string, hotel *models.Property) (*models.Property, error) {
+	if id == "" {
+		return nil, models.InvalidField("hotelID", "hotel ID cannot be empty")
+	}
+	if hotel == nil {
+		return nil, models.Invalid("hotel details cannot be nil")
+	}
+	if err := checkProperty(hotel); err != nil {
+		return nil, err
 	}
 
-	// Assuming bookingRepo has a method to get bookings by hotelID
-	return s.bookingRepo.GetBookingsByUserID(hotelID)
+	existingHotel, err := s.getHotel(id)
+	if err != nil {
+		return nil, err
+	}
+
+	hotel.CreatedAt = existingHotel.CreatedAt
+	hotel.UpdatedAt = time.Now().UTC()
+	return s.hotelRepo.UpdateHotel(id, hotel)
 }
 
-// UpdateHotel updates a hotel's details
-func (s *HotelServiceImpl) UpdateHotel(id string, hotel *models.Hotel) (*models.Hotel, error) {
-	if id == "" {
-		return nil, errors.New("hotel ID cannot be empty")
+// PatchHotel applies a JSON merge patch to a hotel, provided it is still at
+// version. Only the fields named in the patch are written.
+func (s *HotelServiceImpl) PatchHotel(id string, patch []byte, version int64) (*models.Property, error) {
+	existingHotel, err := s.getHotel(id)
+	if err != nil {
+		return nil, err
 	}
-	if hotel == nil {
-		return nil, errors.New("hotel details cannot be nil")
+	if existingHotel.Version != version {
+		return nil, db.ErrVersionConflict
 	}
 
-	// Assuming hotelRepo has a method to update a hotel
-	updatedHotel, err := s.hotelRepo.UpdateHotel(id, hotel)
+	var hotel models.Property
+	fields, err := applyMergePatch(existingHotel, patch, &hotel, "hotelID", "version", "createdAt", "updatedAt")
 	if err != nil {
 		return nil, err
 	}
+	if err := checkProperty(&hotel); err != nil {
+		return nil, err
+	}
 
-	return updatedHotel, nil
+	hotel.HotelID = id
+	hotel.Version = version
+	hotel.CreatedAt = existingHotel.CreatedAt
+	hotel.UpdatedAt = time.Now().UTC()
+	return s.hotelRepo.PatchHotel(id, &hotel, append(fields, "updatedAt"))
 }
 
-// DeleteHotel deletes a hotel by its ID
-func (s *HotelServiceImpl) DeleteHotel(id string) error {
+// DeleteHotel deletes a hotel and its room types, provided the hotel is still
+// at version. Hotels with confirmed reservations cannot be deleted.
+func (s *HotelServiceImpl) DeleteHotel(id string, version int64) error {
 	if id == "" {
-		return errors.New("hotel ID cannot be empty")
+		return models.InvalidField("hotelID", "hotel ID cannot be empty")
+	}
+	hotel, err := s.getHotel(id)
+	if err != nil {
+		return err
+	}
+	// Check the version before removing room types, not only on the final delete
+	if hotel.Version != version {
+		return db.ErrVersionConflict
+	}
+
+	reservations, err := s.reservationRepo.GetReservationsByHotelID(id)
+	if err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if reservation.Status == models.ReservationConfirmed {
+			return models.Conflict("hotel_has_reservations", "hotel has confirmed reservations and cannot be deleted")
+		}
+	}
+
+	roomTypes, err := s.roomTypeRepo.GetRoomTypesByHotelID(id)
+	if err != nil {
+		return err
+	}
+	for _, roomType := range roomTypes {
+		if err := s.roomTypeRepo.DeleteRoomType(roomType.RoomTypeID); err != nil {
+			return err
+		}
+	}
+
+	return s.hotelRepo.DeleteHotel(id, version)
+}
+
+// GetHotelRooms retrieves the room types of a hotel
+func (s *HotelServiceImpl) GetHotelRooms(hotelID string) ([]models.RoomType, error) {
+	if _, err := s.getHotel(hotelID); err != nil {
+		return nil, err
+	}
+	return s.roomTypeRepo.GetRoomTypesByHotelID(hotelID)
+}
+
+// GetHotelRoom retrieves a single room type of a hotel
+func (s *HotelServiceImpl) GetHotelRoom(hotelID, roomTypeID string) (*models.RoomType, error) {
+	return getRoomType(s.roomTypeRepo, hotelID, roomTypeID)
+}
+
+// AddHotelRoom adds a room type to a hotel
+func (s *HotelServiceImpl) AddHotelRoom(hotelID string, roomType *models.RoomType) error {
+	if roomType == nil {
+		return models.Invalid("room type details cannot be nil")
+	}
+	if _, err := s.getHotel(hotelID); err != nil {
+		return err
+	}
+	if err := checkRoomType(roomType); err != nil {
+		return err
+	}
+
+	roomType.HotelID = hotelID
+	if roomType.RoomTypeID == "" {
+		roomType.RoomTypeID = utils.NewID()
+	}
+	return s.roomTypeRepo.CreateRoomType(roomType)
+}
+
+// UpdateHotelRoom replaces a room type's details. The room type stays in the same hotel.
+func (s *HotelServiceImpl) UpdateHotelRoom(hotelID, roomTypeID string, roomType *models.RoomType) (*models.RoomType, error) {
+	if roomType == nil {
+		return nil, models.Invalid("room type details cannot be nil")
+	}
+	if _, err := getRoomType(s.roomTypeRepo, hotelID, roomTypeID); err != nil {
+		return nil, err
+	}
+	if err := checkRoomType(roomType); err != nil {
+		return nil, err
+	}
+
+	roomType.HotelID = hotelID
+	return s.roomTypeRepo.UpdateRoomType(roomTypeID, roomType)
+}
+
+// RemoveHotelRoom removes a room type from a hotel
+func (s *HotelServiceImpl) RemoveHotelRoom(hotelID, roomTypeID string) error {
+	if _, err := getRoomType(s.roomTypeRepo, hotelID, roomTypeID); err != nil {
+		return err
 	}
+	return s.roomTypeRepo.DeleteRoomType(roomTypeID)
+}
 
-	// Assuming hotelRepo has a method to delete a hotel
-	err := s.hotelRepo.DeleteHotel(id)
+// GetHotelAvailability returns the inventory calendar of every room type of a
+// hotel, or of roomTypeID only when set, for the nights from up to but
+// excluding to
+func (s *HotelServiceImpl) GetHotelAvailability(hotelID, roomTypeID, from, to string) ([]models.RoomAvailability, error) {
+	dates, err := parseNightRange(from, to)
 	if err != nil {
+		return nil, err
+	}
+
+	var roomTypes []models.RoomType
+	if roomTypeID != "" {
+		roomType, err := getRoomType(s.roomTypeRepo, hotelID, roomTypeID)
+		if err != nil {
+			return nil, err
+		}
+		roomTypes = []models.RoomType{*roomType}
+	} else {
+		roomTypes, err = s.GetHotelRooms(hotelID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	availability := make([]models.RoomAvailability, 0, len(roomTypes))
+	for _, roomType := range roomTypes {
+		calendar, err := roomCalendar(s.inventoryRepo, roomType, dates)
+		if err != nil {
+			return nil, err
+		}
+		availability = append(availability, models.RoomAvailability{
+			RoomTypeID: roomType.RoomTypeID,
+			Name:       roomType.Name,
+			Nights:     calendar,
+		})
+	}
+	return availability, nil
+}
+
+// SetRoomAllotment sets how many rooms of a type are for sale on each night of
+// the allotment's range, overriding the room type's TotalRooms. The range is
+// set all at once, so it is limited to maxAllotmentNights.
+func (s *HotelServiceImpl) SetRoomAllotment(hotelID, roomTypeID string, allotment models.RoomAllotment) error {
+	if _, err := getRoomType(s.roomTypeRepo, hotelID, roomTypeID); err != nil {
 		return err
 	}
+	if allotment.Total < 0 {
+		return models.InvalidField("total", "allotment cannot be negative")
+	}
+	dates, err := parseNightRange(allotment.From, allotment.To)
+	if err != nil {
+		return err
+	}
+	if len(dates) > maxAllotmentNights {
+		return models.InvalidField("to", "an allotment can cover at most %d nights; set longer ranges in several requests", maxAllotmentNights)
+	}
+	return s.inventoryRepo.SetAllotment(hotelID, roomTypeID, dates, allotment.Total)
+}
+
+func (s *HotelServiceImpl) getHotel(hotelID string) (*models.Property, error) {
+	return getHotel(s.hotelRepo, hotelID)
+}
+
+func getHotel(hotelRepo db.HotelRepository, hotelID string) (*models.Property, error) {
+	if hotelID == "" {
+		return nil, models.InvalidField("hotelID", "hotel ID cannot be empty")
+	}
+	hotel, err := hotelRepo.GetHotelByID(hotelID)
+	if err != nil {
+		return nil, err
+	}
+	if hotel == nil {
+		return nil, models.NotFound("hotel")
+	}
+	return hotel, nil
+}
 
+// checkHotelChain makes sure the hotel exists and belongs to a chain the
+// tenant sells
+func checkHotelChain(hotelRepo db.HotelRepository, tenant *models.Tenant, hotelID string) error {
+	hotel, err := getHotel(hotelRepo, hotelID)
+	if err != nil {
+		return err
+	}
+	if !tenant.AllowsHotelChain(hotel.Chain) {
+		if hotel.Chain == "" {
+			return models.Forbidden(models.CodeSupplierNotAllowed, "this agency does not sell independent hotels")
+		}
+		return models.Forbidden(models.CodeSupplierNotAllowed, "this agency does not sell hotels of chain %s", hotel.Chain)
+	}
 	return nil
 }
+
+// getRoomType loads a room type and checks that it belongs to the hotel
+func getRoomType(roomTypeRepo db.RoomTypeRepository, hotelID, roomTypeID string) (*models.RoomType, error) {
+	if hotelID == "" || roomTypeID == "" {
+		return nil, models.Invalid("hotel ID and room type ID are required")
+	}
+	roomType, err := roomTypeRepo.GetRoomTypeByID(roomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	if roomType == nil || roomType.HotelID != hotelID {
+		return nil, models.NotFound("room type")
+	}
+	return roomType, nil
+}
+
+func checkProperty(hotel *models.Property) error {
+	hotel.Name = strings.TrimSpace(hotel.Name)
+	hotel.Chain = strings.ToUpper(strings.TrimSpace(hotel.Chain))
+	hotel.Address.Country = strings.ToUpper(strings.TrimSpace(hotel.Address.Country))
+	return models.Validate(hotel)
+}
+
+func checkRoomType(roomType *models.RoomType) error {
+	roomType.Name = strings.TrimSpace(roomType.Name)
+	if roomType.MaxGuests == 0 {
+		roomType.MaxGuests = roomType.MaxAdults + roomType.MaxChildren
+	}
+	for i := range roomType.Beds {
+		roomType.Beds[i].Type = strings.ToUpper(roomType.Beds[i].Type)
+	}
+	return models.Validate(roomType)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+type ItineraryServiceImpl struct {
+	flightRepo db.FlightRepository
+	rules      models.ConnectionRules
+}
+
+// NewItineraryService creates a new instance of ItineraryServiceImpl that
+// connects flights within the given connection rules
+func NewItineraryService(flightRepo db.FlightRepository, rules models.ConnectionRules) *ItineraryServiceImpl {
+	return &ItineraryServiceImpl{
+		flightRepo: flightRepo,
+		rules:      rules,
+	}
+}
+
+// SearchItineraries composes direct flights and connections of up to
+// search.MaxStops stops from origin to destination. Every layover must respect
+// the connection time of its airport. Itineraries are ranked by total
+// duration, then by number of stops, then by departure time.
+func (s *ItineraryServiceImpl) SearchItineraries(search models.ItinerarySearch) ([]models.Itinerary, error) {
+	origin := strings.ToUpper(strings.TrimSpace(search.Origin))
+	destination := strings.ToUpper(strings.TrimSpace(search.Destination))
+	if origin == "" || destination == "" {
+		return nil, models.Invalid("origin and destination are required")
+	}
+	if origin == destination {
+		return nil, models.InvalidField("destination", "origin and destination must differ")
+	}
+	if _, err := time.Parse(models.FlightDateLayout, search.Date); err != nil {
+		return nil, models.InvalidField("date", "departure date must be formatted as YYYY-MM-DD")
+	}
+	if search.MaxStops < 0 || search.MaxStops > models.MaxItineraryStops {
+		return nil, models.InvalidField("maxStops", "max stops must be between 0 and %d", models.MaxItineraryStops)
+	}
+
+	finder := &departureFinder{flightRepo: s.flightRepo, cache: make(map[string][]models.Flight)}
+	firstLegs, err := finder.departures(origin, search.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var itineraries []models.Itinerary
+	var extend func(legs []models.Flight) error
+	extend = func(legs []models.Flight) error {
+		last := legs[len(legs)-1]
+		if strings.EqualFold(last.Destination, destination) {
+			itineraries = append(itineraries, buildItinerary(legs))
+			return nil
+		}
+		if len(legs) > search.MaxStops {
+			return nil
+		}
+
+		airport := strings.ToUpper(last.Destination)
+		window := s.rules.For(airport)
+		nextLegs, err := finder.departuresBetween(airport, last.ArrivalTime.Add(window.Min), last.ArrivalTime.Add(window.Max))
+		if err != nil {
+			return err
+		}
+		for _, next := range nextLegs {
+			if visits(legs, next.Destination) {
+				continue
+			}
+			if err := extend(append(append([]models.Flight(nil), legs...), next)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, flight := range firstLegs {
+		if err := extend([]models.Flight{flight}); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(itineraries, func(i, j int) bool {
+		a, b := itineraries[i], itineraries[j]
+		if a.TotalDuration() != b.TotalDuration() {
+			return a.TotalDuration() < b.TotalDuration()
+		}
+		if a.Stops != b.Stops {
+			return a.Stops < b.Stops
+		}
+		return a.DepartureTime.Before(b.DepartureTime)
+	})
+	return itineraries, nil
+}
+
+// departureFinder looks up departures per airport and day, remembering each
+// lookup so that airports reached by several legs are only queried once
+type departureFinder struct {
+	flightRepo db.FlightRepository
+	cache      map[string][]models.Flight
+}
+
+func (f *departureFinder) departures(airport, date string) ([]models.Flight, error) {
+	key := airport + "#" + date
+	if flights, ok := f.cache[key]; ok {
+		return flights, nil
+	}
+	flights, err := f.flightRepo.GetFlightsByOrigin(airport, date)
+	if err != nil {
+		return nil, err
+	}
+	f.cache[key] = flights
+	return flights, nil
+}
+
+// departuresBetween returns the flights leaving airport within [earliest, latest],
+// which may span several calendar days
+func (f *departureFinder) departuresBetween(airport string, earliest, latest time.Time) ([]models.Flight, error) {
+	lastDate := latest.In(earliest.Location()).Format(models.FlightDateLayout)
+
+	var flights []models.Flight
+	for day := earliest; day.Format(models.FlightDateLayout) <= lastDate; day = day.AddDate(0, 0, 1) {
+		departures, err := f.departures(airport, day.Format(models.FlightDateLayout))
+		if err != nil {
+			return nil, err
+		}
+		for _, flight := range departures {
+			if !flight.DepartureTime.Before(earliest) && !flight.DepartureTime.After(latest) {
+				flights = append(flights, flight)
+			}
+		}
+	}
+	return flights, nil
+}
+
+// visits reports whether the journey so far has already passed through airport
+func visits(legs []models.Flight, airport string) bool {
+	for _, leg := range legs {
+		if strings.EqualFold(leg.Origin, airport) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildItinerary(legs []models.Flight) models.Itinerary {
+	first, last := legs[0], legs[len(legs)-1]
+	itinerary := models.Itinerary{
+		Legs:          legs,
+		Stops:         len(legs) - 1,
+		DepartureTime: first.DepartureTime,
+		ArrivalTime:   last.ArrivalTime,
+	}
+	for i := 1; i < len(legs); i++ {
+		itinerary.Connections = append(itinerary.Connections, models.Connection{
+			Airport:         strings.ToUpper(legs[i].Origin),
+			DurationMinutes: int(legs[i].DepartureTime.Sub(legs[i-1].ArrivalTime).Minutes()),
+		})
+	}
+	itinerary.TotalDurationMinutes = int(itinerary.TotalDuration().Minutes())
+	return itinerary
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/pagination"
+)
+
+// checkPage applies the default page size and checks the limit, that the
+// sort field, if any, is one of sortable and that the cursor belongs to the
+// same sort order
+func checkPage(page *models.PageRequest, sortable func(field string) bool) error {
+	if page.Limit == 0 {
+		page.Limit = models.DefaultPageLimit
+	}
+	if page.Limit < 0 || page.Limit > models.MaxPageLimit {
+		return models.InvalidField("limit", "limit must be between 1 and %d", models.MaxPageLimit)
+	}
+	if field, _ := page.SortField(); page.Sort != "" && !sortable(field) {
+		return models.InvalidField("sort", "cannot sort by %q", field)
+	}
+	if _, err := pagination.Decode(page.Cursor, page.Sort); err != nil {
+		return models.InvalidField("cursor", "cursor is not valid for this list and sort order")
+	}
+	return nil
+}
+
+// checkDateRange checks that from and to, when set, are dates in
+// FlightDateLayout and that from is not after to
+func checkDateRange(from, to string) error {
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(models.FlightDateLayout, date); err != nil {
+			return models.Invalid("dates must be formatted as YYYY-MM-DD")
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return models.InvalidField("to", "from date must not be after to date")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"strings"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
+)
+
+type MealServiceImpl struct {
+	mealRepo db.MealRepository
+}
+
+// NewMealService creates a new instance of MealServiceImpl
+func NewMealService(mealRepo db.MealRepository) *MealServiceImpl {
+	return &MealServiceImpl{
+		mealRepo: mealRepo,
+	}
+}
+
+// GetMeals retrieves the meal catalogue, optionally limited to one cabin class
+func (s *MealServiceImpl) GetMeals(class string) ([]models.Meal, error) {
+	if class == "" {
+		return s.mealRepo.GetAllMeals()
+	}
+
+	class = strings.ToUpper(class)
+	if !models.IsCabinClass(class) {
+		return nil, models.Invalid("unknown cabin class %q", class)
+	}
+	return s.mealRepo.GetMealsByClass(class)
+}
+
+// GetMealByID retrieves a catalogue meal by its ID
+func (s *MealServiceImpl) GetMealByID(id string) (*models.Meal, error) {
+	if id == "" {
+		return nil, models.InvalidField("mealID", "meal ID cannot be empty")
+	}
+	meal, err := s.mealRepo.GetMealByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if meal == nil {
+		return nil, models.NotFound("meal")
+	}
+	return meal, nil
+}
+
+// AddMeal adds a meal to the catalogue after normalising its classes and dietary tags
+func (s *MealServiceImpl) AddMeal(meal *models.Meal) error {
+	if meal == nil {
+		return models.Invalid("meal details cannot be nil")
+	}
+	if meal.Description == "" {
+		return models.InvalidField("description", "meal description is required")
+	}
+	if len(meal.AvailableClasses) == 0 {
+		return models.InvalidField("cabinClasses", "at least one cabin class is required")
+	}
+
+	for i, class := range meal.AvailableClasses {
+		class = strings.ToUpper(strings.TrimSpace(class))
+		if !models.IsCabinClass(class) {
+			return models.Invalid("unknown cabin class %q", class)
+		}
+		meal.AvailableClasses[i] = class
+	}
+	for i, tag := range meal.DietaryTags {
+		tag = strings.ToUpper(strings.TrimSpace(tag))
+		if _, ok := models.DietaryCodes[tag]; !ok {
+			return models.InvalidField("dietaryTags", "unknown dietary code %q", tag)
+		}
+		meal.DietaryTags[i] = tag
+	}
+
+	if meal.MealID == "" {
+		meal.MealID = utils.NewID()
+	}
+	return s.mealRepo.AddMeal(meal)
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
+)
+
+type PassengerServiceImpl struct {
+	passengerRepo db.PassengerRepository
+	bookingRepo   db.BookingRepository
+	seatRepo      db.SeatRepository
+	mealRepo      db.MealRepository
+}
+
+// NewPassengerService creates a new instance of PassengerServiceImpl
+func NewPassengerService(passengerRepo db.PassengerRepository, bookingRepo db.BookingRepository, seatRepo db.SeatRepository, mealRepo db.MealRepository) *PassengerServiceImpl {
+	return &PassengerServiceImpl{
+		passengerRepo: passengerRepo,
+		bookingRepo:   bookingRepo,
+		seatRepo:      seatRepo,
+		mealRepo:      mealRepo,
+	}
+}
+
+// GetAllPassengersByBookingID retrieves the passengers travelling on a booking
+func (s *PassengerServiceImpl) GetAllPassengersByBookingID(bookingID string) ([]models.Passenger, error) {
+	if _, err := s.getBooking(bookingID); err != nil {
+		return nil, err
+	}
+	return s.passengerRepo.GetAllPassengersByBookingID(bookingID)
+}
+
+// GetPassengerByID retrieves a single passenger of a booking
+func (s *PassengerServiceImpl) GetPassengerByID(bookingID, passengerID string) (*models.Passenger, error) {
+	return s.getPassenger(bookingID, passengerID)
+}
+
+// AddPassenger adds a passenger to an existing booking
+func (s *PassengerServiceImpl) AddPassenger(passenger *models.Passenger) error {
+	if passenger == nil {
+		return models.Invalid("passenger details cannot be nil")
+	}
+	if err := checkPassenger(passenger); err != nil {
+		return err
+	}
+	if _, err := s.getBooking(passenger.BookingID); err != nil {
+		return err
+	}
+
+	if passenger.PassengerID == "" {
+		passenger.PassengerID = utils.NewID()
+	}
+	return s.passengerRepo.AddPassenger(passenger)
+}
+
+// UpdatePassenger replaces a passenger's details. The passenger stays on the same booking.
+func (s *PassengerServiceImpl) UpdatePassenger(bookingID, passengerID string, passenger *models.Passenger) (*models.Passenger, error) {
+	if passenger == nil {
+		return nil, models.Invalid("passenger details cannot be nil")
+	}
+	if err := checkPassenger(passenger); err != nil {
+		return nil, err
+	}
+	if _, err := s.getPassenger(bookingID, passengerID); err != nil {
+		return nil, err
+	}
+
+	passenger.BookingID = bookingID
+	return s.passengerRepo.UpdatePassenger(passengerID, passenger)
+}
+
+// RemovePassenger removes a passenger from a booking and frees their seats.
+// The passenger repository deletes their meal selection along with them.
+func (s *PassengerServiceImpl) RemovePassenger(bookingID, passengerID string) error {
+	if _, err := s.getPassenger(bookingID, passengerID); err != nil {
+		return err
+	}
+
+	seats, err := s.passengerRepo.GetPassengerSeats(bookingID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.passengerRepo.DeletePassenger(passengerID); err != nil {
+		return err
+	}
+
+	for _, seat := range seats {
+		if seat.PassengerID != passengerID {
+			continue
+		}
+		if err := s.seatRepo.UpdateSeatAvailability(seat.SeatID, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AssignSeat gives a passenger a seat on one of the booking's flights. The seat
+// is identified by SeatNumber, and the flight by FlightID unless the booking
+// has a single flight; any seat the passenger held on that flight before is released.
+func (s *PassengerServiceImpl) AssignSeat(passengerSeat *models.Seat) error {
+	if passengerSeat == nil {
+		return models.Invalid("seat details cannot be nil")
+	}
+	if passengerSeat.SeatNumber == "" {
+		return models.InvalidField("seatNumber", "seat number is required")
+	}
+	if _, err := s.getPassenger(passengerSeat.BookingID, passengerSeat.PassengerID); err != nil {
+		return err
+	}
+
+	booking, err := s.getBooking(passengerSeat.BookingID)
+	if err != nil {
+		return err
+	}
+	flightID, err := seatFlight(booking, passengerSeat.FlightID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.findPassengerSeat(passengerSeat.BookingID, passengerSeat.PassengerID, flightID)
+	if err != nil {
+		return err
+	}
+
+	passengerSeat.FlightID = flightID
+	passengerSeat.SeatID = SeatID(flightID, passengerSeat.SeatNumber)
+	if previous != nil && previous.SeatID == passengerSeat.SeatID {
+		*passengerSeat = *previous
+		return nil
+	}
+	if err := s.passengerRepo.AssignSeat(passengerSeat); err != nil {
+		return err
+	}
+
+	// Reload the seat so the caller sees its cabin class
+	seat, err := s.seatRepo.GetSeatByID(passengerSeat.SeatID)
+	if err != nil {
+		return err
+	}
+	if seat != nil {
+		*passengerSeat = *seat
+	}
+
+	// A hold the booking placed on this seat during checkout is now redundant
+	if err := s.seatRepo.ReleaseSeatHold(passengerSeat.SeatID, passengerSeat.BookingID); err != nil && !errors.Is(err, db.ErrSeatHoldNotFound) {
+		log.Printf("Error releasing hold on seat %s: %v", passengerSeat.SeatID, err)
+	}
+
+	if previous != nil {
+		return s.seatRepo.UpdateSeatAvailability(previous.SeatID, true)
+	}
+	return nil
+}
+
+// GetPassengerSeats retrieves the seats assigned to passengers on a booking
+func (s *PassengerServiceImpl) GetPassengerSeats(bookingID string) ([]models.Seat, error) {
+	if _, err := s.getBooking(bookingID); err != nil {
+		return nil, err
+	}
+	return s.passengerRepo.GetPassengerSeats(bookingID)
+}
+
+// AssignMeal records a passenger's meal choice. The meal must be served in the
+// cabin class of the passenger's assigned seat.
+func (s *PassengerServiceImpl) AssignMeal(passengerMeal *models.MealSelection) error {
+	if passengerMeal == nil {
+		return models.Invalid("meal details cannot be nil")
+	}
+	if passengerMeal.MealID == "" {
+		return models.InvalidField("mealID", "meal ID is required")
+	}
+	if _, err := s.getPassenger(passengerMeal.BookingID, passengerMeal.PassengerID); err != nil {
+		return err
+	}
+
+	meal, err := s.mealRepo.GetMealByID(passengerMeal.MealID)
+	if err != nil {
+		return err
+	}
+	if meal == nil {
+		return models.NotFound("meal")
+	}
+
+	seat, err := s.findPassengerSeat(passengerMeal.BookingID, passengerMeal.PassengerID, "")
+	if err != nil {
+		return err
+	}
+	if seat == nil {
+		return models.Conflict("seat_required", "passenger must have a seat assigned before choosing a meal")
+	}
+	if !meal.AvailableClasses.Contains(seat.Class) {
+		return models.InvalidField("mealID", "meal is not served in the passenger's cabin class")
+	}
+
+	passengerMeal.Class = seat.Class
+	passengerMeal.SelectedAt = time.Now().UTC()
+	return s.passengerRepo.AssignMeal(passengerMeal)
+}
+
+// GetPassengerMeals retrieves the meals chosen by passengers on a booking
+func (s *PassengerServiceImpl) GetPassengerMeals(bookingID string) ([]models.MealSelection, error) {
+	if _, err := s.getBooking(bookingID); err != nil {
+		return nil, err
+	}
+	return s.passengerRepo.GetPassengerMeals(bookingID)
+}
+
+// getBooking loads a booking, failing if it does not exist
+func (s *PassengerServiceImpl) getBooking(bookingID string) (*models.Booking, error) {
+	if bookingID == "" {
+		return nil, models.InvalidField("bookingID", "booking ID cannot be empty")
+	}
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking == nil {
+		return nil, models.NotFound("booking")
+	}
+	return booking, nil
+}
+
+// getPassenger loads a passenger, failing unless it belongs to the given booking
+func (s *PassengerServiceImpl) getPassenger(bookingID, passengerID string) (*models.Passenger, error) {
+	if passengerID == "" {
+		return nil, models.InvalidField("passengerID", "passenger ID cannot be empty")
+	}
+	passenger, err := s.passengerRepo.GetPassengerByID(passengerID)
+	if err != nil {
+		return nil, err
+	}
+	if passenger == nil || passenger.BookingID != bookingID {
+		return nil, models.NotFound("passenger")
+	}
+	return passenger, nil
+}
+
+// findPassengerSeat returns the seat currently assigned to a passenger on a
+// flight, or on any flight of the booking when flightID is empty
+func (s *PassengerServiceImpl) findPassengerSeat(bookingID, passengerID, flightID string) (*models.Seat, error) {
+	seats, err := s.passengerRepo.GetPassengerSeats(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	for _, seat := range seats {
+		if seat.PassengerID == passengerID && (flightID == "" || seat.FlightID == flightID) {
+			return &seat, nil
+		}
+	}
+	return nil, nil
+}
+
+// seatFlight picks the flight of a booking a seat is assigned on. The flight
+// may be left out when the booking has only one.
+func seatFlight(booking *models.Booking, flightID string) (string, error) {
+	flightIDs := booking.FlightIDs()
+	switch {
+	case len(flightIDs) == 0:
+		return "", models.Invalid("booking has no flight to assign seats on")
+	case flightID == "" && len(flightIDs) > 1:
+		return "", models.Invalid("flight ID is required for bookings with several flights")
+	case flightID == "":
+		return flightIDs[0], nil
+	case !booking.HasFlight(flightID):
+		return "", models.Invalid("booking is for a different flight")
+	}
+	return flightID, nil
+}
+
+// checkPassenger normalizes the gender and passport number of a passenger and
+// validates their details
+func checkPassenger(passenger *models.Passenger) error {
+	passenger.Name = strings.TrimSpace(passenger.Name)
+	passenger.Gender = strings.ToUpper(strings.TrimSpace(passenger.Gender))
+	passenger.PassportNumber = strings.ToUpper(strings.Join(strings.Fields(passenger.PassportNumber), ""))
+	return models.Validate(passenger)
+}
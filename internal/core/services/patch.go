@@ -0,0 +1,43 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/mergepatch"
+	"travel-backend/pkg/utils"
+)
+
+// applyMergePatch applies an RFC 7396 merge patch to the JSON form of current
+// and decodes the result into out. It returns the top-level fields the patch
+// touches, refusing patches that touch any of the readOnly fields, which the
+// server manages itself.
+func applyMergePatch(current interface{}, patch []byte, out interface{}, readOnly ...string) ([]string, error) {
+	fields, err := mergepatch.Fields(patch)
+	if err != nil {
+		return nil, models.Invalid("invalid merge patch: %v", err)
+	}
+	if len(fields) == 0 {
+		return nil, models.Invalid("merge patch changes nothing")
+	}
+	for _, field := range fields {
+		for _, protected := range readOnly {
+			if field == protected {
+				return nil, models.InvalidField(field, "field %s cannot be changed", field)
+			}
+		}
+	}
+
+	document, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return nil, models.Invalid("invalid merge patch: %v", err)
+	}
+	if err := utils.DecodeJSON(bytes.NewReader(merged), out); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
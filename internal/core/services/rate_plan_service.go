@@ -0,0 +1,354 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+	"travel-backend/pkg/utils"
+)
+
+type RatePlanServiceImpl struct {
+	ratePlanRepo db.RatePlanRepository
+	hotelRepo    db.HotelRepository
+	roomTypeRepo db.RoomTypeRepository
+	tenant       *models.Tenant
+}
+
+// NewRatePlanService creates a new instance of RatePlanServiceImpl that quotes
+// stays at the hotel chains tenant sells, at its markup
+func NewRatePlanService(ratePlanRepo db.RatePlanRepository, hotelRepo db.HotelRepository, roomTypeRepo db.RoomTypeRepository, tenant *models.Tenant) *RatePlanServiceImpl {
+	return &RatePlanServiceImpl{
+		ratePlanRepo: ratePlanRepo,
+		hotelRepo:    hotelRepo,
+		roomTypeRepo: roomTypeRepo,
+		tenant:       tenant,
+	}
+}
+
+// GetRatePlans retrieves the rate plans of a hotel
+func (s *RatePlanServiceImpl) GetRatePlans(hotelID string) ([]models.RatePlan, error) {
+	if _, err := getHotel(s.hotelRepo, hotelID); err != nil {
+		return nil, err
+	}
+	return s.ratePlanRepo.GetRatePlansByHotelID(hotelID)
+}
+
+// GetRatePlan retrieves a single rate plan of a hotel
+func (s *RatePlanServiceImpl) GetRatePlan(hotelID, ratePlanID string) (*models.RatePlan, error) {
+	return s.getRatePlan(hotelID, ratePlanID)
+}
+
+// CreateRatePlan adds a rate plan to a hotel. Its code must be unique within the hotel.
+func (s *RatePlanServiceImpl) CreateRatePlan(hotelID string, ratePlan *models.RatePlan) error {
+	if ratePlan == nil {
+		return models.Invalid("rate plan details cannot be nil")
+	}
+	if _, err := getHotel(s.hotelRepo, hotelID); err != nil {
+		return err
+	}
+	if err := checkRatePlan(ratePlan); err != nil {
+		return err
+	}
+	if err := s.checkCodeUnique(hotelID, "", ratePlan.Code); err != nil {
+		return err
+	}
+
+	ratePlan.HotelID = hotelID
+	if ratePlan.RatePlanID == "" {
+		ratePlan.RatePlanID = utils.NewID()
+	}
+	return s.ratePlanRepo.CreateRatePlan(ratePlan)
+}
+
+// UpdateRatePlan replaces a rate plan's details. Its nightly rates are kept.
+func (s *RatePlanServiceImpl) UpdateRatePlan(hotelID, ratePlanID string, ratePlan *models.RatePlan) (*models.RatePlan, error) {
+	if ratePlan == nil {
+		return nil, models.Invalid("rate plan details cannot be nil")
+	}
+	if _, err := s.getRatePlan(hotelID, ratePlanID); err != nil {
+		return nil, err
+	}
+	if err := checkRatePlan(ratePlan); err != nil {
+		return nil, err
+	}
+	if err := s.checkCodeUnique(hotelID, ratePlanID, ratePlan.Code); err != nil {
+		return nil, err
+	}
+
+	ratePlan.HotelID = hotelID
+	return s.ratePlanRepo.UpdateRatePlan(ratePlanID, ratePlan)
+}
+
+// DeleteRatePlan removes a rate plan and its nightly rates
+func (s *RatePlanServiceImpl) DeleteRatePlan(hotelID, ratePlanID string) error {
+	if _, err := s.getRatePlan(hotelID, ratePlanID); err != nil {
+		return err
+	}
+	return s.ratePlanRepo.DeleteRatePlan(ratePlanID)
+}
+
+// GetRoomRates retrieves the rates a rate plan has set for a room type on the
+// nights from up to but excluding to. Nights without a rate are left out.
+func (s *RatePlanServiceImpl) GetRoomRates(hotelID, ratePlanID, roomTypeID, from, to string) ([]models.RoomRate, error) {
+	if _, err := s.getRatePlan(hotelID, ratePlanID); err != nil {
+		return nil, err
+	}
+	if _, err := getRoomType(s.roomTypeRepo, hotelID, roomTypeID); err != nil {
+		return nil, err
+	}
+	dates, err := parseNightRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.ratePlanRepo.GetRoomRates(ratePlanID, roomTypeID, dates)
+}
+
+// SetRoomRates sets the price and stay restrictions of a room type under a
+// rate plan for every night of the update's range. IncludedGuests defaults to
+// the room type's MaxGuests, so no extra guest charge applies unless set.
+func (s *RatePlanServiceImpl) SetRoomRates(hotelID, ratePlanID string, update models.RoomRateUpdate) error {
+	if _, err := s.getRatePlan(hotelID, ratePlanID); err != nil {
+		return err
+	}
+	roomType, err := getRoomType(s.roomTypeRepo, hotelID, update.RoomTypeID)
+	if err != nil {
+		return err
+	}
+	dates, err := parseNightRange(update.From, update.To)
+	if err != nil {
+		return err
+	}
+
+	if update.Amount < 0 || update.ExtraGuestAmount < 0 {
+		return models.Invalid("rate amounts cannot be negative")
+	}
+	if update.IncludedGuests < 0 || update.MinStay < 0 || update.MaxStay < 0 {
+		return models.Invalid("included guests and stay limits cannot be negative")
+	}
+	if update.MaxStay > 0 && update.MaxStay < update.MinStay {
+		return models.Invalid("maximum stay cannot be shorter than minimum stay")
+	}
+	if update.IncludedGuests == 0 {
+		update.IncludedGuests = roomType.MaxGuests
+	}
+
+	rates := make([]models.RoomRate, 0, len(dates))
+	for _, date := range dates {
+		rates = append(rates, models.RoomRate{
+			RatePlanID:        ratePlanID,
+			RoomTypeID:        update.RoomTypeID,
+			HotelID:           hotelID,
+			Date:              date,
+			Amount:            update.Amount,
+			IncludedGuests:    update.IncludedGuests,
+			ExtraGuestAmount:  update.ExtraGuestAmount,
+			MinStay:           update.MinStay,
+			MaxStay:           update.MaxStay,
+			ClosedToArrival:   update.ClosedToArrival,
+			ClosedToDeparture: update.ClosedToDeparture,
+		})
+	}
+	return s.ratePlanRepo.SetRoomRates(rates)
+}
+
+// QuoteStay prices a stay in a room type under each of the hotel's rate plans,
+// or only under request.RatePlanID when set, cheapest first. Rate plans that
+// cannot sell the stay, because a night has no rate or a stay restriction is
+// not met, are left out; if none can, the reason is returned as an error.
+func (s *RatePlanServiceImpl) QuoteStay(hotelID string, request models.StayQuoteRequest) ([]models.StayQuote, error) {
+	if err := checkHotelChain(s.hotelRepo, s.tenant, hotelID); err != nil {
+		return nil, err
+	}
+	roomType, err := getRoomType(s.roomTypeRepo, hotelID, request.RoomTypeID)
+	if err != nil {
+		return nil, err
+	}
+	nights, err := parseStay(request.CheckInDate, request.CheckOutDate)
+	if err != nil {
+		return nil, err
+	}
+	if request.NumberOfGuests <= 0 {
+		return nil, models.InvalidField("numberOfGuests", "number of guests must be positive")
+	}
+	if request.NumberOfGuests > roomType.MaxGuests {
+		return nil, models.Invalid("room type sleeps at most %d guests", roomType.MaxGuests)
+	}
+
+	var ratePlans []models.RatePlan
+	if request.RatePlanID != "" {
+		ratePlan, err := s.getRatePlan(hotelID, request.RatePlanID)
+		if err != nil {
+			return nil, err
+		}
+		ratePlans = []models.RatePlan{*ratePlan}
+	} else {
+		ratePlans, err = s.ratePlanRepo.GetRatePlansByHotelID(hotelID)
+		if err != nil {
+			return nil, err
+		}
+		if len(ratePlans) == 0 {
+			return nil, models.Conflict("no_rate_available", "hotel has no rate plans")
+		}
+	}
+
+	quotes, reason, err := quoteRatePlans(s.ratePlanRepo, ratePlans, request, nights, s.tenant.Markup)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, models.Conflict("no_rate_available", "%s", reason)
+	}
+	return quotes, nil
+}
+
+// parseStay parses a check-in/check-out pair and lists the nights of the stay
+func parseStay(checkInDate, checkOutDate string) ([]string, error) {
+	checkIn, err := time.Parse(models.StayDateLayout, checkInDate)
+	if err != nil {
+		return nil, models.InvalidField("checkInDate", "check-in date must be formatted as YYYY-MM-DD")
+	}
+	checkOut, err := time.Parse(models.StayDateLayout, checkOutDate)
+	if err != nil {
+		return nil, models.InvalidField("checkOutDate", "check-out date must be formatted as YYYY-MM-DD")
+	}
+	if !checkOut.After(checkIn) {
+		return nil, models.InvalidField("checkOutDate", "check-out date must be after check-in date")
+	}
+	nights := stayNights(checkIn, checkOut)
+	if len(nights) > maxStayNights {
+		return nil, models.Invalid("a stay cannot be longer than %d nights", maxStayNights)
+	}
+	return nights, nil
+}
+
+// quoteRatePlans prices a stay under each of the given rate plans, cheapest
+// first, adding markup to every quote. Rate plans that cannot sell the stay
+// are left out; reason explains why the first of them could not.
+func quoteRatePlans(ratePlanRepo db.RatePlanRepository, ratePlans []models.RatePlan, request models.StayQuoteRequest, nights []string, markup models.Markup) (quotes []models.StayQuote, reason string, err error) {
+	for _, ratePlan := range ratePlans {
+		quote, unsellable, err := quoteRatePlan(ratePlanRepo, ratePlan, request, nights)
+		if err != nil {
+			return nil, "", err
+		}
+		if unsellable != "" {
+			if reason == "" {
+				reason = unsellable
+			}
+			continue
+		}
+		quote.Markup = markup.On(quote.Total)
+		quote.Total += quote.Markup
+		quotes = append(quotes, *quote)
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Total < quotes[j].Total
+	})
+	return quotes, reason, nil
+}
+
+// quoteRatePlan prices a stay under one rate plan, or returns the reason the
+// rate plan cannot sell it. Minimum and maximum stay and closed-to-arrival are
+// taken from the arrival night's rate; closed-to-departure from the rate of
+// the check-out date, if it has one.
+func quoteRatePlan(ratePlanRepo db.RatePlanRepository, ratePlan models.RatePlan, request models.StayQuoteRequest, nights []string) (*models.StayQuote, string, error) {
+	dates := append(nights[:len(nights):len(nights)], request.CheckOutDate)
+	stored, err := ratePlanRepo.GetRoomRates(ratePlan.RatePlanID, request.RoomTypeID, dates)
+	if err != nil {
+		return nil, "", err
+	}
+	byDate := make(map[string]models.RoomRate, len(stored))
+	for _, rate := range stored {
+		byDate[rate.Date] = rate
+	}
+
+	arrival, ok := byDate[request.CheckInDate]
+	if !ok {
+		return nil, fmt.Sprintf("rate plan %s has no rate on %s", ratePlan.Code, request.CheckInDate), nil
+	}
+	if arrival.ClosedToArrival {
+		return nil, fmt.Sprintf("rate plan %s is closed to arrival on %s", ratePlan.Code, request.CheckInDate), nil
+	}
+	if arrival.MinStay > 0 && len(nights) < arrival.MinStay {
+		return nil, fmt.Sprintf("rate plan %s requires a stay of at least %d nights from %s", ratePlan.Code, arrival.MinStay, request.CheckInDate), nil
+	}
+	if arrival.MaxStay > 0 && len(nights) > arrival.MaxStay {
+		return nil, fmt.Sprintf("rate plan %s allows a stay of at most %d nights from %s", ratePlan.Code, arrival.MaxStay, request.CheckInDate), nil
+	}
+	if departure, ok := byDate[request.CheckOutDate]; ok && departure.ClosedToDeparture {
+		return nil, fmt.Sprintf("rate plan %s is closed to departure on %s", ratePlan.Code, request.CheckOutDate), nil
+	}
+
+	quote := &models.StayQuote{
+		HotelID:           ratePlan.HotelID,
+		RoomTypeID:        request.RoomTypeID,
+		RatePlanID:        ratePlan.RatePlanID,
+		RatePlanCode:      ratePlan.Code,
+		Currency:          ratePlan.Currency,
+		CheckInDate:       request.CheckInDate,
+		CheckOutDate:      request.CheckOutDate,
+		NumberOfGuests:    request.NumberOfGuests,
+		Refundable:        ratePlan.Refundable,
+		BreakfastIncluded: ratePlan.BreakfastIncluded,
+		Nights:            make([]models.NightPrice, 0, len(nights)),
+	}
+	for _, date := range nights {
+		rate, ok := byDate[date]
+		if !ok {
+			return nil, fmt.Sprintf("rate plan %s has no rate on %s", ratePlan.Code, date), nil
+		}
+		night := models.NightPrice{Date: date, RoomAmount: rate.Amount}
+		if extra := request.NumberOfGuests - rate.IncludedGuests; extra > 0 {
+			night.ExtraGuests = extra
+			night.ExtraAmount = int64(extra) * rate.ExtraGuestAmount
+		}
+		night.Total = night.RoomAmount + night.ExtraAmount
+		quote.Nights = append(quote.Nights, night)
+		quote.Total += night.Total
+	}
+	return quote, "", nil
+}
+
+func (s *RatePlanServiceImpl) getRatePlan(hotelID, ratePlanID string) (*models.RatePlan, error) {
+	if hotelID == "" || ratePlanID == "" {
+		return nil, models.Invalid("hotel ID and rate plan ID are required")
+	}
+	ratePlan, err := s.ratePlanRepo.GetRatePlanByID(ratePlanID)
+	if err != nil {
+		return nil, err
+	}
+	if ratePlan == nil || ratePlan.HotelID != hotelID {
+		return nil, models.NotFound("rate plan")
+	}
+	return ratePlan, nil
+}
+
+// checkCodeUnique rejects a code already used by another rate plan of the hotel
+func (s *RatePlanServiceImpl) checkCodeUnique(hotelID, ratePlanID, code string) error {
+	ratePlans, err := s.ratePlanRepo.GetRatePlansByHotelID(hotelID)
+	if err != nil {
+		return err
+	}
+	for _, ratePlan := range ratePlans {
+		if ratePlan.Code == code && ratePlan.RatePlanID != ratePlanID {
+			return models.Conflict("rate_plan_code_taken", "rate plan code %q is already in use", code)
+		}
+	}
+	return nil
+}
+
+func checkRatePlan(ratePlan *models.RatePlan) error {
+	ratePlan.Code = strings.ToUpper(strings.TrimSpace(ratePlan.Code))
+	ratePlan.Name = strings.TrimSpace(ratePlan.Name)
+	ratePlan.Currency = strings.ToUpper(ratePlan.Currency)
+	if ratePlan.Code == "" || ratePlan.Name == "" {
+		return models.Invalid("rate plan code and name are required")
+	}
+	if len(ratePlan.Currency) != 3 {
+		return models.InvalidField("currency", "currency must be a three-letter ISO 4217 code")
+	}
+	return nil
+}
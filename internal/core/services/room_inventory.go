@@ -0,0 +1,100 @@
+package services
+
+import (
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+const (
+	// maxStayNights caps a single reservation, which keeps every stay within one
+	// inventory transaction
+	maxStayNights = 30
+	// maxCalendarNights caps the range of an availability request
+	maxCalendarNights = 366
+	// maxAllotmentNights caps the range of an allotment request, which keeps
+	// every allotment within one inventory transaction
+	maxAllotmentNights = 100
+	// DefaultFlexibleCheckinDays is how far either side of the requested
+	// check-in alternatives are searched for flexible guests
+	DefaultFlexibleCheckinDays = 3
+)
+
+// stayNights lists the nights from checkIn up to but excluding checkOut
+func stayNights(checkIn, checkOut time.Time) []string {
+	var dates []string
+	for day := stayDate(checkIn); day.Before(stayDate(checkOut)); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, day.Format(models.StayDateLayout))
+	}
+	return dates
+}
+
+// parseNightRange parses a from/to pair of dates, to being exclusive
+func parseNightRange(from, to string) ([]string, error) {
+	fromDate, err := time.Parse(models.StayDateLayout, from)
+	if err != nil {
+		return nil, models.InvalidField("from", "from must be formatted as YYYY-MM-DD")
+	}
+	toDate, err := time.Parse(models.StayDateLayout, to)
+	if err != nil {
+		return nil, models.InvalidField("to", "to must be formatted as YYYY-MM-DD")
+	}
+	if !toDate.After(fromDate) {
+		return nil, models.InvalidField("to", "to must be after from")
+	}
+	dates := stayNights(fromDate, toDate)
+	if len(dates) > maxCalendarNights {
+		return nil, models.Invalid("at most %d nights can be requested at once", maxCalendarNights)
+	}
+	return dates, nil
+}
+
+// roomCalendar returns the inventory of a room type on each of the given
+// nights, filling nights that were never sold from the room type's TotalRooms
+func roomCalendar(inventoryRepo db.RoomInventoryRepository, roomType models.RoomType, dates []string) ([]models.RoomNightSummary, error) {
+	stored, err := inventoryRepo.GetRoomNights(roomType.HotelID, roomType.RoomTypeID, dates)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]models.RoomNight, len(stored))
+	for _, night := range stored {
+		byDate[night.Date] = night
+	}
+
+	calendar := make([]models.RoomNightSummary, 0, len(dates))
+	for _, date := range dates {
+		night, ok := byDate[date]
+		if !ok {
+			night = models.RoomNight{Date: date, Total: roomType.TotalRooms}
+		}
+		calendar = append(calendar, models.RoomNightSummary{
+			Date:      date,
+			Total:     night.Total,
+			Sold:      night.Sold,
+			Held:      night.Held,
+			Available: night.Available(),
+		})
+	}
+	return calendar, nil
+}
+
+// roomsAvailable reports whether at least one room is free on every night
+func roomsAvailable(calendar []models.RoomNightSummary) bool {
+	for _, night := range calendar {
+		if night.Available < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// roomsFree returns the number of rooms free on every night of the calendar
+func roomsFree(calendar []models.RoomNightSummary) int {
+	free := 0
+	for i, night := range calendar {
+		if i == 0 || night.Available < free {
+			free = night.Available
+		}
+	}
+	return free
+}
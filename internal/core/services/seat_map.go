@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"travel-backend/internal/core/domain/models"
+)
+
+// cabinLayout describes a contiguous block of rows sharing a class and seat letters
+type cabinLayout struct {
+	class    string
+	firstRow int
+	lastRow  int
+	letters  string
+}
+
+// aircraftLayouts maps a normalised aircraft type to its cabin configuration
+var aircraftLayouts = map[string][]cabinLayout{
+	"A320": {
+		{class: models.CabinBusiness, firstRow: 1, lastRow: 3, letters: "ACDF"},
+		{class: models.CabinEconomy, firstRow: 4, lastRow: 30, letters: "ABCDEF"},
+	},
+	"A321": {
+		{class: models.CabinBusiness, firstRow: 1, lastRow: 4, letters: "ACDF"},
+		{class: models.CabinEconomy, firstRow: 5, lastRow: 36, letters: "ABCDEF"},
+	},
+	"B737": {
+		{class: models.CabinBusiness, firstRow: 1, lastRow: 4, letters: "ACDF"},
+		{class: models.CabinEconomy, firstRow: 5, lastRow: 32, letters: "ABCDEF"},
+	},
+	"B787": {
+		{class: models.CabinBusiness, firstRow: 1, lastRow: 6, letters: "ADGK"},
+		{class: models.CabinPremiumEconomy, firstRow: 10, lastRow: 14, letters: "ACDEGHK"},
+		{class: models.CabinEconomy, firstRow: 20, lastRow: 45, letters: "ABCDEFGHK"},
+	},
+	"B777": {
+		{class: models.CabinFirst, firstRow: 1, lastRow: 2, letters: "ADGK"},
+		{class: models.CabinBusiness, firstRow: 5, lastRow: 10, letters: "ACDGHK"},
+		{class: models.CabinPremiumEconomy, firstRow: 12, lastRow: 16, letters: "ABCDEGHJK"},
+		{class: models.CabinEconomy, firstRow: 20, lastRow: 50, letters: "ABCDEFGHJK"},
+	},
+	"A350": {
+		{class: models.CabinBusiness, firstRow: 1, lastRow: 8, letters: "ADGK"},
+		{class: models.CabinPremiumEconomy, firstRow: 10, lastRow: 13, letters: "ACDEGHK"},
+		{class: models.CabinEconomy, firstRow: 15, lastRow: 45, letters: "ABCDEFGHK"},
+	},
+}
+
+// aircraftAliases maps common variant designators onto a known layout
+var aircraftAliases = map[string]string{
+	"A20N": "A320",
+	"A21N": "A321",
+	"B738": "B737",
+	"B38M": "B737",
+	"B789": "B787",
+	"B788": "B787",
+	"B77W": "B777",
+	"A359": "A350",
+}
+
+// GenerateSeatMap builds the full set of seats for a flight from its aircraft type.
+// All generated seats start out available.
+func GenerateSeatMap(flight *models.Flight) ([]models.Seat, error) {
+	if flight == nil {
+		return nil, models.Invalid("flight details cannot be nil")
+	}
+
+	aircraftType := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(flight.AircraftType))
+	if alias, ok := aircraftAliases[aircraftType]; ok {
+		aircraftType = alias
+	}
+	layout, ok := aircraftLayouts[aircraftType]
+	if !ok {
+		return nil, models.InvalidField("aircraftType", "unsupported aircraft type %q", flight.AircraftType)
+	}
+
+	var seats []models.Seat
+	for _, cabin := range layout {
+		for row := cabin.firstRow; row <= cabin.lastRow; row++ {
+			for _, letter := range cabin.letters {
+				seatNumber := fmt.Sprintf("%d%c", row, letter)
+				seats = append(seats, models.Seat{
+					SeatID:      SeatID(flight.FlightID, seatNumber),
+					FlightID:    flight.FlightID,
+					SeatNumber:  seatNumber,
+					Class:       cabin.class,
+					IsAvailable: true,
+				})
+			}
+		}
+	}
+
+	return seats, nil
+}
+
+// SeatID derives the storage key of a seat from its flight and seat number
+func SeatID(flightID, seatNumber string) string {
+	return flightID + "-" + seatNumber
+}
+
+// sortSeats orders seats the way they appear in the cabin: by row, then by letter
+func sortSeats(seats []models.Seat) {
+	sort.Slice(seats, func(i, j int) bool {
+		rowI, letterI := splitSeatNumber(seats[i].SeatNumber)
+		rowJ, letterJ := splitSeatNumber(seats[j].SeatNumber)
+		if rowI != rowJ {
+			return rowI < rowJ
+		}
+		return letterI < letterJ
+	})
+}
+
+// splitSeatNumber splits a seat number such as "12C" into its row and letter
+func splitSeatNumber(seatNumber string) (int, string) {
+	i := 0
+	for i < len(seatNumber) && seatNumber[i] >= '0' && seatNumber[i] <= '9' {
+		i++
+	}
+	row, _ := strconv.Atoi(seatNumber[:i])
+	return row, seatNumber[i:]
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"time"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/internal/ports/db"
+)
+
+// DefaultSeatHoldDuration is used when no hold window is configured
+const DefaultSeatHoldDuration = 15 * time.Minute
+
+type SeatServiceImpl struct {
+	seatRepo      db.SeatRepository
+	bookingRepo   db.BookingRepository
+	passengerRepo db.PassengerRepository
+	holdDuration  time.Duration
+}
+
+// NewSeatService creates a new instance of SeatServiceImpl
+func NewSeatService(seatRepo db.SeatRepository, bookingRepo db.BookingRepository, passengerRepo db.PassengerRepository, holdDuration time.Duration) *SeatServiceImpl {
+	if holdDuration <= 0 {
+		holdDuration = DefaultSeatHoldDuration
+	}
+	return &SeatServiceImpl{
+		seatRepo:      seatRepo,
+		bookingRepo:   bookingRepo,
+		passengerRepo: passengerRepo,
+		holdDuration:  holdDuration,
+	}
+}
+
+// HoldSeat blocks a seat for a booking during checkout. The hold lapses on its
+// own once the hold window has passed. Only the booking's owner and staff may
+// hold seats for it.
+func (s *SeatServiceImpl) HoldSeat(ctx context.Context, flightID, seatNumber, bookingID string) (*models.SeatHold, error) {
+	seat, err := s.checkSeatForBooking(ctx, flightID, seatNumber, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := &models.SeatHold{
+		SeatID:    seat.SeatID,
+		FlightID:  flightID,
+		BookingID: bookingID,
+		ExpiresAt: time.Now().Add(s.holdDuration).UTC(),
+	}
+	if err := s.seatRepo.HoldSeat(hold); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// ReleaseSeat gives up a booking's hold on a seat, on behalf of the booking's
+// owner or staff
+func (s *SeatServiceImpl) ReleaseSeat(ctx context.Context, flightID, seatNumber, bookingID string) error {
+	if flightID == "" || seatNumber == "" || bookingID == "" {
+		return models.Invalid("flight ID, seat number and booking ID are required")
+	}
+	if _, err := s.getOwnedBooking(ctx, bookingID); err != nil {
+		return err
+	}
+	return s.seatRepo.ReleaseSeatHold(SeatID(flightID, seatNumber), bookingID)
+}
+
+// ConfirmSeat converts a booking's hold on a seat into an assignment for one of
+// the booking's passengers, on behalf of the booking's owner or staff
+func (s *SeatServiceImpl) ConfirmSeat(ctx context.Context, flightID, seatNumber, bookingID, passengerID string) (*models.Seat, error) {
+	if passengerID == "" {
+		return nil, models.InvalidField("passengerID", "passenger ID is required")
+	}
+	seat, err := s.checkSeatForBooking(ctx, flightID, seatNumber, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	passenger, err := s.passengerRepo.GetPassengerByID(passengerID)
+	if err != nil {
+		return nil, err
+	}
+	if passenger == nil || passenger.BookingID != bookingID {
+		return nil, models.NotFound("passenger")
+	}
+
+	if err := s.seatRepo.ConfirmSeatHold(seat.SeatID, passengerID, bookingID); err != nil {
+		return nil, err
+	}
+
+	seat.IsAvailable = false
+	seat.PassengerID = passengerID
+	seat.BookingID = bookingID
+	return seat, nil
+}
+
+// checkSeatForBooking loads the seat and makes sure the booking may use it and
+// the caller in ctx may act for the booking
+func (s *SeatServiceImpl) checkSeatForBooking(ctx context.Context, flightID, seatNumber, bookingID string) (*models.Seat, error) {
+	if flightID == "" || seatNumber == "" || bookingID == "" {
+		return nil, models.Invalid("flight ID, seat number and booking ID are required")
+	}
+
+	booking, err := s.getOwnedBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !booking.HasFlight(flightID) {
+		return nil, models.Invalid("booking is for a different flight")
+	}
+
+	seat, err := s.seatRepo.GetSeatByID(SeatID(flightID, seatNumber))
+	if err != nil {
+		return nil, err
+	}
+	if seat == nil {
+		return nil, models.NotFound("seat")
+	}
+
+	return seat, nil
+}
+
+// getOwnedBooking loads a booking, provided the caller in ctx owns it or is staff
+func (s *SeatServiceImpl) getOwnedBooking(ctx context.Context, bookingID string) (*models.Booking, error) {
+	principal, err := models.RequirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	booking, err := s.bookingRepo.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking == nil {
+		return nil, models.NotFound("booking")
+	}
+	if booking.UserID != principal.UserID && !principal.CanActForAnyUser() {
+		return nil, models.Forbidden(models.CodeBookingAccessDenied, "booking belongs to another user")
+	}
+	return booking, nil
+}
@@ -1,13 +1,17 @@
 package api
 
-import "travel-backend/internal/core/domain/models"
+import (
+	"context"
+	"travel-backend/internal/core/domain/models"
+)
 
 type BookingService interface {
-	GetAllBookings() ([]models.Booking, error)
-	GetBookingByID(id string) (*models.Booking, error)
-	CreateBooking(booking *models.Booking) error
-	UpdateBookingStatus(id string, status string) error
-	GetBookingsByUserID(userID string) ([]models.Booking, error)
-	DeleteBooking(id string) error
-	UpdateBooking(id string, booking *models.Booking) (*models.Booking, error)
+	ListBookings(ctx context.Context, query models.BookingQuery) (*models.BookingPage, error)
+	GetBookingByID(ctx context.Context, id string) (*models.Booking, error)
+	GetBookingByLocator(locator, lastName string) (*models.Booking, error)
+	CreateBooking(ctx context.Context, booking *models.Booking) error
+	UpdateBookingStatus(ctx context.Context, id string, status models.BookingStatus) (*models.Booking, error)
+	DeleteBooking(ctx context.Context, id string, version int64) error
+	UpdateBooking(ctx context.Context, id string, booking *models.Booking) (*models.Booking, error)
+	PatchBooking(ctx context.Context, id string, patch []byte, version int64) (*models.Booking, error)
 }
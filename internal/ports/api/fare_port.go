@@ -0,0 +1,9 @@
+package api
+
+import "travel-backend/internal/core/domain/models"
+
+type FareService interface {
+	GetFlightFares(flightID string) ([]models.Fare, error)
+	CreateFare(flightID string, fare *models.Fare) error
+	QuoteFare(flightID string, request models.FareQuote) (*models.FareQuote, error)
+}
@@ -3,11 +3,14 @@ package api
 import "travel-backend/internal/core/domain/models"
 
 type FlightService interface {
-	GetAllFlights() ([]models.Flight, error)
+	ListFlights(query models.FlightQuery) (*models.FlightPage, error)
 	GetFlightByID(id string) (*models.Flight, error)
+	SearchFlights(search models.FlightSearch) ([]models.FlightSearchResult, error)
 	CreateFlight(flight *models.Flight) error
 	GetFlightBookings(flightID string) ([]models.Booking, error)
-	// GetAvailableSeats(flightID string) ([]models.Seat, error)
+	GetAvailableSeats(flightID string) ([]models.Seat, error)
+	GetFlightSeats(flightID string, filter models.SeatFilter) ([]models.Seat, error)
 	UpdateFlight(id string, flight *models.Flight) (*models.Flight, error)
-	DeleteFlight(id string) error
+	PatchFlight(id string, patch []byte, version int64) (*models.Flight, error)
+	DeleteFlight(id string, version int64) error
 }
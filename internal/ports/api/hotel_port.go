@@ -3,10 +3,26 @@ package api
 import "travel-backend/internal/core/domain/models"
 
 type HotelService interface {
-	GetAllHotels() ([]models.Hotel, error)
-	GetHotelByID(id string) (*models.Hotel, error)
-	CreateHotel(hotel *models.Hotel) error
-	GetHotelBookings(hotelID string) ([]models.Booking, error)
-	UpdateHotel(id string, hotel *models.Hotel) (*models.Hotel, error)
-	DeleteHotel(id string) error
+	ListHotels(query models.HotelQuery) (*models.HotelPage, error)
+	GetHotelByID(id string) (*models.Property, error)
+	SearchHotels(search models.HotelSearch) ([]models.HotelSearchResult, error)
+	GetHotelsNear(center models.GeoPoint, radiusKm float64) ([]models.NearbyHotel, error)
+	CreateHotel(hotel *models.Property) error
+	UpdateHotel(id string, hotel *models.Property) (*models.Property, error)
+	PatchHotel(id string, patch []byte, version int64) (*models.Property, error)
+	DeleteHotel(id string, version int64) error
+	GetHotelRooms(hotelID string) ([]models.RoomType, error)
+	GetHotelRoom(hotelID, roomTypeID string) (*models.RoomType, error)
+	AddHotelRoom(hotelID string, roomType *models.RoomType) error
+	UpdateHotelRoom(hotelID, roomTypeID string, roomType *models.RoomType) (*models.RoomType, error)
+	RemoveHotelRoom(hotelID, roomTypeID string) error
+	GetHotelAvailability(hotelID, roomTypeID, from, to string) ([]models.RoomAvailability, error)
+	SetRoomAllotment(hotelID, roomTypeID string, allotment models.RoomAllotment) error
+}
+
+type HotelReservationService interface {
+	GetHotelReservations(hotelID string) ([]models.HotelReservation, error)
+	GetHotelReservation(hotelID, reservationID string) (*models.HotelReservation, error)
+	CreateHotelReservation(hotelID string, reservation *models.HotelReservation) error
+	CancelHotelReservation(hotelID, reservationID string) (*models.HotelReservation, error)
 }
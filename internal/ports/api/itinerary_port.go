@@ -0,0 +1,7 @@
+package api
+
+import "travel-backend/internal/core/domain/models"
+
+type ItineraryService interface {
+	SearchItineraries(search models.ItinerarySearch) ([]models.Itinerary, error)
+}
@@ -0,0 +1,9 @@
+package api
+
+import "travel-backend/internal/core/domain/models"
+
+type MealService interface {
+	GetMeals(class string) ([]models.Meal, error)
+	GetMealByID(id string) (*models.Meal, error)
+	AddMeal(meal *models.Meal) error
+}
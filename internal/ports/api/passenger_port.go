@@ -4,9 +4,12 @@ import "travel-backend/internal/core/domain/models"
 
 type PassengerService interface {
 	GetAllPassengersByBookingID(bookingID string) ([]models.Passenger, error)
+	GetPassengerByID(bookingID, passengerID string) (*models.Passenger, error)
 	AddPassenger(passenger *models.Passenger) error
+	UpdatePassenger(bookingID, passengerID string, passenger *models.Passenger) (*models.Passenger, error)
+	RemovePassenger(bookingID, passengerID string) error
 	AssignSeat(passengerSeat *models.Seat) error
 	GetPassengerSeats(bookingID string) ([]models.Seat, error)
-	AssignMeal(passengerMeal *models.Meal) error
-	GetPassengerMeals(bookingID string) ([]models.Meal, error)
+	AssignMeal(passengerMeal *models.MealSelection) error
+	GetPassengerMeals(bookingID string) ([]models.MealSelection, error)
 }
@@ -0,0 +1,14 @@
+package api
+
+import "travel-backend/internal/core/domain/models"
+
+type RatePlanService interface {
+	GetRatePlans(hotelID string) ([]models.RatePlan, error)
+	GetRatePlan(hotelID, ratePlanID string) (*models.RatePlan, error)
+	CreateRatePlan(hotelID string, ratePlan *models.RatePlan) error
+	UpdateRatePlan(hotelID, ratePlanID string, ratePlan *models.RatePlan) (*models.RatePlan, error)
+	DeleteRatePlan(hotelID, ratePlanID string) error
+	GetRoomRates(hotelID, ratePlanID, roomTypeID, from, to string) ([]models.RoomRate, error)
+	SetRoomRates(hotelID, ratePlanID string, update models.RoomRateUpdate) error
+	QuoteStay(hotelID string, request models.StayQuoteRequest) ([]models.StayQuote, error)
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"context"
+	"travel-backend/internal/core/domain/models"
+)
+
+type SeatService interface {
+	HoldSeat(ctx context.Context, flightID, seatNumber, bookingID string) (*models.SeatHold, error)
+	ReleaseSeat(ctx context.Context, flightID, seatNumber, bookingID string) error
+	ConfirmSeat(ctx context.Context, flightID, seatNumber, bookingID, passengerID string) (*models.Seat, error)
+}
@@ -6,58 +6,147 @@ import (
 )
 
 type HotelRepository interface {
-	GetAllHotels() ([]models.Hotel, error)
-	GetHotelByID(id string) (*models.Hotel, error)
-	CreateHotel(hotel *models.Hotel) error
-	GetHotelBookings(hotelID string) ([]models.Booking, error)
-	UpdateHotel(id string, hotel *models.Hotel) (*models.Hotel, error)
-	DeleteHotel(id string) error
+	// ListHotels returns one page of the hotels matching query
+	ListHotels(query models.HotelQuery) (*models.HotelPage, error)
+	GetHotelByID(id string) (*models.Property, error)
+	// GetHotelsByCity returns the hotels in a city, matching the name case-insensitively
+	GetHotelsByCity(city string) ([]models.Property, error)
+	// GetHotelsNear returns the hotels within radiusKm of a point, nearest first
+	GetHotelsNear(center models.GeoPoint, radiusKm float64) ([]models.NearbyHotel, error)
+	CreateHotel(hotel *models.Property) error
+	UpdateHotel(id string, hotel *models.Property) (*models.Property, error)
+	// PatchHotel writes only the named top-level fields of hotel, provided the
+	// stored hotel is still at hotel.Version, and bumps the version
+	PatchHotel(id string, hotel *models.Property, fields []string) (*models.Property, error)
+	DeleteHotel(id string, version int64) error
+}
+
+type RoomTypeRepository interface {
+	GetRoomTypesByHotelID(hotelID string) ([]models.RoomType, error)
+	GetRoomTypeByID(roomTypeID string) (*models.RoomType, error)
+	CreateRoomType(roomType *models.RoomType) error
+	UpdateRoomType(roomTypeID string, roomType *models.RoomType) (*models.RoomType, error)
+	DeleteRoomType(roomTypeID string) error
+}
+
+type HotelReservationRepository interface {
+	GetReservationsByHotelID(hotelID string) ([]models.HotelReservation, error)
+	GetReservationsByBookingID(bookingID string) ([]models.HotelReservation, error)
+	GetReservationByID(reservationID string) (*models.HotelReservation, error)
+	CreateReservation(reservation *models.HotelReservation) error
+	UpdateReservation(reservationID string, reservation *models.HotelReservation) (*models.HotelReservation, error)
 }
 
 type FlightRepository interface {
-	GetAllFlights() ([]models.Flight, error)
+	// ListFlights returns one page of the flights matching query
+	ListFlights(query models.FlightQuery) (*models.FlightPage, error)
 	GetFlightByID(id string) (*models.Flight, error)
 	CreateFlight(flight *models.Flight) error
 	GetFlightBookings(flightID string) ([]models.Booking, error)
-	// GetAvailableSeats(flightID string) ([]models.Seat, error)
+	SearchFlights(origin, destination, date string) ([]models.Flight, error)
+	GetFlightsByOrigin(origin, date string) ([]models.Flight, error)
 	UpdateFlight(id string, flight *models.Flight) (*models.Flight, error)
-	DeleteFlight(id string) error
+	// PatchFlight writes only the named top-level fields of flight, provided the
+	// stored flight is still at flight.Version, and bumps the version
+	PatchFlight(id string, flight *models.Flight, fields []string) (*models.Flight, error)
+	DeleteFlight(id string, version int64) error
 }
 
 type BookingRepository interface {
-	GetAllBookings() ([]models.Booking, error)
+	// ListBookings returns one page of the bookings matching query
+	ListBookings(query models.BookingQuery) (*models.BookingPage, error)
 	GetBookingByID(id string) (*models.Booking, error)
+	GetBookingByLocator(locator string) (*models.Booking, error)
 	CreateBooking(booking *models.Booking) error
-	UpdateBookingStatus(id string, status string) error
-	GetBookingsByUserID(userID string) ([]models.Booking, error)
+	UpdateBookingStatus(id string, change models.BookingStatusChange) error
+	// UpdateBookingSegments replaces the segments of a booking, provided it is
+	// still at version, and bumps the version
+	UpdateBookingSegments(id string, segments []models.BookingSegment, version int64) error
 	UpdateBooking(id string, booking *models.Booking) (*models.Booking, error)
-	DeleteBooking(id string) error
+	// PatchBooking writes only the named top-level fields of booking, provided the
+	// stored booking is still at booking.Version, and bumps the version
+	PatchBooking(id string, booking *models.Booking, fields []string) (*models.Booking, error)
+	DeleteBooking(id string, version int64) error
 }
 
 type PassengerRepository interface {
 	GetAllPassengersByBookingID(bookingID string) ([]models.Passenger, error)
+	GetPassengerByID(passengerID string) (*models.Passenger, error)
 	AddPassenger(passenger *models.Passenger) error
+	UpdatePassenger(passengerID string, passenger *models.Passenger) (*models.Passenger, error)
+	DeletePassenger(passengerID string) error
 	AssignSeat(passengerSeat *models.Seat) error
 	GetPassengerSeats(bookingID string) ([]models.Seat, error)
-	AssignMeal(passengerMeal *models.Meal) error
-	GetPassengerMeals(bookingID string) ([]models.Meal, error)
+	AssignMeal(passengerMeal *models.MealSelection) error
+	GetPassengerMeals(bookingID string) ([]models.MealSelection, error)
 }
 
 type SeatRepository interface {
+	CreateSeats(seats []models.Seat) error
 	GetSeatByID(seatID string) (*models.Seat, error)
+	GetSeatsByFlightID(flightID string) ([]models.Seat, error)
 	UpdateSeatAvailability(seatID string, isAvailable bool) error
 	GetAvailableSeatsByFlightID(flightID string) ([]models.Seat, error)
 	AssignSeatToPassenger(seatID, passengerID, bookingID string) error
+	DeleteSeatsByFlightID(flightID string) error
+	HoldSeat(hold *models.SeatHold) error
+	ReleaseSeatHold(seatID, bookingID string) error
+	ConfirmSeatHold(seatID, passengerID, bookingID string) error
+	// GetFlightInventories returns the seat counters of several flights in one
+	// read, by flightID, leaving out flights that have none
+	GetFlightInventories(flightIDs []string) (map[string]models.FlightInventory, error)
+	// ReserveSeats counts count more of a flight's seats as sold, failing with
+	// db.ErrSeatsSoldOut if more than capacity would then be sold
+	ReserveSeats(flightID string, count, capacity int) error
+	// ReleaseSeats gives back count sold seats of a flight
+	ReleaseSeats(flightID string, count int) error
 }
 
 type MealRepository interface {
 	GetAllMeals() ([]models.Meal, error)
+	GetMealByID(mealID string) (*models.Meal, error)
 	GetMealsByClass(class string) ([]models.Meal, error)
 	AddMeal(meal *models.Meal) error
-	GetPassengerMeals(bookingID string) ([]models.Meal, error)
-	AssignMealToPassenger(passengerMeal *models.Meal) error
+	GetPassengerMeals(bookingID string) ([]models.MealSelection, error)
+	AssignMealToPassenger(passengerMeal *models.MealSelection) error
+}
+
+// RoomInventoryRepository stores per-night room counts. Nights without a
+// stored row have not been sold yet and default to the room type's TotalRooms.
+type RoomInventoryRepository interface {
+	GetRoomNights(hotelID, roomTypeID string, dates []string) ([]models.RoomNight, error)
+	SetAllotment(hotelID, roomTypeID string, dates []string, total int) error
+	ReserveRoomNights(hotelID, roomTypeID string, dates []string, defaultTotal int) error
+	ReleaseRoomNights(hotelID, roomTypeID string, dates []string) error
+}
+
+type RatePlanRepository interface {
+	GetRatePlansByHotelID(hotelID string) ([]models.RatePlan, error)
+	GetRatePlanByID(ratePlanID string) (*models.RatePlan, error)
+	CreateRatePlan(ratePlan *models.RatePlan) error
+	UpdateRatePlan(ratePlanID string, ratePlan *models.RatePlan) (*models.RatePlan, error)
+	DeleteRatePlan(ratePlanID string) error
+	GetRoomRates(ratePlanID, roomTypeID string, dates []string) ([]models.RoomRate, error)
+	SetRoomRates(rates []models.RoomRate) error
+}
+
+type FareRepository interface {
+	GetFaresByFlightID(flightID string) ([]models.Fare, error)
+	GetFareByID(fareID string) (*models.Fare, error)
+	CreateFare(fare *models.Fare) error
 }
 
 type ReportRepository interface {
 	GetBookingReport(startDate, endDate time.Time) ([]models.Booking, error)
 }
+
+// IdempotencyRepository remembers requests made with an idempotency key.
+// Records past their ExpiresAt are treated as if they did not exist, and
+// CreateIdempotencyRecord takes over the records models.IdempotencyRecord's
+// CanTakeOver allows.
+type IdempotencyRepository interface {
+	GetIdempotencyRecord(key string) (*models.IdempotencyRecord, error)
+	CreateIdempotencyRecord(record *models.IdempotencyRecord) error
+	CompleteIdempotencyRecord(record *models.IdempotencyRecord) error
+	DeleteIdempotencyRecord(key string) error
+}
@@ -0,0 +1,39 @@
+package db
+
+import "travel-backend/internal/core/domain/models"
+
+var (
+	// ErrSeatUnavailable is returned when a seat is already assigned or held by someone else
+	ErrSeatUnavailable error = models.Conflict("seat_unavailable", "seat is not available")
+
+	// ErrSeatsSoldOut is returned when a flight has fewer seats left for sale than a booking needs
+	ErrSeatsSoldOut error = models.Conflict("seats_sold_out", "not enough seats left on the flight")
+
+	// ErrSeatHoldNotFound is returned when a booking has no active hold on a seat
+	ErrSeatHoldNotFound error = models.Conflict("seat_hold_not_found", "no active hold on seat for this booking")
+
+	// ErrBookingStatusChanged is returned when a booking's status moved on between
+	// reading it and writing a transition
+	ErrBookingStatusChanged error = models.Conflict("booking_status_changed", "booking status was changed concurrently")
+
+	// ErrVersionConflict is returned when a write expected a version of an entity
+	// that is no longer the stored one, because someone else changed it first
+	ErrVersionConflict error = models.Conflict("version_conflict", "resource was modified by another request")
+
+	// ErrLocatorTaken is returned when a new booking's record locator is already in use
+	ErrLocatorTaken error = models.Conflict("locator_taken", "booking locator is already in use")
+
+	// ErrIdempotencyKeyInUse is returned when an unexpired record already exists for an idempotency key
+	ErrIdempotencyKeyInUse error = models.Conflict("idempotency_key_in_use", "idempotency key is already in use")
+
+	// ErrRoomsSoldOut is returned when a room type has no room left on one of the requested nights
+	ErrRoomsSoldOut error = models.Conflict("rooms_sold_out", "no rooms left on one or more nights")
+
+	// ErrAllotmentBelowSold is returned when a room allotment would drop below the
+	// rooms already sold or held
+	ErrAllotmentBelowSold error = models.Conflict("allotment_below_sold", "allotment cannot be lower than rooms already sold or held")
+
+	// ErrRoomInventoryChanged is returned when rooms were sold or released on a
+	// night while its allotment was being set
+	ErrRoomInventoryChanged error = models.Conflict("room_inventory_changed", "room inventory changed while setting the allotment, please retry")
+)
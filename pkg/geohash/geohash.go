@@ -0,0 +1,124 @@
+// Package geohash encodes coordinates as geohash strings and finds the cells
+// that cover a circle, so that radius searches can be run as prefix queries.
+package geohash
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+	// MaxPrecision is the length of the hashes stored for a point, about 5 m across
+	MaxPrecision = 9
+	// kmPerDegree is the length of one degree of latitude
+	kmPerDegree = 111.32
+)
+
+// Encode returns the geohash of a point with the given number of characters
+func Encode(latitude, longitude float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	var hash strings.Builder
+	bits, ch, even := 0, 0, true
+	for hash.Len() < precision {
+		// Bits alternate between longitude and latitude, starting with longitude
+		if even {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if longitude >= mid {
+				ch = ch<<1 | 1
+				lngRange[0] = mid
+			} else {
+				ch <<= 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if latitude >= mid {
+				ch = ch<<1 | 1
+				latRange[0] = mid
+			} else {
+				ch <<= 1
+				latRange[1] = mid
+			}
+		}
+		even = !even
+
+		if bits++; bits == 5 {
+			hash.WriteByte(base32[ch])
+			bits, ch = 0, 0
+		}
+	}
+	return hash.String()
+}
+
+// CellSize returns the height and width in degrees of a cell of the given precision
+func CellSize(precision int) (latDegrees, lngDegrees float64) {
+	bits := 5 * precision
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lngBits))
+}
+
+// Cover returns the cells of the finest precision, at most maxPrecision, whose
+// 3x3 block around the centre cell contains every point within radiusKm of the
+// centre. The result holds no duplicates and all cells have the same length.
+func Cover(latitude, longitude, radiusKm float64, maxPrecision int) []string {
+	// Cells are narrowest on the side of the circle nearest the pole
+	poleward := math.Min(math.Abs(latitude)+radiusKm/kmPerDegree, 90)
+
+	precision := 1
+	for p := maxPrecision; p >= 1; p-- {
+		latDegrees, lngDegrees := CellSize(p)
+		heightKm := latDegrees * kmPerDegree
+		widthKm := lngDegrees * kmPerDegree * math.Cos(poleward*math.Pi/180)
+		if heightKm >= radiusKm && widthKm >= radiusKm {
+			precision = p
+			break
+		}
+	}
+
+	latDegrees, lngDegrees := CellSize(precision)
+	seen := make(map[string]bool, 9)
+	var cells []string
+	for _, dLat := range []float64{-latDegrees, 0, latDegrees} {
+		for _, dLng := range []float64{-lngDegrees, 0, lngDegrees} {
+			lat := latitude + dLat
+			if lat > 90 || lat < -90 {
+				continue
+			}
+			cell := Encode(lat, wrapLongitude(longitude+dLng), precision)
+			if !seen[cell] {
+				seen[cell] = true
+				cells = append(cells, cell)
+			}
+		}
+	}
+	return cells
+}
+
+// Children returns the cells of the given precision that make up a cell
+func Children(cell string, precision int) []string {
+	cells := []string{cell}
+	for len(cells[0]) < precision {
+		next := make([]string, 0, len(cells)*len(base32))
+		for _, parent := range cells {
+			for i := 0; i < len(base32); i++ {
+				next = append(next, parent+base32[i:i+1])
+			}
+		}
+		cells = next
+	}
+	return cells
+}
+
+func wrapLongitude(longitude float64) float64 {
+	if longitude >= 180 {
+		return longitude - 360
+	}
+	if longitude < -180 {
+		return longitude + 360
+	}
+	return longitude
+}
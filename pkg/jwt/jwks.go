@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minJWKSRefetch bounds how often a JWKS is fetched, so that tokens naming
+// bogus keys or an unreachable issuer do not turn every request into a fetch
+const minJWKSRefetch = 30 * time.Second
+
+// JWKS verifies RS256 tokens with the keys published in a JSON Web Key Set
+// (RFC 7517) at a URL. Keys are cached for the refresh interval and fetched
+// again early when a token names a key the cached set lacks, so the issuer
+// can rotate keys.
+type JWKS struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetched   time.Time
+	attempted time.Time
+}
+
+// NewJWKS returns a key source backed by the key set at url
+func NewJWKS(url string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+func (j *JWKS) Key(alg, kid string) (interface{}, error) {
+	if alg != RS256 {
+		return nil, invalid("no %s key is configured", alg)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key, ok := j.lookup(kid)
+	stale := time.Since(j.fetched) > j.refresh
+	if (stale || !ok) && time.Since(j.attempted) > minJWKSRefetch {
+		if err := j.fetch(); err != nil {
+			if ok {
+				// Keep verifying with the cached key while the issuer is unreachable
+				return key, nil
+			}
+			return nil, err
+		}
+		key, ok = j.lookup(kid)
+	}
+	if !ok {
+		if j.fetched.IsZero() {
+			return nil, fmt.Errorf("jwt: JWKS at %s has not been loaded yet", j.url)
+		}
+		return nil, invalid("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// lookup finds a cached key. Tokens without a key ID can only be verified
+// when the set holds a single key.
+func (j *JWKS) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) fetch() error {
+	j.attempted = time.Now()
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return fmt.Errorf("jwt: fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwt: fetching JWKS: unexpected status %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("jwt: decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.KeyType != "RSA" || jwk.Use != "" && jwk.Use != "sig" || jwk.Algorithm != "" && jwk.Algorithm != RS256 {
+			continue
+		}
+		key, err := rsaPublicKey(jwk)
+		if err != nil {
+			return fmt.Errorf("jwt: JWKS key %q: %w", jwk.KeyID, err)
+		}
+		keys[jwk.KeyID] = key
+	}
+	j.keys = keys
+	j.fetched = time.Now()
+	return nil
+}
+
+func rsaPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("bad modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("bad exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("unsupported exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,179 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keySet serves a JSON Web Key Set that tests can change between requests
+type keySet struct {
+	mu      sync.Mutex
+	keys    []jsonWebKey
+	status  int
+	fetches int
+}
+
+func (s *keySet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"keys": s.keys})
+}
+
+func (s *keySet) set(keys ...jsonWebKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func (s *keySet) fail(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *keySet) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func publicJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		KeyType:   "RSA",
+		KeyID:     kid,
+		Use:       "sig",
+		Algorithm: RS256,
+		N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestJWKSKeyLookup(t *testing.T) {
+	first, second, unpublished := testRSAKey(t), testRSAKey(t), testRSAKey(t)
+	encryption := publicJWK("enc", &unpublished.PublicKey)
+	encryption.Use = "enc"
+	otherAlg := publicJWK("ps", &unpublished.PublicKey)
+	otherAlg.Algorithm = "PS256"
+
+	tests := []struct {
+		name      string
+		published []jsonWebKey
+		alg       string
+		kid       string
+		key       *rsa.PrivateKey
+		valid     bool
+	}{
+		{name: "key named by kid", published: []jsonWebKey{publicJWK("k1", &first.PublicKey), publicJWK("k2", &second.PublicKey)}, alg: RS256, kid: "k2", key: second, valid: true},
+		{name: "kid naming another key", published: []jsonWebKey{publicJWK("k1", &first.PublicKey), publicJWK("k2", &second.PublicKey)}, alg: RS256, kid: "k1", key: second},
+		{name: "unknown kid", published: []jsonWebKey{publicJWK("k1", &first.PublicKey)}, alg: RS256, kid: "k9", key: first},
+		{name: "no kid with a single key", published: []jsonWebKey{publicJWK("k1", &first.PublicKey)}, alg: RS256, key: first, valid: true},
+		{name: "no kid with several keys", published: []jsonWebKey{publicJWK("k1", &first.PublicKey), publicJWK("k2", &second.PublicKey)}, alg: RS256, key: first},
+		{name: "encryption key", published: []jsonWebKey{encryption}, alg: RS256, kid: "enc", key: unpublished},
+		{name: "key for another algorithm", published: []jsonWebKey{otherAlg}, alg: RS256, kid: "ps", key: unpublished},
+		{name: "HS256", published: []jsonWebKey{publicJWK("k1", &first.PublicKey)}, alg: HS256, kid: "k1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			set := &keySet{keys: test.published}
+			server := httptest.NewServer(set)
+			defer server.Close()
+
+			verifier := NewVerifier(NewJWKS(server.URL, time.Hour), "travel", "api", 0)
+			verifier.now = func() time.Time { return testNow }
+
+			var signingKey interface{} = test.key
+			if test.alg == HS256 {
+				signingKey = []byte("secret")
+			}
+			_, err := verifier.Verify(sign(t, testClaims(), test.alg, test.kid, signingKey))
+			if test.valid {
+				if err != nil {
+					t.Fatalf("Verify() error = %v, want none", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify() error = %v, want one wrapping ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	old, rotated := testRSAKey(t), testRSAKey(t)
+	set := &keySet{keys: []jsonWebKey{publicJWK("old", &old.PublicKey)}}
+	server := httptest.NewServer(set)
+	defer server.Close()
+
+	jwks := NewJWKS(server.URL, time.Hour)
+	verifier := NewVerifier(jwks, "travel", "api", 0)
+	verifier.now = func() time.Time { return testNow }
+
+	if _, err := verifier.Verify(sign(t, testClaims(), RS256, "old", old)); err != nil {
+		t.Fatalf("Verify() with the published key error = %v", err)
+	}
+
+	// A key published since the last fetch is not picked up while fetches are throttled
+	set.set(publicJWK("old", &old.PublicKey), publicJWK("new", &rotated.PublicKey))
+	if _, err := verifier.Verify(sign(t, testClaims(), RS256, "new", rotated)); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify() with a key named too soon error = %v, want one wrapping ErrInvalidToken", err)
+	}
+	if fetches := set.fetchCount(); fetches != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", fetches)
+	}
+
+	// Once the throttle has passed, an unknown kid fetches the set again
+	jwks.attempted = jwks.attempted.Add(-2 * minJWKSRefetch)
+	if _, err := verifier.Verify(sign(t, testClaims(), RS256, "new", rotated)); err != nil {
+		t.Fatalf("Verify() with the rotated key error = %v", err)
+	}
+	if fetches := set.fetchCount(); fetches != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", fetches)
+	}
+}
+
+func TestJWKSUnreachable(t *testing.T) {
+	key := testRSAKey(t)
+	set := &keySet{keys: []jsonWebKey{publicJWK("k1", &key.PublicKey)}, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(set)
+	defer server.Close()
+
+	jwks := NewJWKS(server.URL, time.Hour)
+	verifier := NewVerifier(jwks, "travel", "api", 0)
+	verifier.now = func() time.Time { return testNow }
+	token := sign(t, testClaims(), RS256, "k1", key)
+
+	// Failing to load the keys is not the token's fault
+	if _, err := verifier.Verify(token); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify() before the set was loaded error = %v, want a load failure", err)
+	}
+
+	// Cached keys keep verifying while the issuer is unreachable
+	set.fail(0)
+	jwks.attempted = time.Time{}
+	if _, err := verifier.Verify(token); err != nil {
+		t.Fatalf("Verify() error = %v, want none", err)
+	}
+	set.fail(http.StatusServiceUnavailable)
+	jwks.fetched, jwks.attempted = time.Now().Add(-2*time.Hour), time.Time{}
+	if _, err := verifier.Verify(token); err != nil {
+		t.Fatalf("Verify() with a stale cache error = %v, want none", err)
+	}
+	if fetches := set.fetchCount(); fetches != 3 {
+		t.Fatalf("JWKS fetched %d times, want 3", fetches)
+	}
+}
@@ -0,0 +1,244 @@
+// Package jwt signs and verifies JSON Web Tokens in compact form (RFC 7519)
+// using HS256 or RS256. Tokens naming any other algorithm, including "none",
+// are rejected.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+)
+
+// ErrInvalidToken is wrapped by every error about a token itself, as opposed
+// to failures to fetch the keys that verify it
+var ErrInvalidToken = errors.New("invalid token")
+
+// Header is the JOSE header of a token
+type Header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Claims are the claims of a token. Times are seconds since the Unix epoch.
+// Roles is a private claim listing what the subject may do, and Tenant one
+// naming the travel agency the subject belongs to.
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Tenant    string   `json:"tenant,omitempty"`
+}
+
+// Audience is the aud claim, which may be a single string or an array of them
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Contains reports whether audience is one of the token's audiences
+func (a Audience) Contains(audience string) bool {
+	for _, aud := range a {
+		if aud == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// Sign returns the compact form of a token carrying claims, signed with key
+// under alg: a []byte secret for HS256 or an *rsa.PrivateKey for RS256. kid,
+// when set, tells verifiers which of their keys to use.
+func Sign(claims *Claims, alg, kid string, key interface{}) (string, error) {
+	header, err := json.Marshal(Header{Algorithm: alg, Type: "JWT", KeyID: kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encode(header) + "." + encode(payload)
+
+	var signature []byte
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return "", fmt.Errorf("jwt: HS256 needs a []byte secret, got %T", key)
+		}
+		signature = hmacSHA256(secret, signingInput)
+	case RS256:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("jwt: RS256 needs an *rsa.PrivateKey, got %T", key)
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err = rsa.SignPKCS1v15(nil, privateKey, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("jwt: unsupported algorithm %q", alg)
+	}
+	return signingInput + "." + encode(signature), nil
+}
+
+// Verifier checks the signature and claims of tokens. Every token must be
+// signed by a key from Keys, carry a subject and an expiry, and name Issuer
+// and Audience when those are set. Leeway allows for clock skew between the
+// issuer and this server.
+type Verifier struct {
+	Keys     KeySource
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+
+	now func() time.Time
+}
+
+// NewVerifier returns a verifier of tokens signed by keys
+func NewVerifier(keys KeySource, issuer, audience string, leeway time.Duration) *Verifier {
+	return &Verifier{Keys: keys, Issuer: issuer, Audience: audience, Leeway: leeway, now: time.Now}
+}
+
+// Verify checks token and returns its claims. Errors about the token wrap
+// ErrInvalidToken; other errors mean its keys could not be loaded.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("token must have three parts")
+	}
+
+	var header Header
+	if err := decodeJSON(parts[0], &header); err != nil {
+		return nil, invalid("malformed header")
+	}
+	if header.Algorithm != HS256 && header.Algorithm != RS256 {
+		return nil, invalid("unsupported algorithm %q", header.Algorithm)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+	key, err := v.Keys.Key(header.Algorithm, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, invalid("malformed claims")
+	}
+	if err := v.checkClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) checkClaims(claims *Claims) error {
+	now := v.now()
+	if claims.Subject == "" {
+		return invalid("token has no subject")
+	}
+	if claims.ExpiresAt == 0 {
+		return invalid("token has no expiry")
+	}
+	if now.Add(-v.Leeway).After(time.Unix(claims.ExpiresAt, 0)) {
+		return invalid("token has expired")
+	}
+	if claims.NotBefore != 0 && now.Add(v.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return invalid("token is not valid yet")
+	}
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return invalid("token was issued by %q", claims.Issuer)
+	}
+	if v.Audience != "" && !claims.Audience.Contains(v.Audience) {
+		return invalid("token is not meant for this audience")
+	}
+	return nil
+}
+
+// verifySignature checks signature against the signing input. The key must
+// be of the kind alg uses, so an RSA public key can never be used as an HMAC
+// secret.
+func verifySignature(alg string, key interface{}, signingInput string, signature []byte) error {
+	switch alg {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok || !hmac.Equal(signature, hmacSHA256(secret, signingInput)) {
+			return invalid("signature does not match")
+		}
+	case RS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return invalid("signature does not match")
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return invalid("signature does not match")
+		}
+	}
+	return nil
+}
+
+func hmacSHA256(secret []byte, signingInput string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return mac.Sum(nil)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidToken, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,220 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	return key
+}
+
+func testClaims() *Claims {
+	return &Claims{
+		Issuer:    "travel",
+		Subject:   "user-1",
+		Audience:  Audience{"api"},
+		ExpiresAt: testNow.Add(time.Hour).Unix(),
+		IssuedAt:  testNow.Unix(),
+	}
+}
+
+func sign(t *testing.T, claims *Claims, alg, kid string, key interface{}) string {
+	t.Helper()
+	token, err := Sign(claims, alg, kid, key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return token
+}
+
+// unsigned builds a token with the given header and claims and an empty signature
+func unsigned(header, claims string) string {
+	return encode([]byte(header)) + "." + encode([]byte(claims)) + "."
+}
+
+type keySourceFunc func(alg, kid string) (interface{}, error)
+
+func (f keySourceFunc) Key(alg, kid string) (interface{}, error) { return f(alg, kid) }
+
+func TestVerify(t *testing.T) {
+	secret := []byte("test-secret")
+	privateKey := testRSAKey(t)
+	otherKey := testRSAKey(t)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&privateKey.PublicKey)})
+	staticKeys := StaticKeys{Secret: secret, PublicKey: &privateKey.PublicKey}
+
+	with := func(change func(c *Claims)) *Claims {
+		claims := testClaims()
+		change(claims)
+		return claims
+	}
+	tamper := func(token string) string {
+		claims := testClaims()
+		claims.Subject = "admin"
+		forged := sign(t, claims, HS256, "", []byte("other"))
+		parts, forgedParts := splitToken(token), splitToken(forged)
+		return parts[0] + "." + forgedParts[1] + "." + parts[2]
+	}
+
+	tests := []struct {
+		name  string
+		keys  KeySource
+		token string
+		valid bool
+	}{
+		{name: "HS256", keys: staticKeys, token: sign(t, testClaims(), HS256, "", secret), valid: true},
+		{name: "RS256", keys: staticKeys, token: sign(t, testClaims(), RS256, "", privateKey), valid: true},
+		{name: "HS256 with the wrong secret", keys: staticKeys, token: sign(t, testClaims(), HS256, "", []byte("wrong"))},
+		{name: "RS256 with the wrong key", keys: staticKeys, token: sign(t, testClaims(), RS256, "", otherKey)},
+		{name: "tampered claims", keys: staticKeys, token: tamper(sign(t, testClaims(), HS256, "", secret))},
+		{name: "stripped signature", keys: staticKeys, token: strings.TrimRightFunc(sign(t, testClaims(), HS256, "", secret), func(r rune) bool { return r != '.' })},
+
+		// Algorithm confusion
+		{name: "alg none", keys: staticKeys, token: unsigned(`{"alg":"none"}`, `{"sub":"user-1","exp":9999999999}`)},
+		{name: "alg None", keys: staticKeys, token: unsigned(`{"alg":"None"}`, `{"sub":"user-1","exp":9999999999}`)},
+		{name: "unsupported alg", keys: staticKeys, token: unsigned(`{"alg":"HS512"}`, `{"sub":"user-1","exp":9999999999}`)},
+		{name: "HS256 signed with the RSA public key", keys: StaticKeys{PublicKey: &privateKey.PublicKey}, token: sign(t, testClaims(), HS256, "", publicPEM)},
+		{
+			name:  "HS256 when the source hands out the RSA public key",
+			keys:  keySourceFunc(func(alg, kid string) (interface{}, error) { return &privateKey.PublicKey, nil }),
+			token: sign(t, testClaims(), HS256, "", x509.MarshalPKCS1PublicKey(&privateKey.PublicKey)),
+		},
+		{
+			name:  "RS256 when the source hands out an HMAC secret",
+			keys:  keySourceFunc(func(alg, kid string) (interface{}, error) { return secret, nil }),
+			token: sign(t, testClaims(), RS256, "", privateKey),
+		},
+
+		// Malformed tokens
+		{name: "two parts", keys: staticKeys, token: "abc.def"},
+		{name: "header not base64", keys: staticKeys, token: "***." + encode([]byte(`{}`)) + ".sig"},
+		{name: "header not JSON", keys: staticKeys, token: unsigned(`not json`, `{}`)},
+		{name: "signature not base64", keys: staticKeys, token: splitToken(sign(t, testClaims(), HS256, "", secret))[0] + "." + encode([]byte(`{}`)) + ".***"},
+
+		// Expiry, not-before and leeway (30s)
+		{name: "no expiry", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.ExpiresAt = 0 }), HS256, "", secret)},
+		{name: "expired", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.ExpiresAt = testNow.Add(-time.Minute).Unix() }), HS256, "", secret)},
+		{name: "expired within leeway", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.ExpiresAt = testNow.Add(-20 * time.Second).Unix() }), HS256, "", secret), valid: true},
+		{name: "not yet valid", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.NotBefore = testNow.Add(time.Minute).Unix() }), HS256, "", secret)},
+		{name: "not yet valid within leeway", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.NotBefore = testNow.Add(20 * time.Second).Unix() }), HS256, "", secret), valid: true},
+		{name: "valid since before now", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.NotBefore = testNow.Add(-time.Minute).Unix() }), HS256, "", secret), valid: true},
+
+		// Subject, issuer and audience
+		{name: "no subject", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.Subject = "" }), HS256, "", secret)},
+		{name: "wrong issuer", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.Issuer = "someone-else" }), HS256, "", secret)},
+		{name: "no issuer", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.Issuer = "" }), HS256, "", secret)},
+		{name: "wrong audience", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.Audience = Audience{"web"} }), HS256, "", secret)},
+		{name: "no audience", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.Audience = nil }), HS256, "", secret)},
+		{name: "audience among several", keys: staticKeys, token: sign(t, with(func(c *Claims) { c.Audience = Audience{"web", "api"} }), HS256, "", secret), valid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier := NewVerifier(test.keys, "travel", "api", 30*time.Second)
+			verifier.now = func() time.Time { return testNow }
+
+			claims, err := verifier.Verify(test.token)
+			if test.valid {
+				if err != nil {
+					t.Fatalf("Verify() error = %v, want none", err)
+				}
+				if claims.Subject != "user-1" {
+					t.Errorf("Verify() subject = %q, want user-1", claims.Subject)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("Verify() error = %v, want one wrapping ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutIssuerOrAudience(t *testing.T) {
+	secret := []byte("test-secret")
+	verifier := NewVerifier(StaticKeys{Secret: secret}, "", "", 0)
+	verifier.now = func() time.Time { return testNow }
+
+	claims := testClaims()
+	claims.Issuer, claims.Audience = "", nil
+	if _, err := verifier.Verify(sign(t, claims, HS256, "", secret)); err != nil {
+		t.Fatalf("Verify() error = %v, want none", err)
+	}
+}
+
+func TestSignRejectsMismatchedKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  string
+		key  interface{}
+	}{
+		{name: "HS256 with an RSA key", alg: HS256, key: testRSAKey(t)},
+		{name: "RS256 with a secret", alg: RS256, key: []byte("secret")},
+		{name: "none", alg: "none", key: []byte("secret")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Sign(testClaims(), test.alg, "", test.key); err == nil {
+				t.Fatal("Sign() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestAudienceJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		want Audience
+	}{
+		{json: `"api"`, want: Audience{"api"}},
+		{json: `["api","web"]`, want: Audience{"api", "web"}},
+	}
+	for _, test := range tests {
+		var got Audience
+		if err := got.UnmarshalJSON([]byte(test.json)); err != nil {
+			t.Fatalf("UnmarshalJSON(%s) error = %v", test.json, err)
+		}
+		if len(got) != len(test.want) || got[0] != test.want[0] {
+			t.Errorf("UnmarshalJSON(%s) = %v, want %v", test.json, got, test.want)
+		}
+		encoded, err := got.MarshalJSON()
+		if err != nil || string(encoded) != test.json {
+			t.Errorf("MarshalJSON(%v) = %s, %v, want %s", got, encoded, err, test.json)
+		}
+	}
+}
+
+func TestKeySources(t *testing.T) {
+	secret := []byte("test-secret")
+	unreachable := keySourceFunc(func(alg, kid string) (interface{}, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	if key, err := (KeySources{unreachable, StaticKeys{Secret: secret}}).Key(HS256, ""); err != nil || string(key.([]byte)) != string(secret) {
+		t.Errorf("Key() = %v, %v, want the secret of the second source", key, err)
+	}
+	if _, err := (KeySources{StaticKeys{}, StaticKeys{}}).Key(HS256, ""); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Key() error = %v, want one wrapping ErrInvalidToken when no source has the key", err)
+	}
+	if _, err := (KeySources{StaticKeys{}, unreachable}).Key(HS256, ""); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Key() error = %v, want the failure to load keys", err)
+	}
+}
+
+func splitToken(token string) []string {
+	return strings.SplitN(token, ".", 3)
+}
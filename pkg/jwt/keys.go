@@ -0,0 +1,94 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// KeySource finds the key that verifies tokens signed with alg by the key
+// named kid, which may be empty. It returns an error wrapping ErrInvalidToken
+// when it has no such key.
+type KeySource interface {
+	Key(alg, kid string) (interface{}, error)
+}
+
+// StaticKeys verifies tokens with a fixed HS256 secret and RS256 public key,
+// either of which may be left unset. Key IDs are ignored.
+type StaticKeys struct {
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+}
+
+func (k StaticKeys) Key(alg, kid string) (interface{}, error) {
+	switch {
+	case alg == HS256 && len(k.Secret) > 0:
+		return k.Secret, nil
+	case alg == RS256 && k.PublicKey != nil:
+		return k.PublicKey, nil
+	}
+	return nil, invalid("no %s key is configured", alg)
+}
+
+// KeySources tries each of its sources in turn
+type KeySources []KeySource
+
+func (s KeySources) Key(alg, kid string) (interface{}, error) {
+	err := invalid("no %s key is configured", alg)
+	for _, source := range s {
+		key, sourceErr := source.Key(alg, kid)
+		if sourceErr == nil {
+			return key, nil
+		}
+		// Keep looking past sources that lack the key, but report the
+		// first failure to load keys over a missing key
+		if errors.Is(err, ErrInvalidToken) {
+			err = sourceErr
+		}
+	}
+	return nil, err
+}
+
+// ParseRSAPublicKey parses a PEM encoded RSA public key, in either PKIX
+// ("PUBLIC KEY") or PKCS #1 ("RSA PUBLIC KEY") form
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: no PEM block found")
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("jwt: public key is %T, not RSA", key)
+	}
+	return publicKey, nil
+}
+
+// ParseRSAPrivateKey parses a PEM encoded RSA private key, in either PKCS #8
+// ("PRIVATE KEY") or PKCS #1 ("RSA PRIVATE KEY") form
+func ParseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: no PEM block found")
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("jwt: private key is %T, not RSA", key)
+	}
+	return privateKey, nil
+}
@@ -0,0 +1,83 @@
+// Package mergepatch applies JSON Merge Patch documents as defined by RFC 7396.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+)
+
+// ContentType is the media type of merge patch request bodies
+const ContentType = "application/merge-patch+json"
+
+// ErrNotObject is returned when a patch that must change individual members
+// of a resource is not a JSON object
+var ErrNotObject = errors.New("merge patch must be a JSON object")
+
+// Apply merges patch into document and returns the result. Members of the
+// patch replace those of the document, objects are merged recursively and
+// null removes a member. A patch that is not an object replaces the document.
+func Apply(document, patch []byte) ([]byte, error) {
+	target, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+// Fields returns the sorted top-level members that an object patch sets or removes
+func Fields(patch []byte) ([]string, error) {
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+	members, ok := changes.(map[string]interface{})
+	if !ok {
+		return nil, ErrNotObject
+	}
+
+	fields := make([]string, 0, len(members))
+	for name := range members {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func merge(target, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = make(map[string]interface{}, len(members))
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = merge(result[name], value)
+	}
+	return result
+}
+
+// decode parses a JSON document, keeping numbers exactly as written
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after JSON document")
+	}
+	return value, nil
+}
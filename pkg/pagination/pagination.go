@@ -0,0 +1,93 @@
+// Package pagination encodes the opaque cursors handed out by list endpoints
+// and cuts sorted lists into pages.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// ErrInvalidCursor is returned for cursors that were not issued by a previous
+// page of the same list and sort order
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// Cursor marks where the next page of a list starts. A storage engine that
+// pages natively keeps its own position in Key; sorted lists resume after the
+// item with SortKey and ID.
+type Cursor struct {
+	Sort    string            `json:"o,omitempty"`
+	Key     map[string]string `json:"k,omitempty"`
+	SortKey string            `json:"s,omitempty"`
+	ID      string            `json:"i,omitempty"`
+}
+
+// Encode returns the opaque token of a cursor
+func Encode(cursor Cursor) string {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		panic("pagination: cannot encode cursor: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses a token issued for a list sorted by sort. An empty token
+// decodes to the zero cursor, the start of the list.
+func Decode(token, sort string) (Cursor, error) {
+	var cursor Cursor
+	if token == "" {
+		return cursor, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &cursor); err != nil || cursor.Sort != sort {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Window sorts items, which must be a slice, by the sort key and then the ID
+// that key returns for each element, and returns the bounds of the page of at
+// most limit items that follows token, along with the token of the next page,
+// which is empty on the last page
+func Window(items interface{}, key func(i int) (string, string), token, sortBy string, limit int) (int, int, string, error) {
+	cursor, err := Decode(token, sortBy)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if len(cursor.Key) > 0 {
+		return 0, 0, "", ErrInvalidCursor
+	}
+
+	descending := len(sortBy) > 0 && sortBy[0] == '-'
+	less := func(keyA, idA, keyB, idB string) bool {
+		if keyA != keyB {
+			return keyA < keyB != descending
+		}
+		return idA < idB != descending
+	}
+	sort.Slice(items, func(i, j int) bool {
+		keyI, idI := key(i)
+		keyJ, idJ := key(j)
+		return less(keyI, idI, keyJ, idJ)
+	})
+
+	n := reflect.ValueOf(items).Len()
+	start := 0
+	if cursor.ID != "" {
+		start = sort.Search(n, func(i int) bool {
+			sortKey, id := key(i)
+			return less(cursor.SortKey, cursor.ID, sortKey, id)
+		})
+	}
+	end := start + limit
+	if end >= n {
+		return start, n, "", nil
+	}
+	sortKey, id := key(end - 1)
+	return start, end, Encode(Cursor{Sort: sortBy, SortKey: sortKey, ID: id}), nil
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// NewID returns a random 128-bit identifier encoded as 32 hex characters
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand unavailable: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// locatorAlphabet holds the characters used in record locators. 0, O, 1 and I
+// are left out because they are easily confused when read out or typed in.
+const locatorAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// LocatorLength is the number of characters in a record locator
+const LocatorLength = 6
+
+// NewLocator returns a random airline-style record locator such as "K7QX3M".
+// Locators are short, so callers must still check them for uniqueness.
+func NewLocator() string {
+	b := make([]byte, LocatorLength)
+	if _, err := rand.Read(b); err != nil {
+		panic("crypto/rand unavailable: " + err.Error())
+	}
+	// The alphabet has 32 characters, so masking keeps the choice uniform
+	for i := range b {
+		b[i] = locatorAlphabet[b[i]&31]
+	}
+	return string(b)
+}
+
+// IsLocator reports whether s is a well-formed record locator
+func IsLocator(s string) bool {
+	if len(s) != LocatorLength {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(locatorAlphabet, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"travel-backend/internal/core/domain/models"
+	"travel-backend/pkg/validate"
+)
+
+// DecodeJSON decodes the JSON document read from body into v. Members that v
+// has no field for are rejected, all of them in one validation error, as are
+// values of the wrong type and empty or malformed documents.
+func DecodeJSON(body io.Reader, v interface{}) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return models.Invalid("request body is required")
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		var (
+			syntaxErr *json.SyntaxError
+			typeErr   *json.UnmarshalTypeError
+		)
+		switch {
+		case errors.As(err, &syntaxErr):
+			return models.Invalid("request body is not valid JSON: %v", syntaxErr)
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return models.InvalidFields([]models.FieldViolation{{
+				Field:   typeErr.Field,
+				Message: "must be a JSON " + jsonKind(typeErr.Type.Kind()) + ", not " + typeErr.Value,
+			}})
+		}
+		return models.Invalid("request body is invalid: %v", err)
+	}
+
+	if unknown := validate.UnknownFields(data, v); len(unknown) > 0 {
+		fields := make([]models.FieldViolation, len(unknown))
+		for i, violation := range unknown {
+			fields[i] = models.FieldViolation{Field: violation.Field, Message: violation.Message}
+		}
+		return models.InvalidFields(fields)
+	}
+	return nil
+}
+
+// jsonKind names the JSON type that decodes into a Go kind
+func jsonKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return "number"
+}
@@ -2,9 +2,51 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"travel-backend/internal/core/domain/models"
 )
 
+const (
+	// ProblemContentType is the media type of error responses, from RFC 7807
+	ProblemContentType = "application/problem+json"
+	// RequestIDHeader carries the ID of a request; it is set on every response
+	RequestIDHeader = "X-Request-ID"
+)
+
+// Stable codes of errors raised outside the domain
+const (
+	CodeMalformedRequest = "malformed_request"
+	CodeInternalError    = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details body. Code is a stable identifier of
+// the error, Errors lists the request fields at fault and Details carries
+// extra data about the error, such as a sold-out stay's alternatives.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail,omitempty"`
+	Code      string                  `json:"code"`
+	Errors    []models.FieldViolation `json:"errors,omitempty"`
+	Details   interface{}             `json:"details,omitempty"`
+	RequestID string                  `json:"requestID,omitempty"`
+}
+
+// kindStatus maps each kind of domain error to its HTTP status
+var kindStatus = map[models.ErrorKind]int{
+	models.KindNotFound:        http.StatusNotFound,
+	models.KindValidation:      http.StatusBadRequest,
+	models.KindConflict:        http.StatusConflict,
+	models.KindUnauthenticated: http.StatusUnauthorized,
+	models.KindForbidden:       http.StatusForbidden,
+	models.KindUnavailable:     http.StatusServiceUnavailable,
+}
+
 func RespondWithJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -13,6 +55,77 @@ func RespondWithJSON(w http.ResponseWriter, statusCode int, payload interface{})
 	}
 }
 
+// HandleError answers a request that failed with err as problem+json, with
+// the status that matches the kind of error. Errors that are not domain
+// errors are reported as 500 Internal Server Error without their message.
 func HandleError(w http.ResponseWriter, err error) {
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	problem := ProblemFor(err)
+	if problem.Status >= http.StatusInternalServerError {
+		log.Printf("Request %s failed: %v", w.Header().Get(RequestIDHeader), err)
+	}
+	RespondWithProblem(w, problem)
+}
+
+// ProblemFor describes err as a problem, without writing it
+func ProblemFor(err error) *Problem {
+	if domainErr, ok := models.AsError(err); ok {
+		status, ok := kindStatus[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		// Keep context added while the error was returned, such as which
+		// segment of a booking failed, except for the causes of outages
+		detail := err.Error()
+		if domainErr.Kind == models.KindUnavailable {
+			detail = domainErr.Error()
+		}
+		return &Problem{
+			Status:  status,
+			Detail:  detail,
+			Code:    domainErr.Code,
+			Errors:  domainErr.Fields,
+			Details: domainErr.Details,
+		}
+	}
+	if isMalformed(err) {
+		return NewProblem(http.StatusBadRequest, CodeMalformedRequest, err.Error())
+	}
+	return NewProblem(http.StatusInternalServerError, CodeInternalError, "the request could not be completed")
+}
+
+// NewProblem returns a problem with the given status, code and message
+func NewProblem(status int, code, detail string) *Problem {
+	return &Problem{Status: status, Code: code, Detail: detail}
+}
+
+// RespondWithProblem writes a problem+json response, tagged with the ID of the request
+func RespondWithProblem(w http.ResponseWriter, problem *Problem) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	problem.RequestID = w.Header().Get(RequestIDHeader)
+	if problem.Status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.Printf("Error writing problem response: %v", err)
+	}
+}
+
+// isMalformed reports whether err comes from a request body or parameter that
+// could not be parsed
+func isMalformed(err error) bool {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		numErr    *strconv.NumError
+	)
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &numErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
 }
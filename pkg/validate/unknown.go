@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// UnknownFields returns a violation for every member of the JSON document data
+// that has no matching field in v, at any depth, matching names the way
+// encoding/json does. Documents that are not valid JSON yield no violations;
+// decoding them reports the problem.
+func UnknownFields(data []byte, v interface{}) []Violation {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil
+	}
+	var violations []Violation
+	findUnknown(document, reflect.TypeOf(v), "", &violations)
+	return violations
+}
+
+func findUnknown(document interface{}, target reflect.Type, path string, violations *[]Violation) {
+	for target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	if reflect.PtrTo(target).Implements(unmarshalerType) {
+		return
+	}
+
+	switch document := document.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(document))
+		for key := range document {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			memberPath := key
+			if path != "" {
+				memberPath = path + "." + key
+			}
+			switch target.Kind() {
+			case reflect.Struct:
+				field, ok := jsonField(target, key)
+				if !ok {
+					*violations = append(*violations, Violation{Field: memberPath, Message: "is not a known field"})
+					continue
+				}
+				findUnknown(document[key], field.Type, memberPath, violations)
+			case reflect.Map:
+				findUnknown(document[key], target.Elem(), memberPath, violations)
+			}
+		}
+	case []interface{}:
+		if target.Kind() != reflect.Slice && target.Kind() != reflect.Array {
+			return
+		}
+		for i, element := range document {
+			findUnknown(element, target.Elem(), path+"["+strconv.Itoa(i)+"]", violations)
+		}
+	}
+}
+
+// jsonField finds the field of structType that encoding/json decodes the
+// member key into, preferring an exact match of names over a case-insensitive one
+func jsonField(structType reflect.Type, key string) (reflect.StructField, bool) {
+	var folded *reflect.StructField
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found, ok := jsonField(embedded, key); ok {
+					return found, true
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if name == key {
+			return field, true
+		}
+		if folded == nil && strings.EqualFold(name, key) {
+			folded = &field
+		}
+	}
+	if folded != nil {
+		return *folded, true
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,288 @@
+// Package validate checks structs against the rules declared in their
+// `validate` struct tags and reports every violation at once.
+//
+// Rules are separated by commas, e.g. `validate:"required,iata_airport"`:
+//
+//	required      the field must not be empty or zero
+//	min=N, max=N  bounds on numbers, or on the length of strings and slices
+//	oneof=A B C   the value must be one of the listed words
+//	enum          the value's IsValid() bool method must return true
+//	date          a calendar date formatted as YYYY-MM-DD
+//	after=Field   a time or date later than the sibling field Field
+//	ne=Field      a value different from the sibling field Field
+//	iata_airport  a three-letter IATA airport code, e.g. "DEL"
+//	iata_airline  a two-character IATA airline designator, e.g. "AI" or "6E"
+//	country       a two-letter ISO 3166-1 country code
+//	currency      a three-letter ISO 4217 currency code
+//	passport      a passport number of 6 to 9 letters and digits
+//	email         an email address, e.g. "asha@example.com"
+//	phone         a phone number in E.164 form, e.g. "+919812345678"
+//
+// Every rule but required passes on empty strings, slices and pointers, so
+// optional fields only need checking when set; numbers are always checked.
+// Nested structs, pointers to structs and slices of structs are validated
+// too, and fields are reported by their JSON path, such as
+// "segments[0].hotel.checkOutDate". UnknownFields reports the members of a
+// JSON document that a struct has no field for, using the same paths.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the format of calendar dates checked by the date rule
+const DateLayout = "2006-01-02"
+
+// Violation is one rule a field breaks
+type Violation struct {
+	Field   string
+	Message string
+}
+
+var (
+	iataAirport = regexp.MustCompile(`^[A-Z]{3}$`)
+	iataAirline = regexp.MustCompile(`^([A-Z]{2}|[A-Z][0-9]|[0-9][A-Z])$`)
+	country     = regexp.MustCompile(`^[A-Z]{2}$`)
+	currency    = regexp.MustCompile(`^[A-Z]{3}$`)
+	passport    = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+	email       = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	phone       = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Struct validates v, which must be a struct or a pointer to one, and returns
+// the violations found, or nil when v is valid
+func Struct(v interface{}) []Violation {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	var violations []Violation
+	checkStruct(value, "", &violations)
+	return violations
+}
+
+func checkStruct(value reflect.Value, path string, violations *[]Violation) {
+	structType := value.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			// Embedded structs are flattened into their parent, as in JSON
+			checkNested(fieldValue, path, violations)
+			continue
+		}
+		if rules := field.Tag.Get("validate"); rules != "" {
+			for _, rule := range strings.Split(rules, ",") {
+				ruleName, param, _ := strings.Cut(rule, "=")
+				if message := check(ruleName, param, fieldValue, value); message != "" {
+					*violations = append(*violations, Violation{Field: fieldPath, Message: message})
+					// Later rules on the same field would only repeat the problem
+					break
+				}
+			}
+		}
+		checkNested(fieldValue, fieldPath, violations)
+	}
+}
+
+// checkNested validates the structs reachable from a field
+func checkNested(value reflect.Value, path string, violations *[]Violation) {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			checkNested(value.Elem(), path, violations)
+		}
+	case reflect.Struct:
+		if value.Type() != timeType {
+			checkStruct(value, path, violations)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			checkNested(value.Index(i), fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+// check applies one rule to a field of parent and returns why the field
+// breaks it, or "" when it holds
+func check(rule, param string, value, parent reflect.Value) string {
+	if rule == "required" {
+		if isEmpty(value) {
+			return "is required"
+		}
+		return ""
+	}
+	if isEmpty(value) && !isNumber(value) {
+		return ""
+	}
+
+	switch rule {
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			panic("validate: bad " + rule + " parameter " + param)
+		}
+		n, unit := size(value)
+		if rule == "min" && n < limit {
+			if unit != "" {
+				return "must have at least " + param + " " + unit
+			}
+			return "must be at least " + param
+		}
+		if rule == "max" && n > limit {
+			if unit != "" {
+				return "must have at most " + param + " " + unit
+			}
+			return "must be at most " + param
+		}
+	case "oneof":
+		allowed := strings.Fields(param)
+		for _, word := range allowed {
+			if fmt.Sprint(value.Interface()) == word {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(allowed, ", ")
+	case "enum":
+		enum, ok := value.Interface().(interface{ IsValid() bool })
+		if !ok {
+			panic("validate: enum rule on a type without IsValid")
+		}
+		if !enum.IsValid() {
+			return fmt.Sprintf("%v is not a known value", value.Interface())
+		}
+	case "date":
+		if _, err := time.Parse(DateLayout, value.String()); err != nil {
+			return "must be a date formatted as YYYY-MM-DD"
+		}
+	case "after":
+		other := parent.FieldByName(param)
+		if after, ok := isAfter(value, other); ok && !after {
+			return "must be after " + fieldJSONName(parent.Type(), param)
+		}
+	case "ne":
+		other := parent.FieldByName(param)
+		if strings.EqualFold(fmt.Sprint(value.Interface()), fmt.Sprint(other.Interface())) {
+			return "must differ from " + fieldJSONName(parent.Type(), param)
+		}
+	case "iata_airport":
+		return match(iataAirport, value, "must be a three-letter IATA airport code")
+	case "iata_airline":
+		return match(iataAirline, value, "must be a two-character IATA airline code")
+	case "country":
+		return match(country, value, "must be a two-letter ISO 3166-1 country code")
+	case "currency":
+		return match(currency, value, "must be a three-letter ISO 4217 currency code")
+	case "passport":
+		return match(passport, value, "must be a passport number of 6 to 9 letters and digits")
+	case "email":
+		return match(email, value, "must be an email address")
+	case "phone":
+		return match(phone, value, "must be a phone number in E.164 form, such as +919812345678")
+	default:
+		panic("validate: unknown rule " + rule)
+	}
+	return ""
+}
+
+func match(pattern *regexp.Regexp, value reflect.Value, message string) string {
+	if !pattern.MatchString(value.String()) {
+		return message
+	}
+	return ""
+}
+
+// size returns the number a min or max rule bounds, with the unit it is
+// counted in for lengths
+func size(value reflect.Value) (float64, string) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), ""
+	case reflect.String:
+		return float64(len([]rune(value.String()))), "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), "items"
+	}
+	panic("validate: min or max rule on unsupported kind " + value.Kind().String())
+}
+
+// isAfter compares two times, or two dates in DateLayout. ok is false when
+// either side is missing or unparsable, which other rules report.
+func isAfter(value, other reflect.Value) (after bool, ok bool) {
+	if !other.IsValid() || isEmpty(other) {
+		return false, false
+	}
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).After(other.Interface().(time.Time)), true
+	}
+	a, errA := time.Parse(DateLayout, value.String())
+	b, errB := time.Parse(DateLayout, other.String())
+	if errA != nil || errB != nil {
+		return false, false
+	}
+	return a.After(b), true
+}
+
+func isNumber(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func fieldJSONName(structType reflect.Type, name string) string {
+	field, ok := structType.FieldByName(name)
+	if !ok {
+		return name
+	}
+	return jsonName(field)
+}